@@ -0,0 +1,142 @@
+package alerts
+
+import (
+	"log"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/email"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const digestInterval = 24 * time.Hour
+
+// NewNotifier creates a Notifier, Run must be called for it to
+// start sending emails.
+func NewNotifier(ss models.SavedSearchService, js models.JobPostService, us models.UserService, emailer *email.Client) *Notifier {
+	return &Notifier{
+		ss:      ss,
+		js:      js,
+		us:      us,
+		emailer: emailer,
+	}
+}
+
+// Notifier emails candidates about new job posts matching
+// their saved searches, either right after a job post is
+// published or in a daily digest.
+type Notifier struct {
+	ss      models.SavedSearchService
+	js      models.JobPostService
+	us      models.UserService
+	emailer *email.Client
+}
+
+// HandleEvent matches the job post of a JobPostPublished event
+// against the instant saved searches. It matches it again once
+// a skill is added, skills being added after the job post.
+func (n *Notifier) HandleEvent(event models.DomainEvent) error {
+	switch event.Type {
+	case models.EventJobPostPublished, models.EventJobPostSkillAdded:
+		return n.NotifyInstant(event.AggregateID)
+	}
+	return nil
+}
+
+// Run sends the daily digests until stop is closed
+func (n *Notifier) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := n.SendDailyDigests(now); err != nil {
+				log.Printf("alerts: could not send daily digests: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// NotifyInstant emails the job post to the owners of every
+// instant saved search it matches, unless it was already sent
+// to them. Job posts that are not listed are not sent.
+func (n *Notifier) NotifyInstant(jobPostID uint) error {
+	jobPost, err := n.js.ByIDWith(jobPostID, "skillRequirements")
+	if err == models.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !jobPost.IsListed(time.Now()) {
+		return nil
+	}
+	searches, err := n.ss.ByFrequency(models.AlertInstant)
+	if err != nil {
+		return err
+	}
+	for _, search := range searches {
+		if !models.MatchesJobPostFilter(search.Filter(), jobPost) {
+			continue
+		}
+		if err := n.send(search, []models.JobPost{*jobPost}, time.Now()); err != nil {
+			log.Printf("alerts: could not notify saved search %d: %v", search.ID, err)
+		}
+	}
+	return nil
+}
+
+// SendDailyDigests emails the owner of every daily saved search
+// the job posts published since their last digest.
+func (n *Notifier) SendDailyDigests(now time.Time) error {
+	searches, err := n.ss.ByFrequency(models.AlertDaily)
+	if err != nil {
+		return err
+	}
+	for _, search := range searches {
+		since := search.CreatedAt
+		if search.LastNotifiedAt != nil {
+			since = *search.LastNotifiedAt
+		}
+		found, err := n.js.FindAll(search.Filter())
+		if err != nil {
+			return err
+		}
+
+		var jobPosts []models.JobPost
+		seen := map[uint]bool{}
+		for _, jp := range found {
+			if seen[jp.ID] || jp.PublishedAt == nil || !jp.PublishedAt.After(since) || jp.PublishedAt.After(now) {
+				continue
+			}
+			seen[jp.ID] = true
+			jobPosts = append(jobPosts, jp)
+		}
+		if len(jobPosts) == 0 {
+			continue
+		}
+		if err := n.send(search, jobPosts, now); err != nil {
+			log.Printf("alerts: could not send digest of saved search %d: %v", search.ID, err)
+		}
+	}
+	return nil
+}
+
+// send emails the job posts that were not sent yet to the owner
+// of the saved search.
+func (n *Notifier) send(search models.SavedSearch, jobPosts []models.JobPost, now time.Time) error {
+	jobPosts, err := n.ss.Unsent(&search, jobPosts)
+	if err != nil || len(jobPosts) == 0 {
+		return err
+	}
+	user, err := n.us.ByID(search.UserID)
+	if err != nil {
+		return err
+	}
+	err = n.emailer.JobAlert(user.Email, search, jobPosts, n.ss.UnsubscribeToken(&search))
+	if err != nil {
+		return err
+	}
+	return n.ss.MarkSent(&search, jobPosts, now)
+}
@@ -51,8 +51,13 @@ type Config struct {
 	Env      string         `json:"env"`
 	Pepper   string         `json:"pepper"`
 	HMACKey  string         `json:"hmacKey"`
+	BaseURL  string         `json:"baseUrl"`
 	Database DatabaseConfig `json:"-"`
 	Mailgun  MailgunConfig  `json:"mailgun"`
+	Storage  StorageConfig  `json:"storage"`
+	// TrashRetentionDays is how long deleted job posts and users
+	// can be restored before being purged, zero keeps them.
+	TrashRetentionDays int `json:"trashRetentionDays"`
 }
 
 func DefaultConfig() Config {
@@ -61,7 +66,13 @@ func DefaultConfig() Config {
 		Env:      "dev",
 		Pepper:   "mUGD8rTdJe",
 		HMACKey:  "the-secret-key",
+		BaseURL:  "http://localhost:5000",
 		Database: DefaultPostgressConfig(),
+		Storage: StorageConfig{
+			Backend: "local",
+			Dir:     "media",
+		},
+		TrashRetentionDays: 30,
 	}
 }
 
@@ -70,12 +81,27 @@ func ProdConfig() Config {
 		Env:     "prod",
 		Pepper:  getEnvVar("PASSWORD_PEPPER"),
 		HMACKey: getEnvVar("HMAC_KEY"),
+		BaseURL: getEnvVar("BASE_URL"),
+		Storage: StorageConfig{
+			Backend:         os.Getenv("STORAGE_BACKEND"),
+			Dir:             os.Getenv("STORAGE_DIR"),
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		},
 	}
 	Port, err := strconv.Atoi(getEnvVar("PORT"))
 	databaseUrl := getEnvVar("DATABASE_URL")
 	if err == nil {
 		c.Port = Port
 	}
+	c.TrashRetentionDays = 30
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil {
+		c.TrashRetentionDays = days
+	}
 	if databaseUrl != "" {
 		c.Database = HerokuPGDatabase{databaseUrl: databaseUrl}
 	}
@@ -113,6 +139,20 @@ type MailgunConfig struct {
 	Domain       string `json:"domain"`
 }
 
+// StorageConfig selects where uploaded files are stored, either
+// in Dir with the "local" backend or in an S3 compatible bucket
+// with the "s3" backend.
+type StorageConfig struct {
+	Backend         string `json:"backend"`
+	Dir             string `json:"dir"`
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	PublicURL       string `json:"public_url"`
+}
+
 type OAuthConfig struct {
 	ID       string `json:"id"`
 	Secret   string `json:"secret"`
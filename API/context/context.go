@@ -7,7 +7,8 @@ import (
 )
 
 const (
-	userKey privateKey = "user"
+	userKey   privateKey = "user"
+	apiKeyKey privateKey = "apiKey"
 )
 
 type privateKey string
@@ -24,3 +25,20 @@ func User(ctx context.Context) *models.User {
 	}
 	return nil
 }
+
+// WithAPIKey stores the API key a request was authenticated
+// with, along with its owner stored by WithUser.
+func WithAPIKey(ctx context.Context, apiKey *models.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey, apiKey)
+}
+
+// APIKey returns nil when the request was not made with an API
+// key.
+func APIKey(ctx context.Context) *models.APIKey {
+	if temp := ctx.Value(apiKeyKey); temp != nil {
+		if apiKey, ok := temp.(*models.APIKey); ok {
+			return apiKey
+		}
+	}
+	return nil
+}
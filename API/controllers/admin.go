@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// Admin serves the /admin endpoints, the routes must only let
+// admins through.
+type Admin struct {
+	js models.JobPostService
+	us models.UserService
+}
+
+func NewAdmin(js models.JobPostService, us models.UserService) *Admin {
+	return &Admin{
+		js: js,
+		us: us,
+	}
+}
+
+// DELETE /admin/jobs/id
+//
+// Only deleted job posts can be purged, the job post is gone
+// for good.
+func (a *Admin) PurgeJobPost(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	if err := a.js.Purge(id); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Purged Jobpost with ID %v", id))
+	return nil
+}
+
+// POST /admin/users/id/restore
+func (a *Admin) RestoreUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	if err := a.us.Restore(id); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Restored user with ID %v", id))
+	return nil
+}
+
+// DELETE /admin/users/id
+//
+// Only deleted users can be purged, the user is gone for good.
+func (a *Admin) PurgeUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	if err := a.us.Purge(id); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Purged user with ID %v", id))
+	return nil
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+type APIKeys struct {
+	aks models.APIKeyService
+	cs  models.CompanyService
+}
+
+func NewAPIKeys(aks models.APIKeyService, cs models.CompanyService) *APIKeys {
+	return &APIKeys{
+		aks: aks,
+		cs:  cs,
+	}
+}
+
+// keyCompanyID returns the company the API key of the request
+// was issued for, or 0 when the caller signed in with a token.
+func keyCompanyID(r *http.Request) uint {
+	if apiKey := llctx.APIKey(r.Context()); apiKey != nil {
+		return apiKey.CompanyID
+	}
+	return 0
+}
+
+// GET /user/id/api-keys
+func (ak *APIKeys) List(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	apiKeys, err := ak.aks.ByUserID(userID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, apiKeys)
+	return nil
+}
+
+// POST /user/id/api-keys
+//
+// Scopes is a comma separated list of jobs:read, jobs:write and
+// applications:read. The key is issued for a company the caller
+// manages the job posts of and can not be used for any other.
+// The key is only part of this response.
+func (ak *APIKeys) Create(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	form := struct {
+		CompanyID uint   `json:"companyId"`
+		Name      string `json:"name"`
+		Scopes    string `json:"scopes"`
+	}{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	if form.CompanyID != 0 {
+		_, err := ak.cs.Authorize(form.CompanyID, userID, models.MemberRole.CanManageJobPosts)
+		if err != nil {
+			return err
+		}
+	}
+	apiKey := models.APIKey{
+		UserID:    userID,
+		CompanyID: form.CompanyID,
+		Name:      form.Name,
+		Scopes:    form.Scopes,
+	}
+	if err := ak.aks.Create(&apiKey); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusCreated, apiKey)
+	return nil
+}
+
+// DELETE /user/id/api-keys/keyId
+func (ak *APIKeys) Revoke(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	id, err := idParam(r, "keyId")
+	if err != nil {
+		return err
+	}
+	apiKey, err := ak.aks.ByID(id)
+	if err != nil {
+		return err
+	}
+	if apiKey.UserID != userID {
+		return models.ErrNotFound
+	}
+	if err := ak.aks.Revoke(apiKey.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Revoked API key with ID %v", apiKey.ID))
+	return nil
+}
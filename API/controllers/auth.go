@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/samueldaviddelacruz/go-job-board/API/email"
 	"github.com/samueldaviddelacruz/go-job-board/API/models"
@@ -26,18 +27,21 @@ type Auth struct {
 	emailer *email.Client
 }
 
+// errEmailUnknown is returned when logging in with an email
+// address no user has.
+var errEmailUnknown = errors.New("Invalid email address")
+
 // Create is used to process the signup form when a user
 // submits it. This is used to create a new user account.
 //
-func (u *Auth) Create(w http.ResponseWriter, r *http.Request) {
+func (u *Auth) Create(w http.ResponseWriter, r *http.Request) error {
 
 	credentials := Credentials{
 	}
 
 	err := parseJSON(r, &credentials)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	companyUser := models.User{
 		RoleID:   1,
@@ -46,52 +50,44 @@ func (u *Auth) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := u.us.Create(&companyUser); err != nil {
-		//vd.SetAlert(err)
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
 	}
 
 	respondJSON(w, http.StatusCreated, "resource created successfully")
+	return nil
 }
 
 // Login is used to verify the provided email address and
 // password and then log the user in if they are correct.
 //
 // POST /login
-func (u *Auth) Login(w http.ResponseWriter, r *http.Request) {
+func (u *Auth) Login(w http.ResponseWriter, r *http.Request) error {
 	credentials := Credentials{
 	}
 
 	err := parseJSON(r, &credentials)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	companyUser := models.User{
 		Password: credentials.Password,
 		Email:    credentials.Email,
 	}
 	_, err = u.us.Authenticate(companyUser.Email, companyUser.Password)
+	if err == models.ErrNotFound {
+		return withStatus(http.StatusUnauthorized, errEmailUnknown)
+	}
 	if err != nil {
-		switch err {
-		case models.ErrNotFound:
-			respondJSON(w, http.StatusUnauthorized, "Invalid email address")
-		default:
-			//vd.SetAlert(err)
-			respondJSON(w, http.StatusInternalServerError, err)
-		}
-		//u.LoginView.Render(w, r, vd)
-		return
+		return err
 	}
 
 	token, err := u.signIn(w, &companyUser)
 	if err != nil {
-		//u.LoginView.Render(w, r, vd)
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	//user2, _ := u.us.ByID(1)
 	respondJSON(w, http.StatusOK, string(token))
+	return nil
 }
 
 // ResetPwForm is used to process the forgot password form
@@ -103,27 +99,21 @@ type ResetPwForm struct {
 }
 
 // POST /forgot
-func (u *Auth) InitiateReset(w http.ResponseWriter, r *http.Request) {
+func (u *Auth) InitiateReset(w http.ResponseWriter, r *http.Request) error {
 	//var vd views.Data
 	var form ResetPwForm
 	//vd.Yield = &form
 	if err := parseForm(r, &form); err != nil {
-		//vd.SetAlert(err)
-		//u.ForgotPwView.Render(w, r, vd)
-		return
+		return badRequest(err)
 	}
 	token, err := u.us.InitiateReset(form.Email)
 	if err != nil {
-		//vd.SetAlert(err)
-		//u.ForgotPwView.Render(w, r, vd)
-		return
+		return err
 	}
 
 	err = u.emailer.ResetPw(form.Email, token)
 	if err != nil {
-		//vd.SetAlert(err)
-		//u.ForgotPwView.Render(w, r, vd)
-		return
+		return err
 	}
 	/*
 		views.RedirectAlert(w, r, "/reset", http.StatusFound, views.Alert{
@@ -131,12 +121,13 @@ func (u *Auth) InitiateReset(w http.ResponseWriter, r *http.Request) {
 			Message: "Instructions for resetting your password have been emailed to you.",
 		})
 	*/
+	return nil
 }
 
 // CompleteReset processes the reset password form
 //
 //POST
-func (u *Auth) CompleteReset(w http.ResponseWriter, r *http.Request) {
+func (u *Auth) CompleteReset(w http.ResponseWriter, r *http.Request) error {
 	/*
 		//var vd views.Data
 		var form ResetPwForm
@@ -165,6 +156,7 @@ func (u *Auth) CompleteReset(w http.ResponseWriter, r *http.Request) {
 				Message: "Your password has been reset and you have been logged in!",
 			})
 	*/
+	return nil
 }
 
 func (u *Auth) signIn(w http.ResponseWriter, user *models.User) ([]byte, error) {
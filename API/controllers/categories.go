@@ -16,11 +16,11 @@ func NewCategories(cs models.CategoryService) *Categories {
 }
 
 // GET /categories
-func (c *Categories) List(w http.ResponseWriter, r *http.Request) {
+func (c *Categories) List(w http.ResponseWriter, r *http.Request) error {
 	categories, err := c.cs.FindAll()
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	respondJSON(w, http.StatusOK, categories)
+	return nil
 }
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+)
+
+const (
+	defaultCompaniesPerPage = 20
+	maxCompaniesPerPage     = 100
+)
+
+// PublicCompany is the public view of a CompanyProfile, it
+// leaves out the account owning the profile.
+type PublicCompany struct {
+	Slug             string          `json:"slug"`
+	CompanyName      string          `json:"companyName"`
+	Website          string          `json:"website,omitempty"`
+	FoundedYear      uint            `json:"foundedYear,omitempty"`
+	Description      string          `json:"description,omitempty"`
+	CompanyLogoUrl   string          `json:"companyLogoUrl,omitempty"`
+	LogoThumbnailUrl string          `json:"logoThumbnailUrl,omitempty"`
+	LogoHeaderUrl    string          `json:"logoHeaderUrl,omitempty"`
+	Verified         bool            `json:"verified"`
+	VerifiedDomain   string          `json:"verifiedDomain,omitempty"`
+	CompanyBenefits  []string        `json:"companyBenefits"`
+	TechStack        []string        `json:"techStack"`
+	URL              string          `json:"url"`
+	JobPosts         []PublicJobPost `json:"jobPosts,omitempty"`
+}
+
+// PublicJobPost is an open job post listed on the public view
+// of its company.
+type PublicJobPost struct {
+	ID             uint                `json:"id"`
+	Slug           string              `json:"slug"`
+	Title          string              `json:"title"`
+	Location       string              `json:"location,omitempty"`
+	Category       string              `json:"category,omitempty"`
+	Skills         []string            `json:"skills"`
+	PublishedAt    *time.Time          `json:"publishedAt,omitempty"`
+	ValidThrough   *time.Time          `json:"validThrough,omitempty"`
+	SalaryMin      uint                `json:"salaryMin,omitempty"`
+	SalaryMax      uint                `json:"salaryMax,omitempty"`
+	SalaryCurrency string              `json:"salaryCurrency,omitempty"`
+	SalaryPeriod   models.SalaryPeriod `json:"salaryPeriod,omitempty"`
+	URL            string              `json:"url"`
+}
+
+// CompanyDirectory is a page of GET /companies
+type CompanyDirectory struct {
+	Companies []PublicCompany `json:"companies"`
+	Page      int             `json:"page"`
+	PerPage   int             `json:"perPage"`
+	Total     int             `json:"total"`
+}
+
+type Companies struct {
+	us   models.UserService
+	js   models.JobPostService
+	urls seo.URLs
+}
+
+func NewCompanies(us models.UserService, js models.JobPostService, urls seo.URLs) *Companies {
+	return &Companies{
+		us:   us,
+		js:   js,
+		urls: urls,
+	}
+}
+
+// GET /companies
+// Accepts a search query "q", a "page" starting at 1 and the
+// number of companies "perPage".
+func (c *Companies) List(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	page := 1
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	perPage := defaultCompaniesPerPage
+	if pp, err := strconv.Atoi(query.Get("perPage")); err == nil && pp > 0 && pp <= maxCompaniesPerPage {
+		perPage = pp
+	}
+
+	profiles, total, err := c.us.SearchCompanyProfiles(query.Get("q"), (page-1)*perPage, perPage)
+	if err != nil {
+		return err
+	}
+	directory := CompanyDirectory{
+		Companies: []PublicCompany{},
+		Page:      page,
+		PerPage:   perPage,
+		Total:     total,
+	}
+	for _, profile := range profiles {
+		directory.Companies = append(directory.Companies, c.publicCompany(profile))
+	}
+	respondJSON(w, http.StatusOK, directory)
+	return nil
+}
+
+// GET /companies/slug
+func (c *Companies) Show(w http.ResponseWriter, r *http.Request) error {
+	profile, err := c.us.CompanyProfileBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		return err
+	}
+	jobPosts, err := c.js.FindAll(models.JobPost{CompanyID: profile.CompanyID})
+	if err != nil {
+		return err
+	}
+
+	company := c.publicCompany(*profile)
+	company.JobPosts = []PublicJobPost{}
+	seen := map[uint]bool{}
+	now := time.Now()
+	for _, jp := range jobPosts {
+		// FindAll returns a job post once per skill
+		if seen[jp.ID] || !jp.IsListed(now) {
+			continue
+		}
+		seen[jp.ID] = true
+		company.JobPosts = append(company.JobPosts, c.publicJobPost(jp))
+	}
+	respondJSON(w, http.StatusOK, company)
+	return nil
+}
+
+func (c *Companies) publicCompany(profile models.CompanyProfile) PublicCompany {
+	company := PublicCompany{
+		Slug:             profile.Slug,
+		CompanyName:      profile.CompanyName,
+		Website:          profile.Website,
+		FoundedYear:      profile.FoundedYear,
+		Description:      profile.Description,
+		CompanyLogoUrl:   profile.CompanyLogoUrl,
+		LogoThumbnailUrl: profile.LogoThumbnailUrl,
+		LogoHeaderUrl:    profile.LogoHeaderUrl,
+		Verified:         profile.Verified,
+		VerifiedDomain:   profile.VerifiedDomain,
+		CompanyBenefits:  []string{},
+		TechStack:        []string{},
+		URL:              c.urls.Company(profile),
+	}
+	for _, benefit := range profile.CompanyBenefits {
+		company.CompanyBenefits = append(company.CompanyBenefits, benefit.BenefitName)
+	}
+	for _, skill := range profile.Skills {
+		company.TechStack = append(company.TechStack, skill.SkillName)
+	}
+	return company
+}
+
+func (c *Companies) publicJobPost(jp models.JobPost) PublicJobPost {
+	jobPost := PublicJobPost{
+		ID:             jp.ID,
+		Slug:           jp.Slug,
+		Title:          jp.Title,
+		Skills:         []string{},
+		PublishedAt:    jp.PublishedAt,
+		ValidThrough:   jp.ValidThrough,
+		SalaryMin:      jp.SalaryMin,
+		SalaryMax:      jp.SalaryMax,
+		SalaryCurrency: jp.SalaryCurrency,
+		SalaryPeriod:   jp.SalaryPeriod,
+		URL:            c.urls.JobPost(jp),
+	}
+	if jp.Location != nil {
+		jobPost.Location = jp.Location.LocationName
+	}
+	if jp.Category != nil {
+		jobPost.Category = jp.Category.CategoryName
+	}
+	for _, skill := range jp.Skills {
+		jobPost.Skills = append(jobPost.Skills, skill.SkillName)
+	}
+	return jobPost
+}
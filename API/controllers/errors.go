@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/rand"
+)
+
+// CorrelationIDHeader carries the correlation ID of a request,
+// set by the client or generated when an unexpected error is
+// logged.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+const (
+	maxCorrelationIDLength = 64
+	correlationIDChars     = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_="
+)
+
+// HandlerFunc is an HTTP handler that returns the errors it
+// does not respond to itself. Handle adapts it to the router.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handle adapts fn to an http.HandlerFunc, responding to the
+// error it returns with the matching problem. Handlers must not
+// write to w before returning an error.
+func Handle(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			respondError(w, r, err)
+		}
+	}
+}
+
+// statusError is an error the handler chose the status of, its
+// message is shown to the caller.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (se statusError) Error() string {
+	return se.err.Error()
+}
+
+func (se statusError) Public() string {
+	if public, ok := se.err.(publicError); ok {
+		return public.Public()
+	}
+	return se.err.Error()
+}
+
+// withStatus makes Handle respond to err with status
+func withStatus(status int, err error) error {
+	return statusError{status: status, err: err}
+}
+
+// badRequest is returned for request bodies or parameters that
+// could not be parsed.
+func badRequest(err error) error {
+	return withStatus(http.StatusBadRequest, err)
+}
+
+// respondError responds with the problem matching err:
+//
+//   - statusError: its status
+//   - ErrIncludeInvalid: 400
+//   - ValidationError: 422 with the fields, or 409 when the
+//     values are already taken
+//   - ErrNotFound: 404
+//   - ErrPasswordIncorrect: 401
+//   - ErrMemberRoleForbidden, ErrInvitationEmailMismatch: 403
+//   - ErrVersionConflict: 412
+//   - values already taken: 409
+//   - other public errors: 422
+//   - anything else: 500, logged with a correlation ID
+func respondError(w http.ResponseWriter, r *http.Request, err error) {
+	if verr, ok := err.(*models.ValidationError); ok {
+		status := http.StatusUnprocessableEntity
+		if verr.Conflict() {
+			status = http.StatusConflict
+		}
+		respondProblem(w, Problem{
+			Title:  "Your request is not valid",
+			Status: status,
+			Errors: verr.Fields(),
+		})
+		return
+	}
+	public, ok := err.(publicError)
+	status := http.StatusUnprocessableEntity
+	switch se, isStatus := err.(statusError); {
+	case isStatus:
+		status = se.status
+	case err == models.ErrIncludeInvalid:
+		status = http.StatusBadRequest
+	case err == models.ErrNotFound:
+		status = http.StatusNotFound
+	case err == models.ErrPasswordIncorrect:
+		status = http.StatusUnauthorized
+	case err == models.ErrMemberRoleForbidden, err == models.ErrInvitationEmailMismatch:
+		status = http.StatusForbidden
+	case err == models.ErrVersionConflict:
+		status = http.StatusPreconditionFailed
+	case err == models.ErrEmailTaken, err == models.ErrSkillNameTaken,
+		err == models.ErrMemberExists, err == models.ErrLastOwner:
+		status = http.StatusConflict
+	case !ok:
+		status = http.StatusInternalServerError
+	}
+	if status >= http.StatusInternalServerError {
+		id := correlationID(r)
+		log.Printf("controllers: %s %s failed [%s]: %v", r.Method, r.URL.Path, id, err)
+		w.Header().Set(CorrelationIDHeader, id)
+		respondProblem(w, Problem{Status: status, CorrelationID: id})
+		return
+	}
+	respondProblem(w, Problem{Status: status, Detail: public.Public()})
+}
+
+// correlationID returns the correlation ID sent by the client,
+// or a new one.
+func correlationID(r *http.Request) string {
+	id := r.Header.Get(CorrelationIDHeader)
+	if id != "" && len(id) <= maxCorrelationIDLength && strings.Trim(id, correlationIDChars) == "" {
+		return id
+	}
+	id, err := rand.String(12)
+	if err != nil {
+		return "unknown"
+	}
+	return id
+}
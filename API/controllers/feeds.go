@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/syndication"
+)
+
+type Feeds struct {
+	js      models.JobPostService
+	baseURL string
+}
+
+func NewFeeds(js models.JobPostService, baseURL string) *Feeds {
+	return &Feeds{
+		js:      js,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// GET /jobs.rss
+func (f *Feeds) RSS(w http.ResponseWriter, r *http.Request) error {
+	return f.serve(w, r, syndication.FormatRSS)
+}
+
+// GET /jobs.atom
+func (f *Feeds) Atom(w http.ResponseWriter, r *http.Request) error {
+	return f.serve(w, r, syndication.FormatAtom)
+}
+
+// GET /jobs.json
+func (f *Feeds) JSON(w http.ResponseWriter, r *http.Request) error {
+	return f.serve(w, r, syndication.FormatJSON)
+}
+
+// serve renders the job posts matching the same filters as
+// GET /jobs, answering 304 when the client copy is current.
+func (f *Feeds) serve(w http.ResponseWriter, r *http.Request, format string) error {
+	filters := models.ParseJobPostFilter(r.URL.Query())
+	jobPosts, err := f.js.FindAll(filters)
+	if err != nil {
+		return err
+	}
+	title := "Jobs"
+	if filters.Title != "" {
+		title = "Jobs matching \"" + filters.Title + "\""
+	}
+	feed := syndication.NewFeed(title, f.baseURL, f.baseURL+r.URL.RequestURI(), jobPosts)
+
+	etag := feed.ETag(format)
+	updated := feed.Updated()
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if !updated.IsZero() {
+		w.Header().Set("Last-Modified", updated.Format(http.TimeFormat))
+	}
+	if notModified(r, etag, updated) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	body, err := feed.Render(format)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", syndication.ContentTypes[format])
+	w.Write(body)
+	return nil
+}
+
+// notModified evaluates If-None-Match, falling back on
+// If-Modified-Since when it is missing.
+func notModified(r *http.Request, etag string, updated time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || updated.IsZero() {
+		return false
+	}
+	return !updated.Truncate(time.Second).After(since)
+}
@@ -1,13 +1,23 @@
 package controllers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/schema"
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
 )
 
+var errPatchInvalid = errors.New("a merge patch must be a JSON object")
+
 func parseForm(r *http.Request, dst interface{}) error {
 	if err := r.ParseForm(); err != nil {
 		return err
@@ -45,7 +55,107 @@ func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	}
 }
 
+// parseJSON decodes the body of the request, fields payload
+// does not have are rejected.
 func parseJSON(r *http.Request, payload interface{}) error {
-	err := json.NewDecoder(r.Body).Decode(&payload)
-	return err
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	return dec.Decode(payload)
+}
+
+// parseUpdate decodes the body of a PUT into dst, which holds
+// the current values. A PUT replaces all of them, a PATCH is a
+// JSON Merge Patch (RFC 7386) of them.
+func parseUpdate(r *http.Request, dst interface{}) error {
+	if r.Method != http.MethodPatch {
+		reset(dst)
+		return parseJSON(r, dst)
+	}
+	var patch interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		return err
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return errPatchInvalid
+	}
+	current, err := json.Marshal(dst)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return err
+	}
+	reset(dst)
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	return dec.Decode(dst)
+}
+
+// mergePatch applies patch to target as RFC 7386 describes,
+// null members are removed.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
+
+// reset sets the value dst points to to its zero value
+func reset(dst interface{}) {
+	v := reflect.ValueOf(dst).Elem()
+	v.Set(reflect.Zero(v.Type()))
+}
+
+// idParam returns the ID in the URL variable name, IDs that can
+// not be parsed are not found.
+func idParam(r *http.Request, name string) (uint, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 32)
+	if err != nil {
+		return 0, models.ErrNotFound
+	}
+	return uint(id), nil
+}
+
+// callerIDParam returns the ID in the URL variable id when it
+// is the one of the user making the request, the resources of
+// other users are not found. It must be behind the RequireUser
+// middleware.
+func callerIDParam(r *http.Request) (uint, error) {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return 0, err
+	}
+	if caller := llctx.User(r.Context()); caller == nil || caller.ID != id {
+		return 0, models.ErrNotFound
+	}
+	return id, nil
+}
+
+// includeParam returns the comma separated associations of the
+// include query parameter.
+func includeParam(r *http.Request) []string {
+	var include []string
+	for _, name := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			include = append(include, name)
+		}
+	}
+	return include
 }
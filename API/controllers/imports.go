@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/importer"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const maxImportBytes = 10 << 20
+
+type Imports struct {
+	ijs  models.ImportJobService
+	bulk *importer.Bulk
+}
+
+func NewImports(ijs models.ImportJobService, bulk *importer.Bulk) *Imports {
+	return &Imports{
+		ijs:  ijs,
+		bulk: bulk,
+	}
+}
+
+// POST /jobs/import?format=csv|jsonl&dry-run=true
+//
+// The format defaults to the one of the Content-Type. The rows
+// are imported in the background, the response is the import
+// job to poll for its status. Imports made with an API key
+// create the job posts for the company of the key.
+func (ic *Imports) Create(w http.ResponseWriter, r *http.Request) error {
+	format := importFormat(r)
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rows, rowErrors, err := importer.Parse(format, r.Body)
+	if err != nil {
+		return badRequest(err)
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	job := models.ImportJob{
+		UserID:    llctx.User(r.Context()).ID,
+		CompanyID: keyCompanyID(r),
+		Format:    format,
+		DryRun:    dryRun,
+	}
+	if err := ic.bulk.Start(&job, rows, rowErrors); err != nil {
+		return err
+	}
+	w.Header().Set("Location", fmt.Sprintf("/jobs/import/%d", job.ID))
+	respondJSON(w, http.StatusAccepted, job)
+	return nil
+}
+
+// GET /jobs/import/importId
+func (ic *Imports) Show(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "importId")
+	if err != nil {
+		return err
+	}
+	job, err := ic.ijs.ByID(id)
+	if err != nil {
+		return err
+	}
+	companyID := keyCompanyID(r)
+	if job.UserID != llctx.User(r.Context()).ID || companyID != 0 && job.CompanyID != companyID {
+		return models.ErrNotFound
+	}
+	respondJSON(w, http.StatusOK, job)
+	return nil
+}
+
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return importer.FormatCSV
+	case strings.Contains(contentType, "json"):
+		return importer.FormatJSONLines
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/importer"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+type JobFeeds struct {
+	fs     models.JobFeedService
+	syncer *importer.FeedSyncer
+}
+
+func NewJobFeeds(fs models.JobFeedService, syncer *importer.FeedSyncer) *JobFeeds {
+	return &JobFeeds{
+		fs:     fs,
+		syncer: syncer,
+	}
+}
+
+// GET /user/id/job-feeds
+func (jf *JobFeeds) List(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	feeds, err := jf.fs.ByUserID(userID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, feeds)
+	return nil
+}
+
+// POST /user/id/job-feeds
+//
+// CategoryID and LocationID are used for the entries whose
+// category or location is not part of the catalog.
+func (jf *JobFeeds) Create(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	form := struct {
+		URL        string `json:"url"`
+		CategoryID uint   `json:"categoryId"`
+		LocationID uint   `json:"locationId"`
+	}{}
+	err = parseJSON(r, &form)
+	if err != nil {
+		return badRequest(err)
+	}
+	feed := models.JobFeed{
+		UserID:     userID,
+		URL:        form.URL,
+		CategoryID: form.CategoryID,
+		LocationID: form.LocationID,
+	}
+	if err := jf.fs.Create(&feed); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusCreated, feed)
+	return nil
+}
+
+// DELETE /user/id/job-feeds/feedId
+//
+// The job posts imported from the feed are left untouched.
+func (jf *JobFeeds) Delete(w http.ResponseWriter, r *http.Request) error {
+	feed, err := jf.getJobFeed(r)
+	if err != nil {
+		return err
+	}
+	if err := jf.fs.Delete(feed.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed job feed with ID %v", feed.ID))
+	return nil
+}
+
+// POST /user/id/job-feeds/feedId/sync
+func (jf *JobFeeds) Sync(w http.ResponseWriter, r *http.Request) error {
+	feed, err := jf.getJobFeed(r)
+	if err != nil {
+		return err
+	}
+	if err := jf.syncer.Sync(feed, time.Now()); err != nil {
+		respondJSON(w, http.StatusBadGateway, feed)
+		return nil
+	}
+	respondJSON(w, http.StatusOK, feed)
+	return nil
+}
+
+// getJobFeed returns the job feed from the URL, making sure it
+// belongs to the user making the request.
+func (jf *JobFeeds) getJobFeed(r *http.Request) (*models.JobFeed, error) {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idParam(r, "feedId")
+	if err != nil {
+		return nil, err
+	}
+	feed, err := jf.fs.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if feed.UserID != userID {
+		return nil, models.ErrNotFound
+	}
+	return feed, nil
+}
@@ -2,176 +2,496 @@ package controllers
 
 import (
 	"fmt"
-	"github.com/gorilla/mux"
 	"net/http"
-	"strconv"
-	"strings"
+	"time"
 
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
 	"github.com/samueldaviddelacruz/go-job-board/API/models"
 )
 
 type Jobs struct {
 	js models.JobPostService
 	ss models.SkillsService
+	bs models.BookmarkService
+	as models.ApplicationService
+	cs models.CompanyService
 }
 
-func NewJobs(js models.JobPostService, ss models.SkillsService) *Jobs {
+func NewJobs(js models.JobPostService, ss models.SkillsService, bs models.BookmarkService, as models.ApplicationService, cs models.CompanyService) *Jobs {
 	return &Jobs{
 		js,
 		ss,
+		bs,
+		as,
+		cs,
 	}
 }
 
 // GET /jobs
-func (j *Jobs) List(w http.ResponseWriter, r *http.Request) {
-	queryObj := models.JobPost{}
-	queryObj.Title = r.URL.Query().Get("q")
-	if userId, err := strconv.Atoi(r.URL.Query().Get("u")); err == nil {
-		queryObj.UserID = uint(userId)
-	}
-	if locationId, err := strconv.Atoi(r.URL.Query().Get("l")); err == nil {
-		queryObj.LocationID = uint(locationId)
-	}
-	if categoryId, err := strconv.Atoi(r.URL.Query().Get("c")); err == nil {
-		queryObj.CategoryID = uint(categoryId)
-	}
-	queryObj.Skills = extractSkillsFromQueryStr(r)
+func (j *Jobs) List(w http.ResponseWriter, r *http.Request) error {
+	queryObj := models.ParseJobPostFilter(r.URL.Query())
 
 	jobs, err := j.js.FindAll(queryObj)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
-	respondJSON(w, http.StatusOK, jobs)
-}
-
-func extractSkillsFromQueryStr(r *http.Request) []models.Skill {
-	var skills []models.Skill
-	if skillsStr := r.URL.Query().Get("sk"); skillsStr != "" {
-		skillsIds := strings.Split(skillsStr, ",")
-		for _, skillId := range skillsIds {
-			if id, err := strconv.Atoi(skillId); err == nil {
-				newSkill := models.Skill{}
-				newSkill.ID = uint(id)
-				skills = append(skills, newSkill)
-			}
+	if user := llctx.User(r.Context()); user != nil {
+		if err := j.js.SetUserStatus(user.ID, jobs); err != nil {
+			return err
 		}
 	}
-	return skills
+	if err := j.js.SetCompanyVerified(jobs); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, jobs)
+	return nil
 }
 
 //POST /jobs
-func (j *Jobs) Create(w http.ResponseWriter, r *http.Request) {
+func (j *Jobs) Create(w http.ResponseWriter, r *http.Request) error {
 
+	form := CreateJobPostRequest{}
+	err := parseJSON(r, &form)
+	if err != nil {
+		return badRequest(err)
+	}
 	jobPost := models.JobPost{
-
+		CompanyID: form.CompanyID,
 	}
-	err := parseJSON(r, &jobPost)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	form.apply(&jobPost)
+	// Job posts are always created for the caller. API keys can
+	// only post for the company they were issued for, which is
+	// the default when the request names none.
+	if user := llctx.User(r.Context()); user != nil {
+		jobPost.UserID = user.ID
+	}
+	if companyID := keyCompanyID(r); companyID != 0 {
+		if jobPost.CompanyID == 0 {
+			jobPost.CompanyID = companyID
+		}
+		if jobPost.CompanyID != companyID {
+			return models.ErrNotFound
+		}
+	}
+	if jobPost.CompanyID != 0 {
+		_, err := j.cs.Authorize(jobPost.CompanyID, jobPost.UserID, models.MemberRole.CanManageJobPosts)
+		if err != nil {
+			return err
+		}
 	}
+	jobPost.EditedBy = jobPost.UserID
 	if err := j.js.Create(&jobPost); err != nil {
-
-		respondJSON(w, http.StatusInternalServerError, "Could not create jobPost")
-		return
+		return err
 	}
 	respondJSON(w, http.StatusCreated, jobPost)
+	return nil
 }
 
-//PUT /jobs/id
-func (j *Jobs) Update(w http.ResponseWriter, r *http.Request) {
+// GET /jobs/id
+//
+// The job post comes with its location, category, skills and
+// company, include adds skillRequirements, company.benefits or
+// company.skills. Job posts that are not listed are only shown
+// to the members of their company. The ETag follows the job
+// post, its company profile and include, If-None-Match answers
+// 304 while it is current.
+func (j *Jobs) Show(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	include := includeParam(r)
+	jobPost, err := j.js.ByIDWith(id, include...)
+	if err != nil {
+		return err
+	}
+	if !jobPost.IsListed(time.Now()) {
+		if err := j.authorizeJob(r, jobPost, models.MemberRole.CanView); err != nil {
+			return err
+		}
+	}
+	etag := jobPostETag(jobPost, include)
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	respondJSON(w, http.StatusOK, jobPost)
+	return nil
+}
 
-	jobPost, err := j.getJobByID(r)
+//PUT|PATCH /jobs/id
+//
+// If-Match must be the ETag of the job post being updated.
+func (j *Jobs) Update(w http.ResponseWriter, r *http.Request) error {
+
+	jobPost, err := j.getOwnJobByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
+	}
+	if err := checkIfMatch(r, jobPost.Version); err != nil {
+		return err
 	}
 
-	parseJSON(r, jobPost)
+	form := newJobPostRequest(jobPost)
+	err = parseUpdate(r, &form)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
+	form.apply(jobPost)
 	if err := j.js.Update(jobPost); err != nil {
+		return err
+	}
+	w.Header().Set("ETag", versionETag(jobPost.Version))
+	respondJSON(w, http.StatusOK, jobPost)
+	return nil
+}
 
-		respondJSON(w, http.StatusInternalServerError, "Could not update jobPost")
-		return
+// POST /jobs/id/close
+func (j *Jobs) Close(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getOwnJobByID(r)
+	if err != nil {
+		return err
 	}
-	respondJSON(w, http.StatusCreated, jobPost)
+	if err := j.js.Close(jobPost.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Closed Jobpost with ID %v", jobPost.ID))
+	return nil
 }
 
 //DELETE /jobs/id
-func (j *Jobs) Delete(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+func (j *Jobs) Delete(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getOwnJobByID(r)
+	if err != nil {
+		return err
+	}
+	if err := j.js.Delete(jobPost.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed Jobpost with ID %v", jobPost.ID))
+	return nil
+}
 
+// GET /jobs/trash
+//
+// The deleted job posts the caller can restore, until they are
+// purged.
+func (j *Jobs) Trash(w http.ResponseWriter, r *http.Request) error {
+	user := llctx.User(r.Context())
+	if user == nil {
+		return models.ErrNotFound
+	}
+	companies, err := j.cs.ByUserID(user.ID)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, "Could not delete jobPost")
-		return
+		return err
 	}
-	if err := j.js.Delete(uint(id)); err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+	// API keys only see the trash of their company.
+	userID, keyCompany := user.ID, keyCompanyID(r)
+	if keyCompany != 0 {
+		userID = 0
 	}
-	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed Jobpost with ID %v", id))
+	var companyIDs []uint
+	for _, company := range companies {
+		if keyCompany != 0 && company.ID != keyCompany {
+			continue
+		}
+		_, err := j.cs.Authorize(company.ID, user.ID, models.MemberRole.CanManageJobPosts)
+		switch err {
+		case nil:
+			companyIDs = append(companyIDs, company.ID)
+		case models.ErrMemberRoleForbidden:
+		default:
+			return err
+		}
+	}
+	jobPosts, err := j.js.Deleted(userID, companyIDs)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, jobPosts)
+	return nil
+}
+
+// POST /jobs/id/restore
+func (j *Jobs) Restore(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	jobPost, err := j.js.DeletedByID(id)
+	if err != nil {
+		return err
+	}
+	if err := j.authorizeJob(r, jobPost, models.MemberRole.CanManageJobPosts); err != nil {
+		return err
+	}
+	if err := j.js.Restore(jobPost.ID); err != nil {
+		return err
+	}
+	jobPost, err = j.js.ByID(jobPost.ID)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("ETag", versionETag(jobPost.Version))
+	respondJSON(w, http.StatusOK, jobPost)
+	return nil
+}
+
+// GET /jobs/id/revisions
+//
+// Each revision lists the fields it changed and the skills it
+// added or removed, oldest first.
+func (j *Jobs) Revisions(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getCompanyJobByID(r, models.MemberRole.CanView)
+	if err != nil {
+		return err
+	}
+	revisions, err := j.js.Revisions(jobPost.ID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, revisions)
+	return nil
+}
+
+// POST /jobs/id/revisions/number/restore
+//
+// The job post gets the fields and skills of the revision back,
+// as a new revision. Skills deleted since are not restored.
+// If-Match must be the ETag of the job post being replaced.
+func (j *Jobs) RestoreRevision(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getOwnJobByID(r)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(r, jobPost.Version); err != nil {
+		return err
+	}
+	number, err := idParam(r, "number")
+	if err != nil {
+		return err
+	}
+	revision, err := j.js.Revision(jobPost.ID, number)
+	if err != nil {
+		return err
+	}
+	if err := j.js.RestoreRevision(jobPost, revision); err != nil {
+		return err
+	}
+	w.Header().Set("ETag", versionETag(jobPost.Version))
+	respondJSON(w, http.StatusOK, jobPost)
+	return nil
+}
+
+// JobPostSkillForm is the payload of AddJobPostSkill. The skill
+// is identified by its ID or any of its names, and is required
+// unless "required" is false.
+type JobPostSkillForm struct {
+	models.Skill
+	Required       *bool                   `json:"required"`
+	MinProficiency models.ProficiencyLevel `json:"minProficiency"`
+	MinYears       uint                    `json:"minYears"`
 }
 
 // PUT /jobs/id/add-skill
-func (j *Jobs) AddJobPostSkill(w http.ResponseWriter, r *http.Request) {
+func (j *Jobs) AddJobPostSkill(w http.ResponseWriter, r *http.Request) error {
 
-	jobPost, err := j.getJobByID(r)
+	jobPost, err := j.getOwnJobByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
 	}
 
-	skill := models.Skill{}
-	parseJSON(r, &skill)
+	form := JobPostSkillForm{}
+	err = parseJSON(r, &form)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
-	if err := j.ss.AddSkillToOwner(jobPost, skill); err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+	if !form.MinProficiency.Valid() {
+		return models.ErrProficiencyInvalid
+	}
+	skill := form.Skill
+	if skill.ID == 0 && skill.SkillName != "" {
+		found, err := j.ss.ByName(skill.SkillName)
+		if err != nil {
+			return err
+		}
+		skill = *found
 	}
-	respondJSON(w, http.StatusCreated, "skills updated successfully")
+	req := models.JobPostSkill{
+		JobPostID:      jobPost.ID,
+		SkillID:        skill.ID,
+		Required:       form.Required == nil || *form.Required,
+		MinProficiency: form.MinProficiency,
+		MinYears:       form.MinYears,
+		EditedBy:       jobPost.EditedBy,
+	}
+	if err := j.js.AddSkill(&req); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
 }
 
 // PUT /user/id/remove-skill
-func (j *Jobs) RemoveJobPostSkill(w http.ResponseWriter, r *http.Request) {
-	jobPost, err := j.getJobByID(r)
+func (j *Jobs) RemoveJobPostSkill(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getOwnJobByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
 	}
 
 	skill := models.Skill{}
-	parseJSON(r, &skill)
+	err = parseJSON(r, &skill)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if err := j.ss.DeleteSkillFromOwner(jobPost, skill); err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
+	}
+
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
+}
+
+// PUT /jobs/id/bookmark
+func (j *Jobs) Bookmark(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getListedJobByID(r)
+	if err != nil {
+		return err
+	}
+	bookmark := models.Bookmark{
+		UserID:    llctx.User(r.Context()).ID,
+		JobPostID: jobPost.ID,
+	}
+	if err := j.bs.Create(&bookmark); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, bookmark)
+	return nil
+}
+
+// DELETE /jobs/id/bookmark
+func (j *Jobs) RemoveBookmark(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getJobByID(r)
+	if err != nil {
+		return err
+	}
+	if err := j.bs.Delete(llctx.User(r.Context()).ID, jobPost.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "bookmark removed successfully")
+	return nil
+}
+
+// PUT /jobs/id/applied
+func (j *Jobs) MarkApplied(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getListedJobByID(r)
+	if err != nil {
+		return err
+	}
+	application := models.Application{
+		UserID:    llctx.User(r.Context()).ID,
+		JobPostID: jobPost.ID,
+	}
+	if err := j.as.Create(&application); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, application)
+	return nil
+}
+
+// GET /jobs/id/applications
+func (j *Jobs) Applications(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getCompanyJobByID(r, models.MemberRole.CanView)
+	if err != nil {
+		return err
 	}
+	applications, err := j.as.ByJobPostID(jobPost.ID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, applications)
+	return nil
+}
 
-	respondJSON(w, http.StatusCreated, "skills updated successfully")
+// DELETE /jobs/id/applied
+func (j *Jobs) UnmarkApplied(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := j.getJobByID(r)
+	if err != nil {
+		return err
+	}
+	if err := j.as.Delete(llctx.User(r.Context()).ID, jobPost.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "application removed successfully")
+	return nil
 }
 
 func (j *Jobs) getJobByID(r *http.Request) (*models.JobPost, error) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := idParam(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	jobPost, err := j.js.ByID(id)
 	if err != nil {
 
 		return nil, err
 	}
-	jobPost, err := j.js.ByID(uint(id))
+	return jobPost, nil
+}
+
+// getListedJobByID returns the job post from the URL if it is
+// listed, candidates can not find the other ones.
+func (j *Jobs) getListedJobByID(r *http.Request) (*models.JobPost, error) {
+	jobPost, err := j.getJobByID(r)
 	if err != nil {
+		return nil, err
+	}
+	if !jobPost.IsListed(time.Now()) {
+		return nil, models.ErrNotFound
+	}
+	return jobPost, nil
+}
 
+// getOwnJobByID returns the job post from the URL, making sure
+// the caller can manage it.
+func (j *Jobs) getOwnJobByID(r *http.Request) (*models.JobPost, error) {
+	return j.getCompanyJobByID(r, models.MemberRole.CanManageJobPosts)
+}
+
+// getCompanyJobByID returns the job post from the URL, making
+// sure the caller is a member of its company with an allowed
+// role. Job posts without a company belong to their author. The
+// caller is recorded as the editor of the changes.
+func (j *Jobs) getCompanyJobByID(r *http.Request, allowed func(models.MemberRole) bool) (*models.JobPost, error) {
+	jobPost, err := j.getJobByID(r)
+	if err != nil {
 		return nil, err
 	}
+	if err := j.authorizeJob(r, jobPost, allowed); err != nil {
+		return nil, err
+	}
+	jobPost.EditedBy = llctx.User(r.Context()).ID
 	return jobPost, nil
 }
+
+// authorizeJob makes sure the caller is a member of the company
+// of the job post with an allowed role, or its author. API keys
+// are limited to the job posts of their company.
+func (j *Jobs) authorizeJob(r *http.Request, jobPost *models.JobPost, allowed func(models.MemberRole) bool) error {
+	user := llctx.User(r.Context())
+	if user == nil {
+		return models.ErrNotFound
+	}
+	if companyID := keyCompanyID(r); companyID != 0 && companyID != jobPost.CompanyID {
+		return models.ErrNotFound
+	}
+	if jobPost.CompanyID == 0 {
+		if user.ID != jobPost.UserID {
+			return models.ErrNotFound
+		}
+		return nil
+	}
+	_, err := j.cs.Authorize(jobPost.CompanyID, user.ID, allowed)
+	return err
+}
@@ -16,11 +16,11 @@ func NewLocations(ls models.LocationService) *Locations {
 }
 
 // GET /locations
-func (c *Locations) List(w http.ResponseWriter, r *http.Request) {
+func (c *Locations) List(w http.ResponseWriter, r *http.Request) error {
 	locations, err := c.ls.FindAll()
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	respondJSON(w, http.StatusOK, locations)
+	return nil
 }
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/imaging"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/storage"
+)
+
+// logoField is the multipart field of the uploaded logo
+const logoField = "logo"
+
+type Logos struct {
+	us    models.UserService
+	cs    models.CompanyService
+	store storage.Storage
+}
+
+func NewLogos(us models.UserService, cs models.CompanyService, store storage.Storage) *Logos {
+	return &Logos{
+		us:    us,
+		cs:    cs,
+		store: store,
+	}
+}
+
+// PUT /user/id/company-profile/logo
+//
+// Expects a multipart form with the image in the "logo" field.
+// The logo replaces the previous one, whose files are removed.
+// If-Match must be the ETag of the profile.
+func (l *Logos) Upload(w http.ResponseWriter, r *http.Request) error {
+	profile, err := l.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+
+	// Leave room for the rest of the multipart body
+	r.Body = http.MaxBytesReader(w, r.Body, imaging.MaxUploadSize+1<<20)
+	file, _, err := r.FormFile(logoField)
+	if err != nil {
+		return badRequest(fmt.Errorf("Missing %q file: %v", logoField, err))
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(file, imaging.MaxUploadSize+1))
+	if err != nil {
+		return badRequest(err)
+	}
+	variants, err := imaging.ProcessLogo(data)
+	switch err {
+	case nil:
+	case imaging.ErrImageTooLarge:
+		return withStatus(http.StatusRequestEntityTooLarge, err)
+	case imaging.ErrFormatUnsupported:
+		return withStatus(http.StatusUnsupportedMediaType, err)
+	case imaging.ErrImageTooSmall:
+		return withStatus(http.StatusUnprocessableEntity, err)
+	default:
+		return err
+	}
+
+	// Keys change with the content, so the files can be cached
+	// forever.
+	sum := sha256.Sum256(data)
+	version := hex.EncodeToString(sum[:8])
+	oldKeys := logoKeys(profile)
+	var keys []string
+	updated := *profile
+	for _, variant := range variants {
+		key := fmt.Sprintf("logos/%d/%s-%s.%s", profile.ID, version, variant.Name, variant.Extension)
+		if err := l.store.Put(key, variant.ContentType, bytes.NewReader(variant.Content)); err != nil {
+			l.deleteKeys(unused(keys, oldKeys))
+			return err
+		}
+		keys = append(keys, key)
+		switch variant.Name {
+		case imaging.VariantOriginal:
+			updated.CompanyLogoUrl = l.store.URL(key)
+		case imaging.VariantThumbnail:
+			updated.LogoThumbnailUrl = l.store.URL(key)
+		case imaging.VariantHeader:
+			updated.LogoHeaderUrl = l.store.URL(key)
+		}
+	}
+	updated.LogoKeys = strings.Join(keys, ",")
+	if err := l.us.UpdateCompanyLogo(&updated); err != nil {
+		l.deleteKeys(unused(keys, oldKeys))
+		return err
+	}
+	l.deleteKeys(unused(oldKeys, keys))
+	w.Header().Set("ETag", versionETag(updated.Version))
+	respondJSON(w, http.StatusOK, updated)
+	return nil
+}
+
+// DELETE /user/id/company-profile/logo
+//
+// If-Match must be the ETag of the profile.
+func (l *Logos) Delete(w http.ResponseWriter, r *http.Request) error {
+	profile, err := l.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	oldKeys := logoKeys(profile)
+	updated := *profile
+	updated.CompanyLogoUrl, updated.LogoThumbnailUrl, updated.LogoHeaderUrl, updated.LogoKeys = "", "", "", ""
+	if err := l.us.UpdateCompanyLogo(&updated); err != nil {
+		return err
+	}
+	l.deleteKeys(oldKeys)
+	w.Header().Set("ETag", versionETag(updated.Version))
+	respondJSON(w, http.StatusOK, "logo removed successfully")
+	return nil
+}
+
+// getCompanyProfile returns the company profile from the URL,
+// making sure the caller is its user or manages the members of
+// its company, and that If-Match is the version of the profile.
+func (l *Logos) getCompanyProfile(r *http.Request) (*models.CompanyProfile, error) {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	user, err := l.us.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	profile := user.CompanyProfile
+	if profile == nil {
+		return nil, models.ErrNotFound
+	}
+	caller := llctx.User(r.Context())
+	if caller == nil {
+		return nil, models.ErrNotFound
+	}
+	if caller.ID != user.ID {
+		if profile.CompanyID == 0 {
+			return nil, models.ErrNotFound
+		}
+		_, err := l.cs.Authorize(profile.CompanyID, caller.ID, models.MemberRole.CanManageMembers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkIfMatch(r, profile.Version); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// deleteKeys removes stored files, failures only leave unused
+// files behind and are logged.
+func (l *Logos) deleteKeys(keys []string) {
+	for _, key := range keys {
+		if err := l.store.Delete(key); err != nil {
+			log.Printf("logos: could not delete %s: %v", key, err)
+		}
+	}
+}
+
+func logoKeys(profile *models.CompanyProfile) []string {
+	if profile.LogoKeys == "" {
+		return nil
+	}
+	return strings.Split(profile.LogoKeys, ",")
+}
+
+// unused returns the keys missing from current, the same logo
+// uploaded twice is stored under the same keys.
+func unused(keys, current []string) []string {
+	kept := map[string]bool{}
+	for _, key := range current {
+		kept[key] = true
+	}
+	var missing []string
+	for _, key := range keys {
+		if !kept[key] {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
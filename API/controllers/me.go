@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/email"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+var (
+	errExportFormat        = errors.New("format must be zip or json")
+	errDeletionUnscheduled = errors.New("the deletion of the account is not scheduled")
+)
+
+// Me is the controller of the resources belonging to the user
+// making the request, it must be behind the RequireUser
+// middleware.
+type Me struct {
+	bs      models.BookmarkService
+	as      models.ApplicationService
+	us      models.UserService
+	emailer *email.Client
+}
+
+func NewMe(bs models.BookmarkService, as models.ApplicationService, us models.UserService, emailer *email.Client) *Me {
+	return &Me{
+		bs:      bs,
+		as:      as,
+		us:      us,
+		emailer: emailer,
+	}
+}
+
+// GET /me/bookmarks
+func (m *Me) Bookmarks(w http.ResponseWriter, r *http.Request) error {
+	bookmarks, err := m.bs.ByUserID(llctx.User(r.Context()).ID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, bookmarks)
+	return nil
+}
+
+// GET /me/applications
+func (m *Me) Applications(w http.ResponseWriter, r *http.Request) error {
+	applications, err := m.as.ByUserID(llctx.User(r.Context()).ID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, applications)
+	return nil
+}
+
+// GET /me/export
+// GET /me/export?format=json
+//
+// Export responds with everything stored about the user, as a
+// ZIP archive of JSON files unless format is json.
+func (m *Me) Export(w http.ResponseWriter, r *http.Request) error {
+	export, err := m.us.Export(llctx.User(r.Context()).ID)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("export-%s", export.ExportedAt.Format("2006-01-02"))
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+		respondJSON(w, http.StatusOK, export)
+		return nil
+	case "", "zip":
+	default:
+		return badRequest(errExportFormat)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	files := []struct {
+		name    string
+		payload interface{}
+	}{
+		{"account.json", export.Account},
+		{"company-profile.json", export.CompanyProfile},
+		{"company-memberships.json", export.CompanyMemberships},
+		{"job-posts.json", export.JobPosts},
+		{"job-feeds.json", export.JobFeeds},
+		{"applications.json", export.Applications},
+		{"bookmarks.json", export.Bookmarks},
+		{"saved-searches.json", export.SavedSearches},
+		{"webhooks.json", export.Webhooks},
+		{"api-keys.json", export.APIKeys},
+		{"oauth-connections.json", export.OAuthConnections},
+	}
+	for _, file := range files {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.payload); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".zip"))
+	w.WriteHeader(http.StatusOK)
+	_, err = archive.WriteTo(w)
+	return err
+}
+
+// AccountDeletionResponse tells when the account of the user
+// will be deleted.
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletionScheduledAt"`
+}
+
+// DELETE /me
+//
+// Delete schedules the deletion of the account of the user at
+// the end of a grace period and emails them about it. Asking
+// again keeps the date first scheduled.
+func (m *Me) Delete(w http.ResponseWriter, r *http.Request) error {
+	user := llctx.User(r.Context())
+	if user.DeletionScheduledAt == nil {
+		if err := m.us.ScheduleDeletion(user); err != nil {
+			return err
+		}
+		if err := m.emailer.AccountDeletion(user.Email, *user.DeletionScheduledAt); err != nil {
+			// Let the deletion be asked for again
+			m.us.CancelDeletion(user)
+			return err
+		}
+	}
+	respondJSON(w, http.StatusAccepted, AccountDeletionResponse{
+		DeletionScheduledAt: *user.DeletionScheduledAt,
+	})
+	return nil
+}
+
+// POST /me/cancel-deletion
+func (m *Me) CancelDeletion(w http.ResponseWriter, r *http.Request) error {
+	user := llctx.User(r.Context())
+	if user.DeletionScheduledAt == nil {
+		return withStatus(http.StatusConflict, errDeletionUnscheduled)
+	}
+	if err := m.us.CancelDeletion(user); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/email"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// Members manages the members of a company and the invitations
+// to join it.
+type Members struct {
+	cs      models.CompanyService
+	emailer *email.Client
+}
+
+func NewMembers(cs models.CompanyService, emailer *email.Client) *Members {
+	return &Members{
+		cs:      cs,
+		emailer: emailer,
+	}
+}
+
+// GET /me/companies
+func (m *Members) Companies(w http.ResponseWriter, r *http.Request) error {
+	companies, err := m.cs.ByUserID(llctx.User(r.Context()).ID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, companies)
+	return nil
+}
+
+// GET /companies/id/members
+func (m *Members) List(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanView)
+	if err != nil {
+		return err
+	}
+	members, err := m.cs.Members(actor.CompanyID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, members)
+	return nil
+}
+
+// PUT /companies/id/members/userId
+func (m *Members) UpdateRole(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanManageMembers)
+	if err != nil {
+		return err
+	}
+	member, err := m.getMember(r, actor.CompanyID)
+	if err != nil {
+		return err
+	}
+	form := struct {
+		Role models.MemberRole `json:"role"`
+	}{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	if !form.Role.Valid() {
+		return models.ErrMemberRoleInvalid
+	}
+	if !actor.Role.CanGrant(member.Role) || !actor.Role.CanGrant(form.Role) {
+		return models.ErrMemberRoleForbidden
+	}
+	member.Role = form.Role
+	if err := m.cs.SetMemberRole(member); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, member)
+	return nil
+}
+
+// DELETE /companies/id/members/userId
+//
+// Members can leave the company themselves.
+func (m *Members) Remove(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanView)
+	if err != nil {
+		return err
+	}
+	member, err := m.getMember(r, actor.CompanyID)
+	if err != nil {
+		return err
+	}
+	if member.UserID != actor.UserID && !actor.Role.CanGrant(member.Role) {
+		return models.ErrMemberRoleForbidden
+	}
+	if err := m.cs.RemoveMember(member.CompanyID, member.UserID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed member with user ID %v", member.UserID))
+	return nil
+}
+
+// GET /companies/id/invitations
+func (m *Members) Invitations(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanManageMembers)
+	if err != nil {
+		return err
+	}
+	invitations, err := m.cs.Invitations(actor.CompanyID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, invitations)
+	return nil
+}
+
+// POST /companies/id/invitations
+//
+// Emails an invitation to join the company with the role
+// provided, which expires after a week.
+func (m *Members) Invite(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanManageMembers)
+	if err != nil {
+		return err
+	}
+	form := struct {
+		Email string            `json:"email"`
+		Role  models.MemberRole `json:"role"`
+	}{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	if form.Role.Valid() && !actor.Role.CanGrant(form.Role) {
+		return models.ErrMemberRoleForbidden
+	}
+	company, err := m.cs.ByID(actor.CompanyID)
+	if err != nil {
+		return err
+	}
+	invitation := models.CompanyInvitation{
+		CompanyID:   actor.CompanyID,
+		Email:       form.Email,
+		Role:        form.Role,
+		InvitedByID: actor.UserID,
+	}
+	if err := m.cs.CreateInvitation(&invitation); err != nil {
+		return err
+	}
+	if err := m.emailer.Invitation(company.Name, invitation); err != nil {
+		// Let the invitation be sent again
+		m.cs.RevokeInvitation(invitation.CompanyID, invitation.ID)
+		return err
+	}
+	respondJSON(w, http.StatusCreated, invitation)
+	return nil
+}
+
+// DELETE /companies/id/invitations/invitationId
+func (m *Members) RevokeInvitation(w http.ResponseWriter, r *http.Request) error {
+	actor, err := m.authorize(r, models.MemberRole.CanManageMembers)
+	if err != nil {
+		return err
+	}
+	id, err := idParam(r, "invitationId")
+	if err != nil {
+		return err
+	}
+	if err := m.cs.RevokeInvitation(actor.CompanyID, id); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Revoked invitation with ID %v", id))
+	return nil
+}
+
+// POST /invitations/accept
+//
+// Expects the token emailed with the invitation, the caller
+// must be signed in with the email address invited.
+func (m *Members) Accept(w http.ResponseWriter, r *http.Request) error {
+	form := struct {
+		Token string `json:"token"`
+	}{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	member, err := m.cs.AcceptInvitation(form.Token, llctx.User(r.Context()))
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusCreated, member)
+	return nil
+}
+
+// authorize returns the membership of the caller in the company
+// from the URL, as long as its role is allowed.
+func (m *Members) authorize(r *http.Request, allowed func(models.MemberRole) bool) (*models.CompanyMember, error) {
+	companyID, err := idParam(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return m.cs.Authorize(companyID, llctx.User(r.Context()).ID, allowed)
+}
+
+func (m *Members) getMember(r *http.Request, companyID uint) (*models.CompanyMember, error) {
+	userID, err := idParam(r, "userId")
+	if err != nil {
+		return nil, err
+	}
+	return m.cs.Member(companyID, userID)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,6 +16,11 @@ import (
 	"golang.org/x/oauth2"
 )
 
+var (
+	errOAuthServiceInvalid = errors.New("Invalid OAuth2 Service")
+	errOAuthStateInvalid   = errors.New("Invalid state provided")
+)
+
 func NewAuths(os models.OAuthService, configs map[string]*oauth2.Config) *Oauths {
 	return &Oauths{
 		os: os,
@@ -29,13 +35,12 @@ type Oauths struct {
 	configs map[string]*oauth2.Config
 }
 
-func (o *Oauths) Connect(w http.ResponseWriter, r *http.Request) {
+func (o *Oauths) Connect(w http.ResponseWriter, r *http.Request) error {
 	vars := mux.Vars(r)
 	service := vars["service"]
 	oauthConfig, ok := o.configs[service]
 	if !ok {
-		http.Error(w, "Invalid OAuth2 Service", http.StatusBadRequest)
-		return
+		return badRequest(errOAuthServiceInvalid)
 	}
 	state := csrf.Token(r)
 	cookie := http.Cookie{
@@ -47,26 +52,24 @@ func (o *Oauths) Connect(w http.ResponseWriter, r *http.Request) {
 
 	url := oauthConfig.AuthCodeURL(state)
 	http.Redirect(w, r, url, http.StatusFound)
+	return nil
 }
 
-func (o *Oauths) Callback(w http.ResponseWriter, r *http.Request) {
+func (o *Oauths) Callback(w http.ResponseWriter, r *http.Request) error {
 	vars := mux.Vars(r)
 	service := vars["service"]
 	oauthConfig, ok := o.configs[service]
 	if !ok {
-		http.Error(w, "Invalid OAuth2 Service", http.StatusBadRequest)
-		return
+		return badRequest(errOAuthServiceInvalid)
 	}
 
 	r.ParseForm()
 	state := r.FormValue("state")
 	cookie, err := r.Cookie("oauth_state")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return badRequest(err)
 	} else if cookie == nil || cookie.Value != state {
-		http.Error(w, "Invalid state provided", http.StatusBadRequest)
-		return
+		return badRequest(errOAuthStateInvalid)
 	}
 
 	cookie.Value = ""
@@ -81,8 +84,7 @@ func (o *Oauths) Callback(w http.ResponseWriter, r *http.Request) {
 	if err == models.ErrNotFound {
 
 	} else if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	} else {
 		o.os.Delete(existing.ID)
 	}
@@ -94,8 +96,8 @@ func (o *Oauths) Callback(w http.ResponseWriter, r *http.Request) {
 	}
 	err = o.os.Create(&userOAuth)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	}
 	fmt.Fprintf(w, "%+v", token)
+	return nil
 }
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/pages"
+)
+
+const htmlContentType = "text/html; charset=utf-8"
+
+type Pages struct {
+	js       models.JobPostService
+	us       models.UserService
+	renderer *pages.Renderer
+}
+
+func NewPages(js models.JobPostService, us models.UserService, renderer *pages.Renderer) *Pages {
+	return &Pages{
+		js:       js,
+		us:       us,
+		renderer: renderer,
+	}
+}
+
+// GET /j/slug
+func (p *Pages) JobPost(w http.ResponseWriter, r *http.Request) error {
+	jobPost, err := p.js.BySlug(mux.Vars(r)["slug"])
+	if err == models.ErrNotFound || (err == nil && !jobPost.IsListed(time.Now())) {
+		p.notFound(w)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	page := pages.JobPostPage{JobPost: *jobPost}
+	if profile, err := p.us.CompanyProfileForJobPost(jobPost); err == nil {
+		page.Company = profile
+	} else if err != models.ErrNotFound {
+		return err
+	}
+
+	w.Header().Set("Content-Type", htmlContentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := p.renderer.JobPost(w, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// GET /c/slug
+func (p *Pages) Company(w http.ResponseWriter, r *http.Request) error {
+	profile, err := p.us.CompanyProfileBySlug(mux.Vars(r)["slug"])
+	if err == models.ErrNotFound {
+		p.notFound(w)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	jobPosts, err := p.js.ByCompanyID(profile.CompanyID)
+	if err != nil {
+		return err
+	}
+	page := pages.CompanyPage{Company: *profile}
+	now := time.Now()
+	for _, jp := range jobPosts {
+		if jp.IsListed(now) {
+			page.JobPosts = append(page.JobPosts, jp)
+		}
+	}
+
+	w.Header().Set("Content-Type", htmlContentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := p.renderer.Company(w, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+func (p *Pages) notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", htmlContentType)
+	w.WriteHeader(http.StatusNotFound)
+	if err := p.renderer.NotFound(w); err != nil {
+		log.Printf("pages: could not render not found page: %v", err)
+	}
+}
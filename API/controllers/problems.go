@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Errors []models.FieldError `json:"errors,omitempty"`
+	// CorrelationID identifies the failure in the logs of the
+	// server, for the unexpected errors only.
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// publicError is implemented by the errors whose message can
+// be shown to the caller.
+type publicError interface {
+	Public() string
+}
+
+func respondProblem(w http.ResponseWriter, problem Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	response, err := json.Marshal(problem)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// JobPostRequest is the body of PUT and PATCH /jobs/id, the
+// only fields of a job post the caller can change. Leaving out
+// publishedAt keeps the publication date.
+type JobPostRequest struct {
+	Title          string              `json:"title"`
+	LocationID     uint                `json:"locationId"`
+	CategoryID     uint                `json:"categoryId"`
+	Description    string              `json:"description"`
+	ApplyAt        string              `json:"applyAt"`
+	PublishedAt    *time.Time          `json:"publishedAt"`
+	ValidThrough   *time.Time          `json:"validThrough"`
+	SalaryMin      uint                `json:"salaryMin"`
+	SalaryMax      uint                `json:"salaryMax"`
+	SalaryCurrency string              `json:"salaryCurrency"`
+	SalaryPeriod   models.SalaryPeriod `json:"salaryPeriod"`
+}
+
+// CreateJobPostRequest is the body of POST /jobs. The job post
+// goes to the company provided, which the caller must be able
+// to post for.
+type CreateJobPostRequest struct {
+	JobPostRequest
+	CompanyID uint `json:"companyId"`
+}
+
+func newJobPostRequest(jp *models.JobPost) JobPostRequest {
+	return JobPostRequest{
+		Title:          jp.Title,
+		LocationID:     jp.LocationID,
+		CategoryID:     jp.CategoryID,
+		Description:    jp.Description,
+		ApplyAt:        jp.ApplyAt,
+		PublishedAt:    jp.PublishedAt,
+		ValidThrough:   jp.ValidThrough,
+		SalaryMin:      jp.SalaryMin,
+		SalaryMax:      jp.SalaryMax,
+		SalaryCurrency: jp.SalaryCurrency,
+		SalaryPeriod:   jp.SalaryPeriod,
+	}
+}
+
+func (req JobPostRequest) apply(jp *models.JobPost) {
+	jp.Title = req.Title
+	jp.LocationID = req.LocationID
+	jp.CategoryID = req.CategoryID
+	jp.Description = req.Description
+	jp.ApplyAt = req.ApplyAt
+	if req.PublishedAt != nil {
+		jp.PublishedAt = req.PublishedAt
+	}
+	jp.ValidThrough = req.ValidThrough
+	jp.SalaryMin = req.SalaryMin
+	jp.SalaryMax = req.SalaryMax
+	jp.SalaryCurrency = req.SalaryCurrency
+	jp.SalaryPeriod = req.SalaryPeriod
+}
+
+// UserRequest is the body of PUT and PATCH /user/id. The
+// password is only changed when one is provided.
+type UserRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
+func (req UserRequest) apply(user *models.User) {
+	user.Email = req.Email
+	user.Password = req.Password
+}
+
+// UserResponse is the account of a user, as returned by the
+// user endpoints.
+type UserResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func newUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// UserDetailsResponse is the user returned by GET /users/id,
+// JobPosts is only set when included.
+type UserDetailsResponse struct {
+	UserResponse
+	CompanyProfile *models.CompanyProfile `json:"companyProfile,omitempty"`
+	Skills         []models.Skill         `json:"skills"`
+	JobPosts       []models.JobPost       `json:"jobPosts,omitempty"`
+}
+
+func newUserDetailsResponse(user *models.User) UserDetailsResponse {
+	skills := user.Skills
+	if skills == nil {
+		skills = []models.Skill{}
+	}
+	return UserDetailsResponse{
+		UserResponse:   newUserResponse(user),
+		CompanyProfile: user.CompanyProfile,
+		Skills:         skills,
+		JobPosts:       user.JobPosts,
+	}
+}
+
+// CompanyProfileRequest is the body of PUT and PATCH
+// /user/id/company-profile. The slug, the logo and the domain
+// verification have endpoints of their own.
+type CompanyProfileRequest struct {
+	CompanyName string `json:"companyName"`
+	Website     string `json:"website"`
+	FoundedYear uint   `json:"foundedYear"`
+	Description string `json:"description"`
+}
+
+func newCompanyProfileRequest(profile *models.CompanyProfile) CompanyProfileRequest {
+	return CompanyProfileRequest{
+		CompanyName: profile.CompanyName,
+		Website:     profile.Website,
+		FoundedYear: profile.FoundedYear,
+		Description: profile.Description,
+	}
+}
+
+func (req CompanyProfileRequest) apply(profile *models.CompanyProfile) {
+	profile.CompanyName = req.CompanyName
+	profile.Website = req.Website
+	profile.FoundedYear = req.FoundedYear
+	profile.Description = req.Description
+}
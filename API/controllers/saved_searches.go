@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+type SavedSearches struct {
+	sss models.SavedSearchService
+}
+
+func NewSavedSearches(sss models.SavedSearchService) *SavedSearches {
+	return &SavedSearches{
+		sss,
+	}
+}
+
+// GET /user/id/saved-searches
+func (s *SavedSearches) List(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	searches, err := s.sss.ByUserID(userID)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, searches)
+	return nil
+}
+
+// POST /user/id/saved-searches
+//
+// The query is the query string of a GET /jobs request, like
+// "q=golang&l=1".
+func (s *SavedSearches) Create(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	search := models.SavedSearch{}
+	err = parseJSON(r, &search)
+	if err != nil {
+		return badRequest(err)
+	}
+	search.ID = 0
+	search.UserID = userID
+	search.LastNotifiedAt = nil
+	if err := s.sss.Create(&search); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusCreated, search)
+	return nil
+}
+
+// DELETE /user/id/saved-searches/searchId
+func (s *SavedSearches) Delete(w http.ResponseWriter, r *http.Request) error {
+	search, err := s.getSavedSearch(r)
+	if err != nil {
+		return err
+	}
+	if err := s.sss.Delete(search.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed saved search with ID %v", search.ID))
+	return nil
+}
+
+// GET|POST /saved-searches/id/unsubscribe?token=
+//
+// This is the one-click link of the alert emails, so it is
+// not authenticated, the token proves the link was sent by us.
+func (s *SavedSearches) Unsubscribe(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	err = s.sss.Unsubscribe(id, r.URL.Query().Get("token"))
+	if err == models.ErrUnsubscribeTokenInvalid {
+		return withStatus(http.StatusForbidden, err)
+	}
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "you will no longer receive alerts for this search")
+	return nil
+}
+
+// getSavedSearch returns the saved search from the URL, making
+// sure it belongs to the user making the request.
+func (s *SavedSearches) getSavedSearch(r *http.Request) (*models.SavedSearch, error) {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idParam(r, "searchId")
+	if err != nil {
+		return nil, err
+	}
+	search, err := s.sss.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if search.UserID != userID {
+		return nil, models.ErrNotFound
+	}
+	return search, nil
+}
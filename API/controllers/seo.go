@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+)
+
+type SEO struct {
+	js       models.JobPostService
+	us       models.UserService
+	cs       models.CategoryService
+	ls       models.LocationService
+	sitemaps *seo.Sitemaps
+	urls     seo.URLs
+}
+
+func NewSEO(js models.JobPostService, us models.UserService, cs models.CategoryService, ls models.LocationService, sitemaps *seo.Sitemaps, urls seo.URLs) *SEO {
+	return &SEO{
+		js:       js,
+		us:       us,
+		cs:       cs,
+		ls:       ls,
+		sitemaps: sitemaps,
+		urls:     urls,
+	}
+}
+
+// GET /jobs/id/jsonld
+func (s *SEO) JobPosting(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	jobPost, err := s.js.ByID(id)
+	if err != nil {
+		return err
+	}
+	if !jobPost.IsListed(time.Now()) {
+		return models.ErrNotFound
+	}
+	if err := s.loadCatalog(jobPost); err != nil {
+		return err
+	}
+	var company *models.CompanyProfile
+	if profile, err := s.us.CompanyProfileForJobPost(jobPost); err == nil {
+		company = profile
+	} else if err != models.ErrNotFound {
+		return err
+	}
+
+	body, err := seo.NewJobPosting(*jobPost, company, s.urls).Render()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", seo.JSONLDContentType)
+	w.Write(body)
+	return nil
+}
+
+// GET /sitemap.xml
+func (s *SEO) SitemapIndex(w http.ResponseWriter, r *http.Request) error {
+	sitemap, err := s.sitemaps.Index(time.Now())
+	if err != nil {
+		return err
+	}
+	serveSitemap(w, sitemap)
+	return nil
+}
+
+// GET /sitemaps/name.xml
+func (s *SEO) Sitemap(w http.ResponseWriter, r *http.Request) error {
+	sitemap, err := s.sitemaps.Page(mux.Vars(r)["name"], time.Now())
+	if err == seo.ErrSitemapNotFound {
+		return withStatus(http.StatusNotFound, err)
+	}
+	if err != nil {
+		return err
+	}
+	serveSitemap(w, sitemap)
+	return nil
+}
+
+func serveSitemap(w http.ResponseWriter, sitemap *seo.Sitemap) {
+	w.Header().Set("Content-Type", seo.SitemapContentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if !sitemap.Updated.IsZero() {
+		w.Header().Set("Last-Modified", sitemap.Updated.Format(http.TimeFormat))
+	}
+	w.Write(sitemap.Body)
+}
+
+// loadCatalog sets the category and location of the job post,
+// which ByID does not load.
+func (s *SEO) loadCatalog(jobPost *models.JobPost) error {
+	categories, err := s.cs.FindAll()
+	if err != nil {
+		return err
+	}
+	for i := range categories {
+		if categories[i].ID == jobPost.CategoryID {
+			jobPost.Category = &categories[i]
+		}
+	}
+	locations, err := s.ls.FindAll()
+	if err != nil {
+		return err
+	}
+	for i := range locations {
+		if locations[i].ID == jobPost.LocationID {
+			jobPost.Location = &locations[i]
+		}
+	}
+	return nil
+}
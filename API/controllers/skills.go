@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+)
+
+type Skills struct {
+	ss models.SkillsService
+}
+
+func NewSkills(ss models.SkillsService) *Skills {
+	return &Skills{
+		ss,
+	}
+}
+
+// GET /skills
+func (s *Skills) List(w http.ResponseWriter, r *http.Request) error {
+	skills, err := s.ss.FindAll()
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, skills)
+	return nil
+}
+
+// GET /skills?prefix=
+func (s *Skills) Autocomplete(w http.ResponseWriter, r *http.Request) error {
+	limit := defaultAutocompleteLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= maxAutocompleteLimit {
+		limit = l
+	}
+	skills, err := s.ss.Autocomplete(r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, skills)
+	return nil
+}
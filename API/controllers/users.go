@@ -1,21 +1,28 @@
 package controllers
 
 import (
-	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
+	"time"
 
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
 	"github.com/samueldaviddelacruz/go-job-board/API/models"
 )
 
+const (
+	defaultRecommendationsLimit = 20
+	maxRecommendationsLimit     = 100
+)
+
 // NewUsers is used to create a new Users controller.
 // This function will panic if the templates are not
 // parsed correctly, and should only be used during
 // initial setup
-func NewUsers(us models.UserService, ss models.SkillsService) *Users {
+func NewUsers(us models.UserService, ss models.SkillsService, js models.JobPostService) *Users {
 	return &Users{
 		us: us,
 		ss: ss,
+		js: js,
 	}
 }
 
@@ -23,187 +30,291 @@ func NewUsers(us models.UserService, ss models.SkillsService) *Users {
 type Users struct {
 	us models.UserService
 	ss models.SkillsService
+	js models.JobPostService
 }
 type Credentials struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
-// PUT /user/id
-func (u *Users) Update(w http.ResponseWriter, r *http.Request) {
+// GET /users/id
+//
+// Users can only read their own account, along with their
+// company profile and skills. include adds jobPosts,
+// companyProfile.benefits or companyProfile.skills.
+func (u *Users) Show(w http.ResponseWriter, r *http.Request) error {
+	id, err := idParam(r, "id")
+	if err != nil {
+		return err
+	}
+	if caller := llctx.User(r.Context()); caller == nil || caller.ID != id {
+		return models.ErrNotFound
+	}
+	user, err := u.us.ByIDWith(id, includeParam(r)...)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, newUserDetailsResponse(user))
+	return nil
+}
+
+// PUT|PATCH /user/id
+func (u *Users) Update(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, "Invalid user ID")
-		return
+		return err
 	}
 
-	err = parseJSON(r, companyUser)
+	form := UserRequest{Email: companyUser.Email}
+	err = parseUpdate(r, &form)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
+	form.apply(companyUser)
 
 	if err := u.us.Update(companyUser); err != nil {
-		//vd.SetAlert(err)
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
 	}
 
-	respondJSON(w, http.StatusCreated, "resource updated successfully")
+	respondJSON(w, http.StatusOK, newUserResponse(companyUser))
+	return nil
 }
 
-// PUT /user/id/company-profile
-func (u *Users) UpdateCompanyProfile(w http.ResponseWriter, r *http.Request) {
+// GET /user/id/company-profile
+//
+// The ETag is the version of the profile, If-None-Match answers
+// 304 while it is current.
+func (u *Users) CompanyProfile(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, "Invalid user ID")
-		return
+		return err
+	}
+	if companyUser.CompanyProfile == nil {
+		return models.ErrNotFound
+	}
+	etag := versionETag(companyUser.CompanyProfile.Version)
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
 	}
-	newCompanyProfile := &models.CompanyProfile{}
-	err = parseJSON(r, newCompanyProfile)
+	respondJSON(w, http.StatusOK, companyUser.CompanyProfile)
+	return nil
+}
+
+// PUT|PATCH /user/id/company-profile
+//
+// If-Match must be the ETag of the profile being updated, it is
+// only optional when creating the profile.
+func (u *Users) UpdateCompanyProfile(w http.ResponseWriter, r *http.Request) error {
+	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return err
 	}
 	if companyUser.CompanyProfile == nil {
 		companyUser.CompanyProfile = &models.CompanyProfile{}
+	} else if err := checkIfMatch(r, companyUser.CompanyProfile.Version); err != nil {
+		return err
+	}
+	form := newCompanyProfileRequest(companyUser.CompanyProfile)
+	err = parseUpdate(r, &form)
+	if err != nil {
+		return badRequest(err)
 	}
-	companyUser.CompanyProfile.Description = newCompanyProfile.Description
-	companyUser.CompanyProfile.Website = newCompanyProfile.Website
-	companyUser.CompanyProfile.CompanyLogoUrl = newCompanyProfile.CompanyLogoUrl
-	companyUser.CompanyProfile.FoundedYear = newCompanyProfile.FoundedYear
+	form.apply(companyUser.CompanyProfile)
 
 	if err := u.us.Update(companyUser); err != nil {
-		respondJSON(w, http.StatusInternalServerError, err.Error())
-		return
+		return err
 	}
+	w.Header().Set("ETag", versionETag(companyUser.CompanyProfile.Version))
 
-	respondJSON(w, http.StatusCreated, "resource updated successfully")
+	respondJSON(w, http.StatusOK, "resource updated successfully")
+	return nil
 }
 
 // PUT /user/id/company-profile/add-skill
-func (u *Users) AddCompanyProfileSkill(w http.ResponseWriter, r *http.Request) {
+func (u *Users) AddCompanyProfileSkill(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	skill := models.Skill{}
 	err = parseJSON(r, &skill)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if companyUser.CompanyProfile != nil {
 		if err := u.ss.AddSkillToOwner(companyUser.CompanyProfile, skill); err != nil {
-			respondJSON(w, http.StatusInternalServerError, err.Error())
-			return
+			return err
 		}
 	}
-	respondJSON(w, http.StatusCreated, "skills updated successfully")
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
 }
 
 // PUT /user/id/company-profile/remove-skill
-func (u *Users) RemoveCompanyProfileSkill(w http.ResponseWriter, r *http.Request) {
+func (u *Users) RemoveCompanyProfileSkill(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, "Invalid user ID")
-		return
+		return err
 	}
 	skill := models.Skill{}
 	err = parseJSON(r, &skill)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if companyUser.CompanyProfile != nil {
 		if err := u.ss.DeleteSkillFromOwner(companyUser.CompanyProfile, skill); err != nil {
-			respondJSON(w, http.StatusInternalServerError, err.Error())
-			return
+			return err
 		}
 	}
 
-	respondJSON(w, http.StatusCreated, "skills updated successfully")
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
 }
 
 // PUT /user/id/company-profile/add-benefit
-func (u *Users) AddCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) {
+func (u *Users) AddCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	benefit := models.CompanyBenefit{}
 	err = parseJSON(r, &benefit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if companyUser.CompanyProfile != nil {
 		if err := u.us.AddCompanyProfileBenefit(companyUser.CompanyProfile, benefit); err != nil {
-			respondJSON(w, http.StatusInternalServerError, err.Error())
-			return
+			return err
 		}
 	}
 
-	respondJSON(w, http.StatusCreated, "benefit added successfully")
+	respondJSON(w, http.StatusOK, "benefit added successfully")
+	return nil
 }
 
 // PUT /user/id/company-profile/remove-benefit
-func (u *Users) RemoveCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) {
+func (u *Users) RemoveCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	benefit := models.CompanyBenefit{}
 	err = parseJSON(r, &benefit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if companyUser.CompanyProfile != nil {
 		if err := u.us.RemoveCompanyProfileBenefit(companyUser.CompanyProfile, benefit); err != nil {
-			respondJSON(w, http.StatusInternalServerError, err.Error())
-			return
+			return err
 		}
 	}
 
-	respondJSON(w, http.StatusCreated, "benefit removed successfully")
+	respondJSON(w, http.StatusOK, "benefit removed successfully")
+	return nil
 }
 
 // PUT /user/id/company-profile/update-benefit
-func (u *Users) UpdateCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) {
+func (u *Users) UpdateCompanyProfileBenefit(w http.ResponseWriter, r *http.Request) error {
 	companyUser, err := u.getUserByID(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, err)
-		return
+		return err
 	}
 	benefit := &models.CompanyBenefit{}
 	err = parseJSON(r, benefit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return badRequest(err)
 	}
 	if companyUser.CompanyProfile != nil {
 		benefit.CompanyProfileID = companyUser.CompanyProfile.ID
 		if err := u.us.UpdateCompanyProfileBenefit(benefit); err != nil {
-			respondJSON(w, http.StatusInternalServerError, err.Error())
-			return
+			return err
 		}
 	}
 
-	respondJSON(w, http.StatusCreated, "benefit updated successfully")
+	respondJSON(w, http.StatusOK, "benefit updated successfully")
+	return nil
 }
 
-func (u *Users) getUserByID(r *http.Request) (*models.User, error) {
-	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+// PUT /user/id/add-skill
+func (u *Users) AddSkill(w http.ResponseWriter, r *http.Request) error {
+	candidate, err := u.getCaller(r)
+	if err != nil {
+		return err
+	}
+	skill := models.Skill{}
+	err = parseJSON(r, &skill)
+	if err != nil {
+		return badRequest(err)
+	}
+	if err := u.ss.AddSkillToOwner(candidate, skill); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
+}
+
+// PUT /user/id/remove-skill
+func (u *Users) RemoveSkill(w http.ResponseWriter, r *http.Request) error {
+	candidate, err := u.getCaller(r)
 	if err != nil {
+		return err
+	}
+	skill := models.Skill{}
+	err = parseJSON(r, &skill)
+	if err != nil {
+		return badRequest(err)
+	}
+	if err := u.ss.DeleteSkillFromOwner(candidate, skill); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, "skills updated successfully")
+	return nil
+}
 
+// GET /user/id/recommendations
+//
+// Accepts an optional preferred location "l" and a "limit"
+// on the number of job posts returned.
+func (u *Users) Recommendations(w http.ResponseWriter, r *http.Request) error {
+	candidate, err := u.getCaller(r)
+	if err != nil {
+		return err
+	}
+	var locationID uint
+	if l, err := strconv.Atoi(r.URL.Query().Get("l")); err == nil {
+		locationID = uint(l)
+	}
+	limit := defaultRecommendationsLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= maxRecommendationsLimit {
+		limit = l
+	}
+
+	recommendations, err := u.js.Recommend(candidate, locationID, limit)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, recommendations)
+	return nil
+}
+
+// getCaller returns the user in the URL when they are the one
+// making the request, see callerIDParam.
+func (u *Users) getCaller(r *http.Request) (*models.User, error) {
+	id, err := callerIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	return u.us.ByID(id)
+}
+
+func (u *Users) getUserByID(r *http.Request) (*models.User, error) {
+	id, err := idParam(r, "id")
+	if err != nil {
 		return nil, err
 	}
-	companyUser, err := u.us.ByID(uint(id))
+	companyUser, err := u.us.ByID(id)
 	if err != nil {
 
 		return nil, err
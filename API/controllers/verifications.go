@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/email"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/verification"
+)
+
+// VerificationStatus is the response of GET
+// /companies/id/verification
+type VerificationStatus struct {
+	Verified           bool                    `json:"verified"`
+	VerifiedDomain     string                  `json:"verifiedDomain,omitempty"`
+	VerificationMethod string                  `json:"verificationMethod,omitempty"`
+	VerifiedAt         *time.Time              `json:"verifiedAt,omitempty"`
+	Challenge          *verification.Challenge `json:"challenge,omitempty"`
+}
+
+// Verifications lets the owners and admins of a company prove
+// the company owns the domain of its website.
+type Verifications struct {
+	us       models.UserService
+	cs       models.CompanyService
+	verifier *verification.Verifier
+	emailer  *email.Client
+}
+
+func NewVerifications(us models.UserService, cs models.CompanyService, verifier *verification.Verifier, emailer *email.Client) *Verifications {
+	return &Verifications{
+		us:       us,
+		cs:       cs,
+		verifier: verifier,
+		emailer:  emailer,
+	}
+}
+
+// GET /companies/id/verification
+//
+// Returns the DNS record and the file that verify the domain.
+func (v *Verifications) Show(w http.ResponseWriter, r *http.Request) error {
+	profile, err := v.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	challenge, err := v.verifier.Challenge(*profile)
+	if err != nil {
+		return verificationError(err)
+	}
+	respondJSON(w, http.StatusOK, VerificationStatus{
+		Verified:           profile.Verified,
+		VerifiedDomain:     profile.VerifiedDomain,
+		VerificationMethod: profile.VerificationMethod,
+		VerifiedAt:         profile.VerifiedAt,
+		Challenge:          challenge,
+	})
+	return nil
+}
+
+// POST /companies/id/verification/dns
+func (v *Verifications) CheckDNS(w http.ResponseWriter, r *http.Request) error {
+	profile, err := v.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	domain, err := v.verifier.CheckDNS(*profile)
+	if err != nil {
+		return verificationError(err)
+	}
+	return v.verify(w, profile, domain, verification.MethodDNS)
+}
+
+// POST /companies/id/verification/file
+func (v *Verifications) CheckFile(w http.ResponseWriter, r *http.Request) error {
+	profile, err := v.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	domain, err := v.verifier.CheckFile(*profile)
+	if err != nil {
+		return verificationError(err)
+	}
+	return v.verify(w, profile, domain, verification.MethodFile)
+}
+
+// VerificationEmailForm is the payload of SendEmail and
+// ConfirmEmail.
+type VerificationEmailForm struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// POST /companies/id/verification/email
+//
+// Emails a code to the work address provided, which must be on
+// the domain of the website.
+func (v *Verifications) SendEmail(w http.ResponseWriter, r *http.Request) error {
+	profile, err := v.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	form := VerificationEmailForm{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	code, err := v.verifier.EmailCode(*profile, form.Email, time.Now())
+	if err != nil {
+		return verificationError(err)
+	}
+	domain, _ := verification.Domain(profile.Website)
+	if err := v.emailer.DomainVerification(form.Email, domain, code); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusAccepted, "verification code sent")
+	return nil
+}
+
+// POST /companies/id/verification/email/confirm
+func (v *Verifications) ConfirmEmail(w http.ResponseWriter, r *http.Request) error {
+	profile, err := v.getCompanyProfile(r)
+	if err != nil {
+		return err
+	}
+	form := VerificationEmailForm{}
+	if err := parseJSON(r, &form); err != nil {
+		return badRequest(err)
+	}
+	domain, err := v.verifier.CheckEmailCode(*profile, form.Email, form.Code, time.Now())
+	if err != nil {
+		return verificationError(err)
+	}
+	return v.verify(w, profile, domain, verification.MethodEmail)
+}
+
+// verify records the verification once a check succeeded
+func (v *Verifications) verify(w http.ResponseWriter, profile *models.CompanyProfile, domain, method string) error {
+	now := time.Now()
+	profile.VerifiedDomain = domain
+	profile.VerificationMethod = method
+	profile.VerifiedAt = &now
+	if err := v.us.VerifyCompanyDomain(profile); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, VerificationStatus{
+		Verified:           profile.Verified,
+		VerifiedDomain:     profile.VerifiedDomain,
+		VerificationMethod: profile.VerificationMethod,
+		VerifiedAt:         profile.VerifiedAt,
+	})
+	return nil
+}
+
+// verificationError gives the failed checks a 422, the other
+// errors come from the DNS or the website of the company.
+func verificationError(err error) error {
+	switch err {
+	case verification.ErrWebsiteInvalid, verification.ErrChallengeFailed,
+		verification.ErrEmailDomain, verification.ErrEmailCodeInvalid:
+		return withStatus(http.StatusUnprocessableEntity, err)
+	}
+	return withStatus(http.StatusBadGateway, err)
+}
+
+// getCompanyProfile returns the profile of the company from the
+// URL, as long as the caller can manage the company.
+func (v *Verifications) getCompanyProfile(r *http.Request) (*models.CompanyProfile, error) {
+	companyID, err := idParam(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	_, err = v.cs.Authorize(companyID, llctx.User(r.Context()).ID, models.MemberRole.CanManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	return v.us.CompanyProfileByCompanyID(companyID)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+var errIfMatchMissing = errors.New("If-Match header is required, send the ETag of the resource being updated")
+
+// versionETag is the entity tag of a job post or company
+// profile at version.
+func versionETag(version uint) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// jobPostETag is the entity tag of a job post as served with
+// its company profile and the associations in include. It
+// changes with the version of both and with the associations,
+// so representations with different bodies never share it.
+func jobPostETag(jobPost *models.JobPost, include []string) string {
+	tag := fmt.Sprintf("v%d", jobPost.Version)
+	if jobPost.Company != nil {
+		tag += fmt.Sprintf("-c%d.%d", jobPost.Company.ID, jobPost.Company.Version)
+	}
+	names := map[string]bool{}
+	for _, name := range include {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		tag += "+" + name
+	}
+	return `"` + tag + `"`
+}
+
+// checkIfMatch makes sure the caller updates the version it
+// read. Updates without If-Match respond with 428, so they can
+// not overwrite changes the caller never saw, and updates of
+// another version with 412.
+func checkIfMatch(r *http.Request, version uint) error {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		return withStatus(http.StatusPreconditionRequired, errIfMatchMissing)
+	}
+	etag := versionETag(version)
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return nil
+		}
+	}
+	return models.ErrVersionConflict
+}
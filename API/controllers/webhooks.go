@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/webhooks"
+)
+
+const webhookDeliveriesLimit = 50
+
+type Webhooks struct {
+	ws         models.WebhookService
+	dispatcher *webhooks.Dispatcher
+}
+
+func NewWebhooks(ws models.WebhookService, dispatcher *webhooks.Dispatcher) *Webhooks {
+	return &Webhooks{
+		ws:         ws,
+		dispatcher: dispatcher,
+	}
+}
+
+// GET /user/id/webhooks
+func (wc *Webhooks) List(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	hooks, err := wc.ws.ByUserID(userID)
+	if err != nil {
+		return err
+	}
+	// Secrets are only shown when the webhook is created
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	respondJSON(w, http.StatusOK, hooks)
+	return nil
+}
+
+// POST /user/id/webhooks
+//
+// Events is a comma separated list of event types, every event
+// is sent when it is empty. A secret is generated when none
+// is provided.
+func (wc *Webhooks) Create(w http.ResponseWriter, r *http.Request) error {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return err
+	}
+	webhook := models.Webhook{}
+	err = parseJSON(r, &webhook)
+	if err != nil {
+		return badRequest(err)
+	}
+	webhook.ID = 0
+	webhook.UserID = userID
+	if err := wc.ws.Create(&webhook); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusCreated, webhook)
+	return nil
+}
+
+// DELETE /user/id/webhooks/hookId
+func (wc *Webhooks) Delete(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := wc.getWebhook(r)
+	if err != nil {
+		return err
+	}
+	if err := wc.ws.Delete(webhook.ID); err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, fmt.Sprintf("Removed webhook with ID %v", webhook.ID))
+	return nil
+}
+
+// GET /user/id/webhooks/hookId/deliveries
+func (wc *Webhooks) Deliveries(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := wc.getWebhook(r)
+	if err != nil {
+		return err
+	}
+	deliveries, err := wc.ws.Deliveries(webhook.ID, webhookDeliveriesLimit)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, deliveries)
+	return nil
+}
+
+// POST /user/id/webhooks/hookId/test
+func (wc *Webhooks) SendTest(w http.ResponseWriter, r *http.Request) error {
+	webhook, err := wc.getWebhook(r)
+	if err != nil {
+		return err
+	}
+	delivery, err := wc.dispatcher.SendTest(*webhook)
+	if err != nil {
+		return err
+	}
+	respondJSON(w, http.StatusOK, delivery)
+	return nil
+}
+
+// getWebhook returns the webhook from the URL, making sure it
+// belongs to the user making the request.
+func (wc *Webhooks) getWebhook(r *http.Request) (*models.Webhook, error) {
+	userID, err := callerIDParam(r)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idParam(r, "hookId")
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := wc.ws.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.UserID != userID {
+		return nil, models.ErrNotFound
+	}
+	return webhook, nil
+}
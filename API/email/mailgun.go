@@ -3,16 +3,27 @@ package email
 import (
 	"context"
 	"fmt"
+	"html"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/mailgun/mailgun-go/v3"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
 )
 
 const (
-	welcomeSubject = "Welcome to Lenslocked Project Demo"
-	resetSubject   = "Instructions for resetting your password."
-	resetBaseURL   = "https://lenslocked-project-demo.net/reset"
+	welcomeSubject     = "Welcome to Lenslocked Project Demo"
+	resetSubject       = "Instructions for resetting your password."
+	resetBaseURL       = "https://lenslocked-project-demo.net/reset"
+	jobAlertSubject    = "New jobs matching %q"
+	jobBaseURL         = "https://lenslocked-project-demo.net/jobs/%d"
+	unsubscribeBaseURL = "https://lenslocked-project-demo.net/saved-searches/%d/unsubscribe"
+	invitationSubject  = "You have been invited to join %s"
+	invitationBaseURL  = "https://lenslocked-project-demo.net/invitations/accept"
+	verifySubject      = "Verify %s on Lenslocked"
+	deletionSubject    = "Your Lenslocked account will be deleted"
+	accountBaseURL     = "https://lenslocked-project-demo.net/account"
 )
 const welcomeText = `
 Hi there!
@@ -73,6 +84,137 @@ const resetHTMLTmpl = `
 	Lenslocked Support<br/>
 `
 
+const jobAlertTextTmpl = `
+	Hi there!
+
+	New jobs matching your saved search %q were just published:
+
+%s
+	Best,
+
+	Lenslocked Support
+
+	To stop receiving these emails, follow the link below:
+	%s
+`
+
+const jobAlertHTMLTmpl = `
+	Hi there!<br/>
+	<br/>
+	New jobs matching your saved search "%s" were just published:
+	<br/>
+	<ul>
+%s
+	</ul>
+	<br/>
+	Best,<br/>
+
+	Lenslocked Support<br/>
+	<br/>
+	<a href="%s">Unsubscribe from this alert</a>
+`
+
+const invitationTextTmpl = `
+	Hi there!
+
+	You have been invited to join %s as %s. To accept the
+	invitation, sign in with this email address and follow the
+	link below:
+
+	%s
+
+	The invitation expires on %s.
+
+	Best,
+
+	Lenslocked Support
+`
+
+const invitationHTMLTmpl = `
+	Hi there!<br/>
+	<br/>
+	You have been invited to join %s as %s. To accept the
+	invitation, sign in with this email address and follow the
+	link below:
+	<br/>
+	<a href="%s">%s</a>
+	<br/>
+	<br/>
+	The invitation expires on %s.
+	<br/>
+	Best,<br/>
+
+	Lenslocked Support<br/>
+`
+
+const verifyTextTmpl = `
+	Hi there!
+
+	Someone asked to verify that %s belongs to their company. If
+	this was you, please use the following code:
+
+	%s
+
+	The code expires in 24 hours. If you did not ask for it you
+	can safely ignore this email.
+
+	Best,
+
+	Lenslocked Support
+`
+
+const verifyHTMLTmpl = `
+	Hi there!<br/>
+	<br/>
+	Someone asked to verify that %s belongs to their company. If
+	this was you, please use the following code:
+	<br/>
+	<code>%s</code>
+	<br/>
+	<br/>
+	The code expires in 24 hours. If you did not ask for it you
+	can safely ignore this email.
+	<br/>
+	Best,<br/>
+
+	Lenslocked Support<br/>
+`
+
+const deletionTextTmpl = `
+	Hi there!
+
+	As you asked, your account and its personal data will be
+	deleted on %s. Until then you can download a copy of your
+	data or cancel the deletion from your account:
+
+	%s
+
+	If you did not ask for it, sign in and cancel the deletion
+	right away.
+
+	Best,
+
+	Lenslocked Support
+`
+
+const deletionHTMLTmpl = `
+	Hi there!<br/>
+	<br/>
+	As you asked, your account and its personal data will be
+	deleted on %s. Until then you can download a copy of your
+	data or cancel the deletion from your account:
+	<br/>
+	<a href="%s">%s</a>
+	<br/>
+	<br/>
+	If you did not ask for it, sign in and cancel the deletion
+	right away.
+	<br/>
+	Best,<br/>
+
+	Lenslocked Support<br/>
+`
+
 type ClientConfig func(*Client)
 
 func WithMailgun(domain, apiKey string) ClientConfig {
@@ -130,6 +272,87 @@ func (c *Client) ResetPw(toEmail, token string) error {
 	return err
 }
 
+// JobAlert emails the job posts matching a saved search, along
+// with a link to unsubscribe signed with the provided token.
+func (c *Client) JobAlert(toEmail string, search models.SavedSearch, jobPosts []models.JobPost, unsubscribeToken string) error {
+	v := url.Values{}
+	v.Set("token", unsubscribeToken)
+	unsubscribeURL := fmt.Sprintf(unsubscribeBaseURL, search.ID) + "?" + v.Encode()
+
+	var textList, htmlList strings.Builder
+	for _, jp := range jobPosts {
+		jobURL := fmt.Sprintf(jobBaseURL, jp.ID)
+		fmt.Fprintf(&textList, "\t- %s: %s\n", jp.Title, jobURL)
+		fmt.Fprintf(&htmlList, "\t\t<li><a href=\"%s\">%s</a></li>\n", jobURL, html.EscapeString(jp.Title))
+	}
+
+	subject := fmt.Sprintf(jobAlertSubject, search.Name)
+	alertText := fmt.Sprintf(jobAlertTextTmpl, search.Name, textList.String(), unsubscribeURL)
+	message := c.mg.NewMessage(c.from, subject, alertText, toEmail)
+	alertHTML := fmt.Sprintf(jobAlertHTMLTmpl, html.EscapeString(search.Name), htmlList.String(), html.EscapeString(unsubscribeURL))
+	message.SetHtml(alertHTML)
+	message.AddHeader("List-Unsubscribe", "<"+unsubscribeURL+">")
+	message.AddHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	_, _, err := c.mg.Send(ctx, message)
+
+	return err
+}
+
+// Invitation emails the link accepting an invitation to join
+// the company.
+func (c *Client) Invitation(companyName string, invitation models.CompanyInvitation) error {
+	v := url.Values{}
+	v.Set("token", invitation.Token)
+	acceptURL := invitationBaseURL + "?" + v.Encode()
+	expires := invitation.ExpiresAt.Format("January 2, 2006")
+
+	subject := fmt.Sprintf(invitationSubject, companyName)
+	invitationText := fmt.Sprintf(invitationTextTmpl, companyName, invitation.Role, acceptURL, expires)
+	message := c.mg.NewMessage(c.from, subject, invitationText, invitation.Email)
+	invitationHTML := fmt.Sprintf(invitationHTMLTmpl, html.EscapeString(companyName), invitation.Role,
+		html.EscapeString(acceptURL), html.EscapeString(acceptURL), expires)
+	message.SetHtml(invitationHTML)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	_, _, err := c.mg.Send(ctx, message)
+
+	return err
+}
+
+// DomainVerification emails the code verifying that the domain
+// belongs to the company of the recipient.
+func (c *Client) DomainVerification(toEmail, domain, code string) error {
+	subject := fmt.Sprintf(verifySubject, domain)
+	verifyText := fmt.Sprintf(verifyTextTmpl, domain, code)
+	message := c.mg.NewMessage(c.from, subject, verifyText, toEmail)
+	message.SetHtml(fmt.Sprintf(verifyHTMLTmpl, html.EscapeString(domain), html.EscapeString(code)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	_, _, err := c.mg.Send(ctx, message)
+
+	return err
+}
+
+// AccountDeletion confirms that the account of the recipient
+// will be deleted at the time provided.
+func (c *Client) AccountDeletion(toEmail string, at time.Time) error {
+	date := at.Format("January 2, 2006")
+	deletionText := fmt.Sprintf(deletionTextTmpl, date, accountBaseURL)
+	message := c.mg.NewMessage(c.from, deletionSubject, deletionText, toEmail)
+	message.SetHtml(fmt.Sprintf(deletionHTMLTmpl, date, accountBaseURL, accountBaseURL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	_, _, err := c.mg.Send(ctx, message)
+
+	return err
+}
+
 func buildEmail(name, email string) string {
 	if name == "" {
 		return email
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"log"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const announceInterval = time.Minute
+
+// NewAnnouncer creates an Announcer, Run must be called for the
+// scheduled job posts to be announced.
+func NewAnnouncer(js models.JobPostService) *Announcer {
+	return &Announcer{js: js}
+}
+
+// Announcer records the JobPostPublished event of the job posts
+// scheduled for later once their publication date is reached,
+// so the subscribers only hear of job posts that are listed.
+type Announcer struct {
+	js models.JobPostService
+}
+
+// Run announces the due job posts every minute until stop is
+// closed
+func (a *Announcer) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if _, err := a.js.AnnounceDue(now); err != nil {
+				log.Printf("events: could not announce scheduled job posts: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
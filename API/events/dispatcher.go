@@ -0,0 +1,113 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	pollBatch    = 100
+)
+
+// Handler reacts to a domain event. Handlers must be idempotent,
+// an event is delivered again to every handler of its type when
+// any of them fails.
+type Handler func(event models.DomainEvent) error
+
+// NewDispatcher creates a Dispatcher, Run must be called for the
+// recorded events to be relayed.
+func NewDispatcher(es models.EventService) *Dispatcher {
+	return &Dispatcher{
+		es:       es,
+		handlers: map[models.EventType][]Handler{},
+	}
+}
+
+// Dispatcher relays the events recorded by the services to the
+// in-process subscribers. An event is only marked as dispatched
+// once every handler succeeded, so handlers see each event at
+// least once.
+type Dispatcher struct {
+	es       models.EventService
+	mu       sync.RWMutex
+	handlers map[models.EventType][]Handler
+}
+
+// Subscribe registers the handler for the provided event types
+func (d *Dispatcher) Subscribe(handler Handler, types ...models.EventType) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range types {
+		d.handlers[t] = append(d.handlers[t], handler)
+	}
+}
+
+// DispatchPending relays the events not dispatched yet, oldest
+// first. Failed events are retried on the next call.
+func (d *Dispatcher) DispatchPending() error {
+	pending, err := d.es.Pending(pollBatch)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		event := &pending[i]
+		if err := d.dispatch(*event); err != nil {
+			log.Printf("events: could not dispatch event %d: %v", event.ID, err)
+			if err := d.es.MarkFailed(event, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.es.MarkDispatched(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run polls for new events until stop is closed
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := d.DispatchPending(); err != nil {
+				log.Printf("events: could not dispatch events: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// dispatch calls every handler of the event, the first error is
+// returned once all of them ran.
+func (d *Dispatcher) dispatch(event models.DomainEvent) (err error) {
+	d.mu.RLock()
+	handlers := d.handlers[event.Type]
+	d.mu.RUnlock()
+
+	for _, handle := range handlers {
+		if hErr := safeHandle(handle, event); hErr != nil && err == nil {
+			err = hErr
+		}
+	}
+	return err
+}
+
+// safeHandle turns a panicking handler into an error so it does
+// not stop the dispatcher.
+func safeHandle(handle Handler, event models.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("events: handler panicked: %v", r)
+		}
+	}()
+	return handle(event)
+}
@@ -0,0 +1,157 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	// Registers the WebP decoder with image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxUploadSize is the size of the largest image accepted
+	MaxUploadSize = 5 << 20
+	// maxPixels guards against images small on disk but huge
+	// once decoded.
+	maxPixels = 25000000
+	// minSide is the size of the smallest logo accepted
+	minSide = 32
+
+	jpegQuality = 85
+)
+
+var (
+	ErrFormatUnsupported = errors.New("imaging: image must be a PNG, JPEG or WebP")
+	ErrImageTooLarge     = errors.New("imaging: image is too large")
+	ErrImageTooSmall     = errors.New("imaging: image must be at least 32x32 pixels")
+)
+
+// formats are the formats accepted, as named by image.Decode
+var formats = map[string]bool{
+	"png":  true,
+	"jpeg": true,
+	"webp": true,
+}
+
+// Variant names
+const (
+	VariantOriginal  = "original"
+	VariantThumbnail = "thumbnail"
+	VariantHeader    = "header"
+)
+
+// variantSpec sizes a variant. Square variants are cropped
+// around the center, the others are fit within the bounds.
+// Images are never enlarged.
+type variantSpec struct {
+	name          string
+	width, height int
+	square        bool
+}
+
+var logoVariants = []variantSpec{
+	{name: VariantOriginal, width: 1024, height: 1024},
+	{name: VariantThumbnail, width: 256, height: 256, square: true},
+	{name: VariantHeader, width: 1200, height: 400},
+}
+
+// Variant is an encoded size of an image
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	// Extension is the file extension of the format, without
+	// the dot.
+	Extension string
+	Content   []byte
+}
+
+// ProcessLogo decodes the logo and encodes each of its variants.
+// Re-encoding the pixels drops the metadata of the upload, such
+// as EXIF data. Opaque logos are encoded as JPEG, the others as
+// PNG to keep their transparency.
+func ProcessLogo(data []byte) ([]Variant, error) {
+	if len(data) > MaxUploadSize {
+		return nil, ErrImageTooLarge
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !formats[format] {
+		return nil, ErrFormatUnsupported
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	if cfg.Width < minSide || cfg.Height < minSide {
+		return nil, ErrImageTooSmall
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrFormatUnsupported
+	}
+
+	var variants []Variant
+	for _, spec := range logoVariants {
+		img := resize(src, spec)
+		variant := Variant{
+			Name:   spec.name,
+			Width:  img.Bounds().Dx(),
+			Height: img.Bounds().Dy(),
+		}
+		var buf bytes.Buffer
+		if img.Opaque() {
+			variant.ContentType, variant.Extension = "image/jpeg", "jpg"
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			variant.ContentType, variant.Extension = "image/png", "png"
+			err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+		}
+		if err != nil {
+			return nil, err
+		}
+		variant.Content = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func resize(src image.Image, spec variantSpec) *image.NRGBA {
+	bounds := src.Bounds()
+	if spec.square {
+		side := bounds.Dx()
+		if bounds.Dy() < side {
+			side = bounds.Dy()
+		}
+		x := bounds.Min.X + (bounds.Dx()-side)/2
+		y := bounds.Min.Y + (bounds.Dy()-side)/2
+		bounds = image.Rect(x, y, x+side, y+side)
+	}
+	width, height := fit(bounds.Dx(), bounds.Dy(), spec.width, spec.height)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// fit returns the size of a width x height image scaled down to
+// fit within maxWidth x maxHeight, keeping its aspect ratio.
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	if width*maxHeight > height*maxWidth {
+		h := height * maxWidth / width
+		if h < 1 {
+			h = 1
+		}
+		return maxWidth, h
+	}
+	w := width * maxHeight / height
+	if w < 1 {
+		w = 1
+	}
+	return w, maxHeight
+}
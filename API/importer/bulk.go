@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// progressInterval is the number of rows processed between two
+// saves of the progress of an import.
+const progressInterval = 50
+
+// importColumns are the columns of the job post fields whose
+// name differs in the imports.
+var importColumns = map[string]string{
+	"categoryId": "category",
+	"locationId": "location",
+}
+
+// NewBulk creates a Bulk importer
+func NewBulk(ijs models.ImportJobService, js models.JobPostService, ss models.SkillsService, cs models.CategoryService, ls models.LocationService) *Bulk {
+	return &Bulk{
+		ijs: ijs,
+		js:  js,
+		ss:  ss,
+		cs:  cs,
+		ls:  ls,
+	}
+}
+
+// Bulk creates the job posts of an import for a company,
+// resolving the names of the catalog entries to their IDs.
+type Bulk struct {
+	ijs models.ImportJobService
+	js  models.JobPostService
+	ss  models.SkillsService
+	cs  models.CategoryService
+	ls  models.LocationService
+}
+
+// Start records the import job and runs it in the background,
+// rowErrors are the rows that could not be parsed.
+func (b *Bulk) Start(job *models.ImportJob, rows []Row, rowErrors []models.ImportRowError) error {
+	job.Status = models.ImportPending
+	job.Total = len(rows) + len(rowErrors)
+	job.Failed = len(rowErrors)
+	job.Errors = rowErrors
+	if err := b.ijs.Create(job); err != nil {
+		return err
+	}
+	// The background run works on its own copy of the job
+	running := *job
+	go b.Run(&running, rows)
+	return nil
+}
+
+// Run imports the rows and records the outcome of every row in
+// the job. On a dry run, Imported is the number of valid rows.
+func (b *Bulk) Run(job *models.ImportJob, rows []Row) {
+	job.Status = models.ImportRunning
+	b.save(job)
+
+	catalog, err := loadCatalog(b.cs, b.ls)
+	if err != nil {
+		job.Status = models.ImportFailed
+		job.Error = err.Error()
+		b.save(job)
+		return
+	}
+
+	for i, row := range rows {
+		if rowErrors := b.importRow(job, row, catalog); len(rowErrors) > 0 {
+			job.Failed++
+			job.Errors = append(job.Errors, rowErrors...)
+		} else {
+			job.Imported++
+		}
+		if (i+1)%progressInterval == 0 {
+			b.save(job)
+		}
+	}
+	job.Status = models.ImportCompleted
+	b.save(job)
+}
+
+func (b *Bulk) importRow(job *models.ImportJob, row Row, catalog *catalog) []models.ImportRowError {
+	var rowErrors []models.ImportRowError
+	rowError := func(field, message string) {
+		rowErrors = append(rowErrors, models.ImportRowError{
+			Row:     row.Number,
+			Field:   field,
+			Message: message,
+		})
+	}
+
+	jobPost := models.JobPost{
+		UserID:      job.UserID,
+		CompanyID:   job.CompanyID,
+		Title:       row.Title,
+		Description: row.Description,
+		ApplyAt:     row.ApplyAt,
+	}
+	if row.Category != "" {
+		if jobPost.CategoryID = catalog.categories.lookup(row.Category); jobPost.CategoryID == 0 {
+			rowError("category", fmt.Sprintf("unknown category %q", row.Category))
+		}
+	}
+	if row.Location != "" {
+		if jobPost.LocationID = catalog.locations.lookup(row.Location); jobPost.LocationID == 0 {
+			rowError("location", fmt.Sprintf("unknown location %q", row.Location))
+		}
+	}
+	var skills []models.Skill
+	for _, name := range row.Skills {
+		skill, err := b.ss.ByName(name)
+		if err != nil {
+			rowError("skills", fmt.Sprintf("unknown skill %q", name))
+			continue
+		}
+		skills = append(skills, *skill)
+	}
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	if err := b.js.Validate(&jobPost); err != nil {
+		validationErrors(err, rowError)
+		return rowErrors
+	}
+	if job.DryRun {
+		return nil
+	}
+	// The skills are attached along with the job post, so a row
+	// is either imported entirely or not at all.
+	jobPost.Skills = skills
+	jobPost.EditedBy = job.UserID
+	if err := b.js.Create(&jobPost); err != nil {
+		validationErrors(err, rowError)
+		return rowErrors
+	}
+	return rowErrors
+}
+
+// validationErrors reports every field error of a validation
+// error under its column, other errors are not about a field.
+func validationErrors(err error, rowError func(field, message string)) {
+	verr, ok := err.(*models.ValidationError)
+	if !ok {
+		rowError("", err.Error())
+		return
+	}
+	for _, fe := range verr.Fields() {
+		field := fe.Field
+		if column, ok := importColumns[field]; ok {
+			field = column
+		}
+		rowError(field, fe.Message)
+	}
+}
+
+func (b *Bulk) save(job *models.ImportJob) {
+	if err := b.ijs.Update(job); err != nil {
+		log.Printf("importer: could not save import job %d: %v", job.ID, err)
+	}
+}
+
+// catalog holds the IDs of the categories and locations by
+// name, loaded once per import.
+type catalog struct {
+	categories names
+	locations  names
+}
+
+// names maps lowercase names, and IDs, to IDs
+type names map[string]uint
+
+func (n names) add(id uint, name string) {
+	n[strings.ToLower(strings.TrimSpace(name))] = id
+	n[strconv.Itoa(int(id))] = id
+}
+
+func (n names) lookup(name string) uint {
+	return n[strings.ToLower(strings.TrimSpace(name))]
+}
+
+func loadCatalog(cs models.CategoryService, ls models.LocationService) (*catalog, error) {
+	c := catalog{
+		categories: names{},
+		locations:  names{},
+	}
+	categories, err := cs.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		c.categories.add(category.ID, category.CategoryName)
+	}
+	locations, err := ls.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, location := range locations {
+		c.locations.add(location.ID, location.LocationName)
+	}
+	return &c, nil
+}
@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/publicnet"
+)
+
+const (
+	feedSyncInterval = time.Hour
+	feedFetchTimeout = 30 * time.Second
+)
+
+// feedDateLayouts are the date formats found in job feeds
+var feedDateLayouts = []string{
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC3339,
+	"2006-01-02",
+}
+
+// FeedEntry is a job of an XML job feed
+type FeedEntry struct {
+	Ref         string
+	Title       string
+	Description string
+	ApplyAt     string
+	Category    string
+	// Locations are the city, state and country of the job,
+	// the first one found in the catalog is used.
+	Locations   []string
+	PublishedAt *time.Time
+}
+
+// xmlJob is a job of an Indeed style XML feed, where every job
+// is a <job> element of the root element.
+type xmlJob struct {
+	ReferenceNumber string `xml:"referencenumber"`
+	Title           string `xml:"title"`
+	Description     string `xml:"description"`
+	URL             string `xml:"url"`
+	Email           string `xml:"email"`
+	Date            string `xml:"date"`
+	Category        string `xml:"category"`
+	City            string `xml:"city"`
+	State           string `xml:"state"`
+	Country         string `xml:"country"`
+}
+
+// ParseFeed reads the entries of an XML job feed
+func ParseFeed(r io.Reader) ([]FeedEntry, error) {
+	var feed struct {
+		Jobs []xmlJob `xml:"job"`
+	}
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, err
+	}
+
+	entries := make([]FeedEntry, 0, len(feed.Jobs))
+	for _, job := range feed.Jobs {
+		entry := FeedEntry{
+			Ref:         strings.TrimSpace(job.ReferenceNumber),
+			Title:       strings.TrimSpace(job.Title),
+			Description: strings.TrimSpace(job.Description),
+			ApplyAt:     strings.TrimSpace(job.URL),
+			Category:    strings.TrimSpace(job.Category),
+			PublishedAt: parseFeedDate(job.Date),
+		}
+		if entry.ApplyAt == "" {
+			entry.ApplyAt = strings.TrimSpace(job.Email)
+		}
+		for _, location := range []string{job.City, job.State, job.Country} {
+			if location = strings.TrimSpace(location); location != "" {
+				entry.Locations = append(entry.Locations, location)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseFeedDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// Fetcher returns the content of a feed URL
+type Fetcher interface {
+	Fetch(url string) (io.ReadCloser, error)
+}
+
+// FetcherFunc is a function used as a Fetcher
+type FetcherFunc func(url string) (io.ReadCloser, error)
+
+func (fn FetcherFunc) Fetch(url string) (io.ReadCloser, error) {
+	return fn(url)
+}
+
+// HTTPFetcher fetches feeds over https
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+// NewHTTPFetcher creates an HTTPFetcher with a timeout, whose
+// client only reaches public hosts over https, redirects
+// included.
+func NewHTTPFetcher() *HTTPFetcher {
+	return &HTTPFetcher{
+		Client: publicnet.NewClient(feedFetchTimeout),
+	}
+}
+
+func (hf *HTTPFetcher) Fetch(rawURL string) (io.ReadCloser, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("importer: feed url %s is not https", rawURL)
+	}
+	res, err := hf.Client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("importer: feed responded with status %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+// NewFeedSyncer creates a FeedSyncer, Run must be called for the
+// feeds to be synced periodically.
+func NewFeedSyncer(fs models.JobFeedService, js models.JobPostService, cs models.CategoryService, ls models.LocationService, fetcher Fetcher) *FeedSyncer {
+	return &FeedSyncer{
+		fs:      fs,
+		js:      js,
+		cs:      cs,
+		ls:      ls,
+		fetcher: fetcher,
+	}
+}
+
+// FeedSyncer keeps the job posts imported from the job feeds in
+// sync with the feeds. Entries are identified by their reference
+// number: new entries are created, changed ones are updated and
+// the job posts of the entries gone from the feed are closed,
+// to be reopened if the entries come back. The job posts their
+// owner deleted are left alone.
+type FeedSyncer struct {
+	fs      models.JobFeedService
+	js      models.JobPostService
+	cs      models.CategoryService
+	ls      models.LocationService
+	fetcher Fetcher
+}
+
+// Run syncs every feed until stop is closed
+func (s *FeedSyncer) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(feedSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := s.SyncAll(now); err != nil {
+				log.Printf("importer: could not sync feeds: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// SyncAll syncs every feed, a feed failing to sync does not
+// stop the others from being synced.
+func (s *FeedSyncer) SyncAll(now time.Time) error {
+	feeds, err := s.fs.FindAll()
+	if err != nil {
+		return err
+	}
+	for i := range feeds {
+		if err := s.Sync(&feeds[i], now); err != nil {
+			log.Printf("importer: could not sync feed %d: %v", feeds[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Sync syncs the feed and records the outcome in the feed
+func (s *FeedSyncer) Sync(feed *models.JobFeed, now time.Time) error {
+	err := s.sync(feed)
+	feed.LastSyncedAt = &now
+	feed.LastError = ""
+	if err != nil {
+		feed.LastError = err.Error()
+	}
+	if uErr := s.fs.Update(feed); uErr != nil && err == nil {
+		err = uErr
+	}
+	return err
+}
+
+func (s *FeedSyncer) sync(feed *models.JobFeed) error {
+	body, err := s.fetcher.Fetch(feed.URL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	entries, err := ParseFeed(body)
+	if err != nil {
+		return err
+	}
+	catalog, err := loadCatalog(s.cs, s.ls)
+	if err != nil {
+		return err
+	}
+	found, err := s.js.ByJobFeedID(feed.ID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]models.JobPost, len(found))
+	for _, jp := range found {
+		existing[jp.ExternalRef] = jp
+	}
+
+	feed.LastCreated, feed.LastUpdated, feed.LastClosed, feed.LastRejected = 0, 0, 0, 0
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Ref == "" || seen[entry.Ref] {
+			feed.LastRejected++
+			continue
+		}
+		seen[entry.Ref] = true
+
+		jobPost, ok := existing[entry.Ref]
+		if ok && jobPost.DeletedAt != nil {
+			continue
+		}
+		if !ok {
+			jobPost = models.JobPost{
+				UserID:      feed.UserID,
+				JobFeedID:   feed.ID,
+				ExternalRef: entry.Ref,
+				PublishedAt: entry.PublishedAt,
+			}
+		}
+		jobPost.EditedBy = feed.UserID
+		changed := applyFeedEntry(&jobPost, entry, feed, catalog)
+		if jobPost.ClosedAt != nil {
+			// The entry is back in the feed
+			jobPost.ClosedAt = nil
+			changed = true
+		}
+		switch {
+		case !ok:
+			if err := s.js.Create(&jobPost); err != nil {
+				log.Printf("importer: feed %d entry %q rejected: %v", feed.ID, entry.Ref, err)
+				feed.LastRejected++
+				continue
+			}
+			feed.LastCreated++
+		case changed:
+			if err := s.js.Update(&jobPost); err != nil {
+				log.Printf("importer: feed %d entry %q rejected: %v", feed.ID, entry.Ref, err)
+				feed.LastRejected++
+				continue
+			}
+			feed.LastUpdated++
+		}
+	}
+
+	for ref, jobPost := range existing {
+		if seen[ref] || jobPost.ClosedAt != nil || jobPost.DeletedAt != nil {
+			continue
+		}
+		if err := s.js.Close(jobPost.ID); err != nil {
+			return err
+		}
+		feed.LastClosed++
+	}
+	return nil
+}
+
+// applyFeedEntry copies the entry onto the job post and reports
+// whether the job post changed.
+func applyFeedEntry(jobPost *models.JobPost, entry FeedEntry, feed *models.JobFeed, catalog *catalog) bool {
+	categoryID := catalog.categories.lookup(entry.Category)
+	if categoryID == 0 {
+		categoryID = feed.CategoryID
+	}
+	locationID := feed.LocationID
+	for _, location := range entry.Locations {
+		if id := catalog.locations.lookup(location); id != 0 {
+			locationID = id
+			break
+		}
+	}
+
+	changed := jobPost.Title != entry.Title ||
+		jobPost.Description != entry.Description ||
+		jobPost.ApplyAt != entry.ApplyAt ||
+		jobPost.CategoryID != categoryID ||
+		jobPost.LocationID != locationID
+	jobPost.Title = entry.Title
+	jobPost.Description = entry.Description
+	jobPost.ApplyAt = entry.ApplyAt
+	jobPost.CategoryID = categoryID
+	jobPost.LocationID = locationID
+	return changed
+}
@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	FormatCSV        = "csv"
+	FormatJSONLines  = "jsonl"
+	maxJSONLineBytes = 1 << 20
+)
+
+// ErrFormatUnknown is returned for formats other than
+// FormatCSV and FormatJSONLines.
+var ErrFormatUnknown = errors.New("importer: format must be csv or jsonl")
+
+// Row is a job post read from an import. The category, location
+// and skills are referred to by name, or by ID for the first two.
+type Row struct {
+	Number      int      `json:"-"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ApplyAt     string   `json:"applyAt"`
+	Category    string   `json:"category"`
+	Location    string   `json:"location"`
+	Skills      []string `json:"skills"`
+}
+
+// Parse reads the rows of an import in the provided format.
+// Rows that can not be read are returned as row errors, the
+// error is only set when the import can not be read at all.
+func Parse(format string, r io.Reader) ([]Row, []models.ImportRowError, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatJSONLines:
+		return ParseJSONLines(r)
+	}
+	return nil, nil, ErrFormatUnknown
+}
+
+// csvColumns maps the accepted CSV headers to the Row fields
+var csvColumns = map[string]string{
+	"title":       "title",
+	"description": "description",
+	"applyat":     "applyAt",
+	"apply_at":    "applyAt",
+	"category":    "category",
+	"location":    "location",
+	"skills":      "skills",
+}
+
+// ParseCSV reads rows from CSV starting with a header line.
+// Headers are case insensitive and skills are separated by ";".
+func ParseCSV(r io.Reader) ([]Row, []models.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make([]string, len(header))
+	for i, h := range header {
+		field, ok := csvColumns[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			return nil, nil, fmt.Errorf("importer: unknown column %q", h)
+		}
+		columns[i] = field
+	}
+
+	var rows []Row
+	var rowErrors []models.ImportRowError
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(record) != len(columns) {
+			rowErrors = append(rowErrors, models.ImportRowError{
+				Row:     number,
+				Message: fmt.Sprintf("expected %d columns, got %d", len(columns), len(record)),
+			})
+			continue
+		}
+		row := Row{Number: number}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "title":
+				row.Title = value
+			case "description":
+				row.Description = value
+			case "applyAt":
+				row.ApplyAt = value
+			case "category":
+				row.Category = value
+			case "location":
+				row.Location = value
+			case "skills":
+				row.Skills = splitSkills(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// ParseJSONLines reads a JSON object per line, blank lines are
+// skipped but still counted as rows.
+func ParseJSONLines(r io.Reader) ([]Row, []models.ImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLineBytes)
+
+	var rows []Row
+	var rowErrors []models.ImportRowError
+	for number := 1; scanner.Scan(); number++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := Row{Number: number}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			rowErrors = append(rowErrors, models.ImportRowError{
+				Row:     number,
+				Message: err.Error(),
+			})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
+
+func splitSkills(value string) []string {
+	var skills []string
+	for _, s := range strings.Split(value, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			skills = append(skills, s)
+		}
+	}
+	return skills
+}
@@ -4,13 +4,25 @@ import (
 	"flag"
 	"fmt"
 	"github.com/samueldaviddelacruz/go-job-board/API/middleware"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/samueldaviddelacruz/go-job-board/API/alerts"
 	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
 	"github.com/samueldaviddelacruz/go-job-board/API/email"
+	"github.com/samueldaviddelacruz/go-job-board/API/events"
+	"github.com/samueldaviddelacruz/go-job-board/API/importer"
 
 	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/pages"
+	"github.com/samueldaviddelacruz/go-job-board/API/retention"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+	"github.com/samueldaviddelacruz/go-job-board/API/storage"
+	"github.com/samueldaviddelacruz/go-job-board/API/verification"
+	"github.com/samueldaviddelacruz/go-job-board/API/webhooks"
 )
 
 func main() {
@@ -32,6 +44,15 @@ func main() {
 		models.WithOAuth(),
 		models.WithCategory(),
 		models.WithLocation(),
+		models.WithSavedSearch(appCfg.HMACKey),
+		models.WithBookmark(),
+		models.WithApplication(),
+		models.WithWebhook(),
+		models.WithEvent(),
+		models.WithAPIKey(appCfg.HMACKey),
+		models.WithImportJob(),
+		models.WithJobFeed(),
+		models.WithCompany(appCfg.HMACKey),
 	)
 	must(err)
 
@@ -45,108 +66,527 @@ func main() {
 		email.WithMailgun(mgCfg.Domain, mgCfg.APIKey),
 	)
 
+	notifier := alerts.NewNotifier(services.SavedSearch, services.JobPost, services.User, emailer)
+	go notifier.Run(nil)
+	dispatcher := webhooks.NewDispatcher(services.Webhook, services.Company, webhooks.NewSender())
+	go dispatcher.Run(nil)
+
+	eventsD := events.NewDispatcher(services.Event)
+	eventsD.Subscribe(notifier.HandleEvent, models.EventJobPostPublished, models.EventJobPostSkillAdded)
+	eventsD.Subscribe(dispatcher.HandleEvent, webhooks.DomainEvents()...)
+	seoURLs := seo.NewURLs(appCfg.BaseURL)
+	sitemaps := seo.NewSitemaps(services.JobPost, services.User, seoURLs)
+	eventsD.Subscribe(sitemaps.HandleEvent, seo.DomainEvents()...)
+	go eventsD.Run(nil)
+	go events.NewAnnouncer(services.JobPost).Run(nil)
+
 	r := mux.NewRouter()
 
-	jobsC := controllers.NewJobs(services.JobPost, services.Skill)
+	jobsC := controllers.NewJobs(services.JobPost, services.Skill, services.Bookmark, services.Application, services.Company)
 	categoriesC := controllers.NewCategories(services.Category)
 	locationsC := controllers.NewLocations(services.Location)
-	usersC := controllers.NewUsers(services.User, services.Skill)
+	skillsC := controllers.NewSkills(services.Skill)
+	savedSearchesC := controllers.NewSavedSearches(services.SavedSearch)
+	meC := controllers.NewMe(services.Bookmark, services.Application, services.User, emailer)
+	webhooksC := controllers.NewWebhooks(services.Webhook, dispatcher)
+	apiKeysC := controllers.NewAPIKeys(services.APIKey, services.Company)
+	bulkImporter := importer.NewBulk(services.ImportJob, services.JobPost, services.Skill, services.Category, services.Location)
+	importsC := controllers.NewImports(services.ImportJob, bulkImporter)
+	feedSyncer := importer.NewFeedSyncer(services.JobFeed, services.JobPost, services.Category, services.Location, importer.NewHTTPFetcher())
+	go feedSyncer.Run(nil)
+	jobFeedsC := controllers.NewJobFeeds(services.JobFeed, feedSyncer)
+	feedsC := controllers.NewFeeds(services.JobPost, appCfg.BaseURL)
+	pagesC := controllers.NewPages(services.JobPost, services.User, pages.NewRenderer(seoURLs))
+	store, mediaHandler := newStorage(appCfg.Storage, appCfg.BaseURL)
+	logosC := controllers.NewLogos(services.User, services.Company, store)
+	companiesC := controllers.NewCompanies(services.User, services.JobPost, seoURLs)
+	seoC := controllers.NewSEO(services.JobPost, services.User, services.Category, services.Location, sitemaps, seoURLs)
+	usersC := controllers.NewUsers(services.User, services.Skill, services.JobPost)
 	authC := controllers.NewAuth(services.User, emailer)
+	membersC := controllers.NewMembers(services.Company, emailer)
+	verifier := verification.NewVerifier(appCfg.HMACKey, net.DefaultResolver, verification.NewHTTPFetcher())
+	verificationsC := controllers.NewVerifications(services.User, services.Company, verifier, emailer)
+	adminC := controllers.NewAdmin(services.JobPost, services.User)
+	purger := retention.NewPurger(services.JobPost, services.User, time.Duration(appCfg.TrashRetentionDays)*24*time.Hour)
+	go purger.Run(nil)
 
 	must(err)
 
 	requireJWT := middleware.RequireJWT{
 		Secret: appCfg.HMACKey,
 	}
+	userMw := middleware.User{
+		Secret:      appCfg.HMACKey,
+		UserService: services.User,
+	}
+	requireUserMw := middleware.RequireUser{
+		User: userMw,
+	}
+	requireAdminMw := middleware.RequireAdmin{
+		RequireUser: requireUserMw,
+	}
+	authMw := middleware.Auth{
+		User:      userMw,
+		APIKeys:   services.APIKey,
+		Companies: services.Company,
+	}
 
 	applyRoutes(r,
 		Route{
 			path:    "/signup",
-			handler: authC.Create,
+			handler: controllers.Handle(authC.Create),
 			method:  "POST",
 		},
 		Route{
 			path:    "/login",
-			handler: authC.Login,
+			handler: controllers.Handle(authC.Login),
 			method:  "POST",
 		},
 		Route{
 			path:    "/user/{id:[0-9]+}",
-			handler: requireJWT.ApplyFn(usersC.Update),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.Update)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}",
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.Update)),
+			method:  "PATCH",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/add-skill",
+			handler: requireUserMw.ApplyFn(controllers.Handle(usersC.AddSkill)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/remove-skill",
+			handler: requireUserMw.ApplyFn(controllers.Handle(usersC.RemoveSkill)),
 			method:  "PUT",
 		},
+		Route{
+			path:    "/user/{id:[0-9]+}/recommendations",
+			handler: requireUserMw.ApplyFn(controllers.Handle(usersC.Recommendations)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/saved-searches",
+			handler: requireUserMw.ApplyFn(controllers.Handle(savedSearchesC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/saved-searches",
+			handler: requireUserMw.ApplyFn(controllers.Handle(savedSearchesC.Create)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/saved-searches/{searchId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(savedSearchesC.Delete)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/saved-searches/{id:[0-9]+}/unsubscribe",
+			handler: controllers.Handle(savedSearchesC.Unsubscribe),
+			method:  "GET",
+		},
+		Route{
+			path:    "/saved-searches/{id:[0-9]+}/unsubscribe",
+			handler: controllers.Handle(savedSearchesC.Unsubscribe),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/webhooks",
+			handler: requireUserMw.ApplyFn(controllers.Handle(webhooksC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/webhooks",
+			handler: requireUserMw.ApplyFn(controllers.Handle(webhooksC.Create)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/webhooks/{hookId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(webhooksC.Delete)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/webhooks/{hookId:[0-9]+}/deliveries",
+			handler: requireUserMw.ApplyFn(controllers.Handle(webhooksC.Deliveries)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/webhooks/{hookId:[0-9]+}/test",
+			handler: requireUserMw.ApplyFn(controllers.Handle(webhooksC.SendTest)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/api-keys",
+			handler: requireUserMw.ApplyFn(controllers.Handle(apiKeysC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/api-keys",
+			handler: requireUserMw.ApplyFn(controllers.Handle(apiKeysC.Create)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/api-keys/{keyId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(apiKeysC.Revoke)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/job-feeds",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobFeedsC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/job-feeds",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobFeedsC.Create)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/job-feeds/{feedId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobFeedsC.Delete)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/job-feeds/{feedId:[0-9]+}/sync",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobFeedsC.Sync)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/users/{id:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(usersC.Show)),
+			method:  "GET",
+		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile",
-			handler: requireJWT.ApplyFn(usersC.UpdateCompanyProfile),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.CompanyProfile)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/company-profile",
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.UpdateCompanyProfile)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/company-profile",
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.UpdateCompanyProfile)),
+			method:  "PATCH",
+		},
+		Route{
+			path:    "/user/{id:[0-9]+}/company-profile/logo",
+			handler: requireUserMw.ApplyFn(controllers.Handle(logosC.Upload)),
 			method:  "PUT",
 		},
+		Route{
+			path:    "/user/{id:[0-9]+}/company-profile/logo",
+			handler: requireUserMw.ApplyFn(controllers.Handle(logosC.Delete)),
+			method:  "DELETE",
+		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile/add-skill",
-			handler: requireJWT.ApplyFn(usersC.AddCompanyProfileSkill),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.AddCompanyProfileSkill)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile/remove-skill",
-			handler: requireJWT.ApplyFn(usersC.RemoveCompanyProfileSkill),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.RemoveCompanyProfileSkill)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile/add-benefit",
-			handler: requireJWT.ApplyFn(usersC.AddCompanyProfileBenefit),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.AddCompanyProfileBenefit)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile/remove-benefit",
-			handler: requireJWT.ApplyFn(usersC.RemoveCompanyProfileBenefit),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.RemoveCompanyProfileBenefit)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/user/{id:[0-9]+}/company-profile/update-benefit",
-			handler: requireJWT.ApplyFn(usersC.UpdateCompanyProfileBenefit),
+			handler: requireJWT.ApplyFn(controllers.Handle(usersC.UpdateCompanyProfileBenefit)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/jobs",
-			handler: jobsC.List,
+			handler: authMw.AllowFn(models.ScopeJobsRead, controllers.Handle(jobsC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs.rss",
+			handler: controllers.Handle(feedsC.RSS),
 			method:  "GET",
 		},
 		Route{
-			path: "/jobs",
-			//handler: requireJWT.ApplyFn(jobsC.Create),
-			handler: jobsC.Create,
+			path:    "/jobs.atom",
+			handler: controllers.Handle(feedsC.Atom),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs.json",
+			handler: controllers.Handle(feedsC.JSON),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}",
+			handler: authMw.AllowFn(models.ScopeJobsRead, controllers.Handle(jobsC.Show)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/jsonld",
+			handler: controllers.Handle(seoC.JobPosting),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies",
+			handler: controllers.Handle(companiesC.List),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies/{slug}",
+			handler: controllers.Handle(companiesC.Show),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/members",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.List)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/members/{userId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.UpdateRole)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/members/{userId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.Remove)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/invitations",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.Invitations)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/invitations",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.Invite)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/invitations/{invitationId:[0-9]+}",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.RevokeInvitation)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/verification",
+			handler: requireUserMw.ApplyFn(controllers.Handle(verificationsC.Show)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/verification/dns",
+			handler: requireUserMw.ApplyFn(controllers.Handle(verificationsC.CheckDNS)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/verification/file",
+			handler: requireUserMw.ApplyFn(controllers.Handle(verificationsC.CheckFile)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/verification/email",
+			handler: requireUserMw.ApplyFn(controllers.Handle(verificationsC.SendEmail)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/companies/{id:[0-9]+}/verification/email/confirm",
+			handler: requireUserMw.ApplyFn(controllers.Handle(verificationsC.ConfirmEmail)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/invitations/accept",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.Accept)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/j/{slug}",
+			handler: controllers.Handle(pagesC.JobPost),
+			method:  "GET",
+		},
+		Route{
+			path:    "/c/{slug}",
+			handler: controllers.Handle(pagesC.Company),
+			method:  "GET",
+		},
+		Route{
+			path:    "/sitemap.xml",
+			handler: controllers.Handle(seoC.SitemapIndex),
+			method:  "GET",
+		},
+		Route{
+			path:    "/sitemaps/{name:[a-z]+-[0-9]+}.xml",
+			handler: controllers.Handle(seoC.Sitemap),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Create)),
 			method:  "POST",
 		},
+		Route{
+			path:    "/jobs/trash",
+			handler: authMw.RequireFn(models.ScopeJobsRead, controllers.Handle(jobsC.Trash)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/restore",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Restore)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/admin/jobs/{id:[0-9]+}",
+			handler: requireAdminMw.ApplyFn(controllers.Handle(adminC.PurgeJobPost)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/admin/users/{id:[0-9]+}/restore",
+			handler: requireAdminMw.ApplyFn(controllers.Handle(adminC.RestoreUser)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/admin/users/{id:[0-9]+}",
+			handler: requireAdminMw.ApplyFn(controllers.Handle(adminC.PurgeUser)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/jobs/import",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(importsC.Create)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/jobs/import/{importId:[0-9]+}",
+			handler: authMw.RequireFn(models.ScopeJobsRead, controllers.Handle(importsC.Show)),
+			method:  "GET",
+		},
 		Route{
 			path:    "/jobs/{id:[0-9]+}",
-			handler: requireJWT.ApplyFn(jobsC.Update),
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Update)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/jobs/{id:[0-9]+}",
-			handler: requireJWT.ApplyFn(jobsC.Delete),
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Update)),
+			method:  "PATCH",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Delete)),
 			method:  "DELETE",
 		},
 		Route{
 			path:    "/jobs/{id:[0-9]+}/add-skill",
-			handler: requireJWT.ApplyFn(jobsC.AddJobPostSkill),
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.AddJobPostSkill)),
 			method:  "PUT",
 		},
 		Route{
 			path:    "/jobs/{id:[0-9]+}/remove-skill",
-			handler: requireJWT.ApplyFn(jobsC.RemoveJobPostSkill),
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.RemoveJobPostSkill)),
 			method:  "PUT",
 		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/revisions",
+			handler: authMw.RequireFn(models.ScopeJobsRead, controllers.Handle(jobsC.Revisions)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/revisions/{number:[0-9]+}/restore",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.RestoreRevision)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/close",
+			handler: authMw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobsC.Close)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/applications",
+			handler: authMw.RequireFn(models.ScopeApplicationsRead, controllers.Handle(jobsC.Applications)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/bookmark",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobsC.Bookmark)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/bookmark",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobsC.RemoveBookmark)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/applied",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobsC.MarkApplied)),
+			method:  "PUT",
+		},
+		Route{
+			path:    "/jobs/{id:[0-9]+}/applied",
+			handler: requireUserMw.ApplyFn(controllers.Handle(jobsC.UnmarkApplied)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/me/bookmarks",
+			handler: requireUserMw.ApplyFn(controllers.Handle(meC.Bookmarks)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/me/applications",
+			handler: requireUserMw.ApplyFn(controllers.Handle(meC.Applications)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/me/export",
+			handler: requireUserMw.ApplyFn(controllers.Handle(meC.Export)),
+			method:  "GET",
+		},
+		Route{
+			path:    "/me",
+			handler: requireUserMw.ApplyFn(controllers.Handle(meC.Delete)),
+			method:  "DELETE",
+		},
+		Route{
+			path:    "/me/cancel-deletion",
+			handler: requireUserMw.ApplyFn(controllers.Handle(meC.CancelDeletion)),
+			method:  "POST",
+		},
+		Route{
+			path:    "/me/companies",
+			handler: requireUserMw.ApplyFn(controllers.Handle(membersC.Companies)),
+			method:  "GET",
+		},
 		Route{
 			path:    "/categories",
-			handler: categoriesC.List,
+			handler: controllers.Handle(categoriesC.List),
 			method:  "GET",
 		},
 		Route{
 			path:    "/locations",
-			handler: locationsC.List,
+			handler: controllers.Handle(locationsC.List),
+			method:  "GET",
+		},
+		Route{
+			path:    "/skills",
+			handler: controllers.Handle(skillsC.Autocomplete),
+			method:  "GET",
+			queries: []string{"prefix", "{prefix}"},
+		},
+		Route{
+			path:    "/skills",
+			handler: controllers.Handle(skillsC.List),
 			method:  "GET",
 		},
 	)
+	if mediaHandler != nil {
+		r.PathPrefix("/media/").Methods("GET", "HEAD").Handler(mediaHandler)
+	}
 
 	fmt.Printf("Running on port :%d", appCfg.Port)
 	must(http.ListenAndServe(fmt.Sprintf(":%d", appCfg.Port), r))
@@ -156,11 +596,38 @@ type Route struct {
 	path    string
 	handler func(http.ResponseWriter, *http.Request)
 	method  string
+	// queries restricts the route to requests carrying these
+	// query parameters, as key/value pattern pairs.
+	queries []string
+}
+
+// newStorage returns the storage of uploaded files and, for the
+// local backend, the handler serving them at /media/.
+func newStorage(cfg StorageConfig, baseURL string) (storage.Storage, http.Handler) {
+	if cfg.Backend == "s3" {
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        cfg.Endpoint,
+			Region:          cfg.Region,
+			Bucket:          cfg.Bucket,
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			PublicURL:       cfg.PublicURL,
+		}), nil
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "media"
+	}
+	local := storage.NewLocal(dir, strings.TrimRight(baseURL, "/")+"/media")
+	return local, http.StripPrefix("/media", local.Handler())
 }
 
 func applyRoutes(r *mux.Router, routes ...Route) {
 	for _, route := range routes {
-		r.HandleFunc(route.path, route.handler).Methods(route.method)
+		muxRoute := r.HandleFunc(route.path, route.handler).Methods(route.method)
+		if len(route.queries) > 0 {
+			muxRoute.Queries(route.queries...)
+		}
 	}
 }
 
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// APIKeyHeader is the header companies send their API key in
+const APIKeyHeader = "X-API-Key"
+
+// Auth authenticates the requests made either by a user with a
+// JWT in the Authorization header or by a company with an API
+// key in the X-API-Key header. The owner of the key is stored
+// in the request context like the user of a JWT is, along with
+// the key that limits the request to its company. Keys stop
+// working once their owner leaves the company. Users are
+// granted every scope.
+type Auth struct {
+	User
+	APIKeys   models.APIKeyService
+	Companies models.CompanyService
+}
+
+// RequireFn rejects the requests that are not authenticated or
+// whose API key was not granted the scope.
+func (mw *Auth) RequireFn(scope models.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return mw.apply(scope, true, next)
+}
+
+// AllowFn lets anonymous requests through, but still rejects
+// API keys that were not granted the scope.
+func (mw *Auth) AllowFn(scope models.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return mw.apply(scope, false, next)
+}
+
+func (mw *Auth) apply(scope models.APIKeyScope, required bool, next http.HandlerFunc) http.HandlerFunc {
+	withUser := mw.User.ApplyFn(func(w http.ResponseWriter, r *http.Request) {
+		if required && llctx.User(r.Context()) == nil {
+			http.Error(w, "a valid token or API key is required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(APIKeyHeader)
+		if key == "" {
+			withUser(w, r)
+			return
+		}
+		apiKey, err := mw.APIKeys.Authenticate(key)
+		if err == models.ErrAPIKeyInvalid {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !apiKey.HasScope(scope) {
+			http.Error(w, "the API key is missing the "+string(scope)+" scope", http.StatusForbidden)
+			return
+		}
+		user, err := mw.ByID(apiKey.UserID)
+		if err != nil {
+			http.Error(w, models.ErrAPIKeyInvalid.Error(), http.StatusUnauthorized)
+			return
+		}
+		_, err = mw.Companies.Member(apiKey.CompanyID, apiKey.UserID)
+		if err == models.ErrNotFound {
+			http.Error(w, models.ErrAPIKeyInvalid.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ctx := llctx.WithAPIKey(llctx.WithUser(r.Context(), user), apiKey)
+		next(w, r.WithContext(ctx))
+	}
+}
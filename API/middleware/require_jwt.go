@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"github.com/gbrlsnchs/jwt/v3"
-	"github.com/samueldaviddelacruz/go-job-board/API/models"
 	"net/http"
 )
 
@@ -21,10 +20,7 @@ func (mw *RequireJWT) Apply(next http.Handler) http.HandlerFunc {
 func (mw *RequireJWT) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
 	var hs = jwt.NewHS256([]byte(mw.Secret))
 	return func(w http.ResponseWriter, r *http.Request) {
-		var pl models.CustomPayload
-		token := r.Header.Get("Authorization")
-
-		_, err := jwt.Verify([]byte(token), hs, &pl)
+		_, err := verifyToken(r, hs)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
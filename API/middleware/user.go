@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gbrlsnchs/jwt/v3"
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// User looks up the user a JWT was issued to and stores it in
+// the request context. Requests without a valid token are let
+// through without a user.
+type User struct {
+	Secret string
+	models.UserService
+}
+
+func (mw *User) Apply(next http.Handler) http.HandlerFunc {
+	return mw.ApplyFn(next.ServeHTTP)
+}
+
+func (mw *User) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
+	var hs = jwt.NewHS256([]byte(mw.Secret))
+	return func(w http.ResponseWriter, r *http.Request) {
+		pl, err := verifyToken(r, hs)
+		if err != nil {
+			next(w, r)
+			return
+		}
+		user, err := mw.ByEmail(pl.Email)
+		if err != nil {
+			next(w, r)
+			return
+		}
+		ctx := llctx.WithUser(r.Context(), user)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// RequireUser rejects the requests that are not made by a
+// user, it runs the User middleware itself.
+type RequireUser struct {
+	User
+}
+
+func (mw *RequireUser) Apply(next http.Handler) http.HandlerFunc {
+	return mw.ApplyFn(next.ServeHTTP)
+}
+
+func (mw *RequireUser) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
+	return mw.User.ApplyFn(func(w http.ResponseWriter, r *http.Request) {
+		if llctx.User(r.Context()) == nil {
+			http.Error(w, "a valid token is required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// RequireAdmin rejects the requests that are not made by a
+// user with the admin role.
+type RequireAdmin struct {
+	RequireUser
+}
+
+func (mw *RequireAdmin) Apply(next http.Handler) http.HandlerFunc {
+	return mw.ApplyFn(next.ServeHTTP)
+}
+
+func (mw *RequireAdmin) ApplyFn(next http.HandlerFunc) http.HandlerFunc {
+	return mw.RequireUser.ApplyFn(func(w http.ResponseWriter, r *http.Request) {
+		admin, err := mw.IsAdmin(llctx.User(r.Context()))
+		if err != nil {
+			http.Error(w, "Something went wrong.", http.StatusInternalServerError)
+			return
+		}
+		if !admin {
+			http.Error(w, "admin access is required", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// verifyToken verifies the JWT of the Authorization header,
+// with or without the "Bearer" scheme.
+func verifyToken(r *http.Request, hs *jwt.HMACSHA) (*models.CustomPayload, error) {
+	var pl models.CustomPayload
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	_, err := jwt.Verify([]byte(token), hs, &pl)
+	if err != nil {
+		return nil, err
+	}
+	return &pl, nil
+}
@@ -0,0 +1,284 @@
+package models
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/samueldaviddelacruz/go-job-board/API/hash"
+	"github.com/samueldaviddelacruz/go-job-board/API/rand"
+)
+
+// APIKeyScope is a permission granted to an API key
+type APIKeyScope string
+
+const (
+	ScopeJobsRead         APIKeyScope = "jobs:read"
+	ScopeJobsWrite        APIKeyScope = "jobs:write"
+	ScopeApplicationsRead APIKeyScope = "applications:read"
+)
+
+// APIKeyScopes are the scopes an API key can be granted
+var APIKeyScopes = []APIKeyScope{
+	ScopeJobsRead,
+	ScopeJobsWrite,
+	ScopeApplicationsRead,
+}
+
+const (
+	apiKeyPrefix       = "jbk_"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyDefaultName  = "API key"
+	apiKeyUsedInterval = time.Minute
+)
+
+// APIKey lets a company call the API server-to-server. The key
+// is "<Prefix>.<secret>", the prefix is used to look the key up
+// and only the HMAC of the whole key is stored, so Key is only
+// returned when the key is created. Requests made with the key
+// act as its owner, but only within CompanyID.
+type APIKey struct {
+	gorm.Model
+	UserID    uint   `gorm:"not null;index" json:"userId"`
+	CompanyID uint   `gorm:"not null;index" json:"companyId"`
+	Name      string `gorm:"not null" json:"name"`
+	Prefix    string `gorm:"not null;unique_index" json:"prefix"`
+	Key       string `gorm:"-" json:"key,omitempty"`
+	KeyHash   string `gorm:"not null" json:"-"`
+	// Scopes is a comma separated list of APIKeyScope
+	Scopes     string     `gorm:"not null" json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// HasScope reports whether the key was granted the scope
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range strings.Split(k.Scopes, ",") {
+		if APIKeyScope(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyService interface {
+	APIKeyDB
+
+	// Authenticate returns the active API key matching the
+	// provided key and records that it was used.
+	Authenticate(key string) (*APIKey, error)
+}
+
+type APIKeyDB interface {
+	ByID(id uint) (*APIKey, error)
+	ByPrefix(prefix string) (*APIKey, error)
+	ByUserID(userID uint) ([]APIKey, error)
+	Create(apiKey *APIKey) error
+	Revoke(id uint) error
+	// Touch sets the last time the key was used
+	Touch(id uint, usedAt time.Time) error
+}
+
+func NewAPIKeyService(db *gorm.DB, hmacKey string) APIKeyService {
+	hmac := hash.NewHMAC(hmacKey)
+	return &apiKeyService{
+		APIKeyDB: &apiKeyValidator{
+			APIKeyDB: &apiKeyGorm{db},
+			hmac:     hmac,
+		},
+		hmac: hmac,
+	}
+}
+
+var _ APIKeyService = &apiKeyService{}
+
+type apiKeyService struct {
+	APIKeyDB
+	hmac hash.HMAC
+}
+
+func (aks *apiKeyService) Authenticate(key string) (*APIKey, error) {
+	i := strings.Index(key, ".")
+	if i <= 0 {
+		return nil, ErrAPIKeyInvalid
+	}
+	apiKey, err := aks.ByPrefix(key[:i])
+	if err == ErrNotFound {
+		return nil, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil || !aks.hmac.Equal(key, apiKey.KeyHash) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	// Keys are used on every request, so the timestamp is only
+	// written once in a while.
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyUsedInterval {
+		if err := aks.Touch(apiKey.ID, now); err != nil {
+			return nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, nil
+}
+
+type apiKeyValidator struct {
+	APIKeyDB
+	hmac hash.HMAC
+}
+
+func (akv *apiKeyValidator) Create(apiKey *APIKey) error {
+	err := runAPIKeyValFuncs(apiKey,
+		akv.userIDRequired,
+		akv.companyIDRequired,
+		akv.setNameIfUnset,
+		akv.scopesValid,
+		akv.generateKey)
+	if err != nil {
+		return err
+	}
+
+	return akv.APIKeyDB.Create(apiKey)
+}
+
+func (akv *apiKeyValidator) Revoke(id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return akv.APIKeyDB.Revoke(id)
+}
+
+func (akv *apiKeyValidator) userIDRequired(k *APIKey) error {
+	if k.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	return nil
+}
+
+func (akv *apiKeyValidator) companyIDRequired(k *APIKey) error {
+	if k.CompanyID <= 0 {
+		return ErrCompanyIDRequired
+	}
+	return nil
+}
+
+func (akv *apiKeyValidator) setNameIfUnset(k *APIKey) error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" {
+		k.Name = apiKeyDefaultName
+	}
+	return nil
+}
+
+// scopesValid requires at least one scope, keys are not granted
+// any scope by default.
+func (akv *apiKeyValidator) scopesValid(k *APIKey) error {
+	var scopes []string
+	for _, s := range strings.Split(k.Scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		known := false
+		for _, scope := range APIKeyScopes {
+			if APIKeyScope(s) == scope {
+				known = true
+			}
+		}
+		if !known {
+			return ErrAPIKeyScopeInvalid
+		}
+		scopes = append(scopes, s)
+	}
+	if len(scopes) == 0 {
+		return ErrAPIKeyScopeInvalid
+	}
+	k.Scopes = strings.Join(scopes, ",")
+	return nil
+}
+
+func (akv *apiKeyValidator) generateKey(k *APIKey) error {
+	b, err := rand.Bytes(apiKeyPrefixBytes)
+	if err != nil {
+		return err
+	}
+	secret, err := rand.String(apiKeySecretBytes)
+	if err != nil {
+		return err
+	}
+	k.Prefix = apiKeyPrefix + hex.EncodeToString(b)
+	k.Key = k.Prefix + "." + secret
+	k.KeyHash = akv.hmac.Hash(k.Key)
+	return nil
+}
+
+var _ APIKeyDB = &apiKeyGorm{}
+
+type apiKeyGorm struct {
+	db *gorm.DB
+}
+
+func (akg *apiKeyGorm) ByID(id uint) (*APIKey, error) {
+	var apiKey APIKey
+	err := first(akg.db.Where("id = ?", id), &apiKey)
+
+	return &apiKey, err
+}
+
+func (akg *apiKeyGorm) ByPrefix(prefix string) (*APIKey, error) {
+	var apiKey APIKey
+	err := first(akg.db.Where("prefix = ?", prefix), &apiKey)
+
+	return &apiKey, err
+}
+
+func (akg *apiKeyGorm) ByUserID(userID uint) ([]APIKey, error) {
+	var apiKeys []APIKey
+	err := akg.db.Where("user_id = ?", userID).Order("created_at desc").Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (akg *apiKeyGorm) Create(apiKey *APIKey) error {
+	return akg.db.Create(apiKey).Error
+}
+
+// Revoke keeps the key around so it still shows in the list
+// of keys of the company.
+func (akg *apiKeyGorm) Revoke(id uint) error {
+	db := akg.db.Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (akg *apiKeyGorm) Touch(id uint, usedAt time.Time) error {
+	return akg.db.Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+}
+
+type apiKeyValFunc func(*APIKey) error
+
+func runAPIKeyValFuncs(apiKey *APIKey, fns ...apiKeyValFunc) error {
+	for _, fn := range fns {
+		if err := fn(apiKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
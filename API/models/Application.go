@@ -0,0 +1,120 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// Application records that a user applied to a job post.
+type Application struct {
+	gorm.Model
+	UserID    uint     `gorm:"not null;unique_index:idx_application_user_job_post" json:"userId"`
+	JobPostID uint     `gorm:"not null;unique_index:idx_application_user_job_post" json:"jobPostId"`
+	JobPost   *JobPost `json:"jobPost,omitempty"`
+}
+
+type ApplicationService interface {
+	ApplicationDB
+}
+
+type ApplicationDB interface {
+	// ByUserID returns the applications of the user along with
+	// their job post, most recent first.
+	ByUserID(userID uint) ([]Application, error)
+	ByJobPostID(jobPostID uint) ([]Application, error)
+	// Create records the application, applying to a job post
+	// twice is not an error.
+	Create(application *Application) error
+	Delete(userID, jobPostID uint) error
+}
+
+func NewApplicationService(db *gorm.DB) ApplicationService {
+	return &applicationValidator{
+		&applicationGorm{db},
+	}
+}
+
+type applicationValidator struct {
+	ApplicationDB
+}
+
+func (av *applicationValidator) Create(application *Application) error {
+	if application.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	if application.JobPostID <= 0 {
+		return ErrIDInvalid
+	}
+	return av.ApplicationDB.Create(application)
+}
+
+func (av *applicationValidator) Delete(userID, jobPostID uint) error {
+	if userID <= 0 {
+		return ErrUserIDRequired
+	}
+	if jobPostID <= 0 {
+		return ErrIDInvalid
+	}
+	return av.ApplicationDB.Delete(userID, jobPostID)
+}
+
+var _ ApplicationDB = &applicationGorm{}
+
+type applicationGorm struct {
+	db *gorm.DB
+}
+
+func (ag *applicationGorm) ByUserID(userID uint) ([]Application, error) {
+	var applications []Application
+	err := ag.db.Preload("JobPost").
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&applications).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+func (ag *applicationGorm) ByJobPostID(jobPostID uint) ([]Application, error) {
+	var applications []Application
+	err := ag.db.Where("job_post_id = ?", jobPostID).
+		Order("created_at desc").
+		Find(&applications).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// Create only records an ApplicationReceived event the first
+// time the user applies to the job post.
+func (ag *applicationGorm) Create(application *Application) error {
+	return transaction(ag.db, func(tx *gorm.DB) error {
+		db := tx.Where("user_id = ? AND job_post_id = ?", application.UserID, application.JobPostID)
+		err := first(db, application)
+		if err != ErrNotFound {
+			return err
+		}
+		err = tx.Set("gorm:association_autoupdate", false).Create(application).Error
+		if err != nil {
+			return err
+		}
+
+		// The job post is part of the payload so subscribers
+		// know which company received the application.
+		received := *application
+		received.JobPost = &JobPost{}
+		if err := first(tx.Where("id = ?", application.JobPostID), received.JobPost); err != nil {
+			return err
+		}
+		return recordEvent(tx, EventApplicationReceived, aggregateApplication, application.ID, received)
+	})
+}
+
+// Delete removes the application for good so the user can
+// apply again.
+func (ag *applicationGorm) Delete(userID, jobPostID uint) error {
+	return ag.db.Unscoped().
+		Where("user_id = ? AND job_post_id = ?", userID, jobPostID).
+		Delete(&Application{}).Error
+}
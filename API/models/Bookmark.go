@@ -0,0 +1,88 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// Bookmark is a job post saved by a user for later
+type Bookmark struct {
+	gorm.Model
+	UserID    uint     `gorm:"not null;unique_index:idx_bookmark_user_job_post" json:"userId"`
+	JobPostID uint     `gorm:"not null;unique_index:idx_bookmark_user_job_post" json:"jobPostId"`
+	JobPost   *JobPost `json:"jobPost,omitempty"`
+}
+
+type BookmarkService interface {
+	BookmarkDB
+}
+
+type BookmarkDB interface {
+	// ByUserID returns the bookmarks of the user along with
+	// their job post, most recent first.
+	ByUserID(userID uint) ([]Bookmark, error)
+	// Create bookmarks the job post, bookmarking a job post
+	// twice is not an error.
+	Create(bookmark *Bookmark) error
+	Delete(userID, jobPostID uint) error
+}
+
+func NewBookmarkService(db *gorm.DB) BookmarkService {
+	return &bookmarkValidator{
+		&bookmarkGorm{db},
+	}
+}
+
+type bookmarkValidator struct {
+	BookmarkDB
+}
+
+func (bv *bookmarkValidator) Create(bookmark *Bookmark) error {
+	if bookmark.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	if bookmark.JobPostID <= 0 {
+		return ErrIDInvalid
+	}
+	return bv.BookmarkDB.Create(bookmark)
+}
+
+func (bv *bookmarkValidator) Delete(userID, jobPostID uint) error {
+	if userID <= 0 {
+		return ErrUserIDRequired
+	}
+	if jobPostID <= 0 {
+		return ErrIDInvalid
+	}
+	return bv.BookmarkDB.Delete(userID, jobPostID)
+}
+
+var _ BookmarkDB = &bookmarkGorm{}
+
+type bookmarkGorm struct {
+	db *gorm.DB
+}
+
+func (bg *bookmarkGorm) ByUserID(userID uint) ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	err := bg.db.Preload("JobPost").
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&bookmarks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+func (bg *bookmarkGorm) Create(bookmark *Bookmark) error {
+	return bg.db.Set("gorm:association_autoupdate", false).
+		Where(Bookmark{UserID: bookmark.UserID, JobPostID: bookmark.JobPostID}).
+		FirstOrCreate(bookmark).Error
+}
+
+// Delete removes the bookmark for good so the job post can be
+// bookmarked again.
+func (bg *bookmarkGorm) Delete(userID, jobPostID uint) error {
+	return bg.db.Unscoped().
+		Where("user_id = ? AND job_post_id = ?", userID, jobPostID).
+		Delete(&Bookmark{}).Error
+}
@@ -0,0 +1,586 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/samueldaviddelacruz/go-job-board/API/hash"
+	"github.com/samueldaviddelacruz/go-job-board/API/rand"
+)
+
+// invitationTTL is how long an invitation can be accepted for
+const invitationTTL = 7 * 24 * time.Hour
+
+// MemberRole is the role of a user within a company
+type MemberRole string
+
+const (
+	MemberOwner     MemberRole = "owner"
+	MemberAdmin     MemberRole = "admin"
+	MemberRecruiter MemberRole = "recruiter"
+	MemberViewer    MemberRole = "viewer"
+)
+
+func (mr MemberRole) Valid() bool {
+	switch mr {
+	case MemberOwner, MemberAdmin, MemberRecruiter, MemberViewer:
+		return true
+	}
+	return false
+}
+
+// CanManageJobPosts reports whether the role can create, edit
+// and close the job posts of the company.
+func (mr MemberRole) CanManageJobPosts() bool {
+	return mr == MemberOwner || mr == MemberAdmin || mr == MemberRecruiter
+}
+
+// CanManageMembers reports whether the role can invite, remove
+// and change the role of the members of the company.
+func (mr MemberRole) CanManageMembers() bool {
+	return mr == MemberOwner || mr == MemberAdmin
+}
+
+// CanView reports whether the role can see the job posts and
+// applications of the company, which every member can.
+func (mr MemberRole) CanView() bool {
+	return mr.Valid()
+}
+
+// CanGrant reports whether the role can give or take the other
+// role. Only owners can make or unmake owners.
+func (mr MemberRole) CanGrant(other MemberRole) bool {
+	if !mr.CanManageMembers() {
+		return false
+	}
+	return mr == MemberOwner || other != MemberOwner
+}
+
+// Company is the organization the recruiters of a company
+// profile belong to. The job posts of the company can be
+// managed by any of its members with the rights to.
+type Company struct {
+	gorm.Model
+	Name    string          `json:"name"`
+	Members []CompanyMember `json:"members,omitempty"`
+}
+
+// CompanyMember gives a user a role within a company, a user
+// is a member of a company at most once.
+type CompanyMember struct {
+	ID        uint       `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	CompanyID uint       `gorm:"not null;unique_index:idx_company_member" json:"companyId"`
+	UserID    uint       `gorm:"not null;unique_index:idx_company_member;index" json:"userId"`
+	User      *User      `gorm:"save_associations:false" json:"user,omitempty"`
+	Role      MemberRole `gorm:"not null" json:"role"`
+}
+
+// CompanyInvitation invites whoever owns the email address to
+// join a company. Only the HMAC of the token is stored, the
+// token itself is emailed when the invitation is created.
+type CompanyInvitation struct {
+	gorm.Model
+	CompanyID   uint       `gorm:"not null;index" json:"companyId"`
+	Email       string     `gorm:"not null" json:"email"`
+	Role        MemberRole `gorm:"not null" json:"role"`
+	InvitedByID uint       `json:"invitedById"`
+	Token       string     `gorm:"-" json:"-"`
+	TokenHash   string     `gorm:"not null;unique_index" json:"-"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	AcceptedAt  *time.Time `json:"acceptedAt,omitempty"`
+}
+
+type CompanyService interface {
+	CompanyDB
+
+	// Authorize returns the membership of the user in the
+	// company, or ErrNotFound when the user is not a member and
+	// ErrMemberRoleForbidden when the role is not allowed.
+	Authorize(companyID, userID uint, allowed func(MemberRole) bool) (*CompanyMember, error)
+	// AcceptInvitation makes the user a member of the company
+	// the token invites to. The invitation must be pending and
+	// sent to the email address of the user.
+	AcceptInvitation(token string, user *User) (*CompanyMember, error)
+}
+
+type CompanyDB interface {
+	ByID(id uint) (*Company, error)
+	// ByUserID returns the companies the user is a member of
+	ByUserID(userID uint) ([]Company, error)
+	// Create creates the company with the user as its owner
+	Create(company *Company, ownerID uint) error
+
+	Member(companyID, userID uint) (*CompanyMember, error)
+	// Members returns the members of the company along with
+	// their user.
+	Members(companyID uint) ([]CompanyMember, error)
+	// SetMemberRole changes the role of an existing member, the
+	// last owner of a company can not be demoted.
+	SetMemberRole(member *CompanyMember) error
+	// RemoveMember removes the user from the company, the last
+	// owner of a company can not be removed.
+	RemoveMember(companyID, userID uint) error
+
+	// CreateInvitation creates the invitation, setting its
+	// Token when it was not provided.
+	CreateInvitation(invitation *CompanyInvitation) error
+	InvitationByToken(token string) (*CompanyInvitation, error)
+	// Invitations returns the pending invitations of the company
+	Invitations(companyID uint) ([]CompanyInvitation, error)
+	RevokeInvitation(companyID, id uint) error
+	// ClaimInvitation marks the invitation as accepted and adds
+	// the user to its company.
+	ClaimInvitation(invitation *CompanyInvitation, userID uint) (*CompanyMember, error)
+}
+
+func NewCompanyService(db *gorm.DB, hmacKey string) CompanyService {
+	return &companyService{
+		CompanyDB: &companyValidator{
+			CompanyDB:  &companyGorm{db},
+			hmac:       hash.NewHMAC(hmacKey),
+			emailRegex: regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,16}$`),
+		},
+	}
+}
+
+var _ CompanyService = &companyService{}
+
+type companyService struct {
+	CompanyDB
+}
+
+func (cs *companyService) Authorize(companyID, userID uint, allowed func(MemberRole) bool) (*CompanyMember, error) {
+	member, err := cs.Member(companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed(member.Role) {
+		return nil, ErrMemberRoleForbidden
+	}
+	return member, nil
+}
+
+func (cs *companyService) AcceptInvitation(token string, user *User) (*CompanyMember, error) {
+	invitation, err := cs.InvitationByToken(token)
+	if err == ErrNotFound {
+		return nil, ErrInvitationInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationInvalid
+	}
+	if !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	return cs.ClaimInvitation(invitation, user.ID)
+}
+
+type companyValidator struct {
+	CompanyDB
+	hmac       hash.HMAC
+	emailRegex *regexp.Regexp
+}
+
+func (cv *companyValidator) Create(company *Company, ownerID uint) error {
+	if ownerID <= 0 {
+		return ErrUserIDRequired
+	}
+	company.Name = strings.TrimSpace(company.Name)
+
+	return cv.CompanyDB.Create(company, ownerID)
+}
+
+func (cv *companyValidator) SetMemberRole(member *CompanyMember) error {
+	if member.CompanyID <= 0 || member.UserID <= 0 {
+		return ErrIDInvalid
+	}
+	if !member.Role.Valid() {
+		return ErrMemberRoleInvalid
+	}
+
+	return cv.CompanyDB.SetMemberRole(member)
+}
+
+func (cv *companyValidator) RemoveMember(companyID, userID uint) error {
+	if companyID <= 0 || userID <= 0 {
+		return ErrIDInvalid
+	}
+
+	return cv.CompanyDB.RemoveMember(companyID, userID)
+}
+
+func (cv *companyValidator) CreateInvitation(invitation *CompanyInvitation) error {
+	err := runCompanyInvitationValFuncs(invitation,
+		cv.companyIDRequired,
+		cv.normalizeEmail,
+		cv.emailFormat,
+		cv.roleValid,
+		cv.setTokenIfUnset,
+		cv.hmacToken,
+		cv.setExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	return cv.CompanyDB.CreateInvitation(invitation)
+}
+
+// InvitationByToken hashes the token before looking it up
+func (cv *companyValidator) InvitationByToken(token string) (*CompanyInvitation, error) {
+	if token == "" {
+		return nil, ErrNotFound
+	}
+
+	return cv.CompanyDB.InvitationByToken(cv.hmac.Hash(token))
+}
+
+func (cv *companyValidator) RevokeInvitation(companyID, id uint) error {
+	if companyID <= 0 || id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return cv.CompanyDB.RevokeInvitation(companyID, id)
+}
+
+func (cv *companyValidator) companyIDRequired(inv *CompanyInvitation) error {
+	if inv.CompanyID <= 0 {
+		return ErrIDInvalid
+	}
+	return nil
+}
+
+func (cv *companyValidator) normalizeEmail(inv *CompanyInvitation) error {
+	inv.Email = strings.ToLower(strings.TrimSpace(inv.Email))
+	return nil
+}
+
+func (cv *companyValidator) emailFormat(inv *CompanyInvitation) error {
+	if inv.Email == "" {
+		return ErrEmailRequired
+	}
+	if !cv.emailRegex.MatchString(inv.Email) {
+		return ErrEmailInvalid
+	}
+	return nil
+}
+
+func (cv *companyValidator) roleValid(inv *CompanyInvitation) error {
+	if !inv.Role.Valid() {
+		return ErrMemberRoleInvalid
+	}
+	return nil
+}
+
+func (cv *companyValidator) setTokenIfUnset(inv *CompanyInvitation) error {
+	if inv.Token != "" {
+		return nil
+	}
+	token, err := rand.RememberToken()
+	if err != nil {
+		return err
+	}
+	inv.Token = token
+	return nil
+}
+
+func (cv *companyValidator) hmacToken(inv *CompanyInvitation) error {
+	inv.TokenHash = cv.hmac.Hash(inv.Token)
+	return nil
+}
+
+func (cv *companyValidator) setExpiresAt(inv *CompanyInvitation) error {
+	inv.ExpiresAt = time.Now().Add(invitationTTL)
+	return nil
+}
+
+var _ CompanyDB = &companyGorm{}
+
+type companyGorm struct {
+	db *gorm.DB
+}
+
+func (cg *companyGorm) ByID(id uint) (*Company, error) {
+	var company Company
+	err := first(cg.db.Where("id = ?", id), &company)
+
+	return &company, err
+}
+
+func (cg *companyGorm) ByUserID(userID uint) ([]Company, error) {
+	var companies []Company
+	err := cg.db.
+		Joins("JOIN company_members ON company_members.company_id = companies.id").
+		Where("company_members.user_id = ?", userID).
+		Order("companies.id").
+		Preload("Members").
+		Find(&companies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return companies, nil
+}
+
+func (cg *companyGorm) Create(company *Company, ownerID uint) error {
+	return transaction(cg.db, func(tx *gorm.DB) error {
+		return createCompany(tx, company, ownerID)
+	})
+}
+
+func (cg *companyGorm) Member(companyID, userID uint) (*CompanyMember, error) {
+	var member CompanyMember
+	err := first(cg.db.Where("company_id = ? AND user_id = ?", companyID, userID), &member)
+
+	return &member, err
+}
+
+func (cg *companyGorm) Members(companyID uint) ([]CompanyMember, error) {
+	var members []CompanyMember
+	err := cg.db.Preload("User").Where("company_id = ?", companyID).Order("id").Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (cg *companyGorm) SetMemberRole(member *CompanyMember) error {
+	return transaction(cg.db, func(tx *gorm.DB) error {
+		var stored CompanyMember
+		err := first(tx.Where("company_id = ? AND user_id = ?", member.CompanyID, member.UserID), &stored)
+		if err != nil {
+			return err
+		}
+		if stored.Role == MemberOwner && member.Role != MemberOwner {
+			if err := requireAnotherOwner(tx, stored.CompanyID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&stored).Update("role", member.Role).Error; err != nil {
+			return err
+		}
+		*member = stored
+		return recordEvent(tx, EventCompanyMemberUpdated, aggregateCompany, member.CompanyID, member)
+	})
+}
+
+func (cg *companyGorm) RemoveMember(companyID, userID uint) error {
+	return transaction(cg.db, func(tx *gorm.DB) error {
+		var member CompanyMember
+		err := first(tx.Where("company_id = ? AND user_id = ?", companyID, userID), &member)
+		if err != nil {
+			return err
+		}
+		if member.Role == MemberOwner {
+			if err := requireAnotherOwner(tx, companyID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventCompanyMemberRemoved, aggregateCompany, companyID, member)
+	})
+}
+
+// CreateInvitation refuses to invite the members of the company
+func (cg *companyGorm) CreateInvitation(invitation *CompanyInvitation) error {
+	var count int
+	err := cg.db.Model(&CompanyMember{}).
+		Joins("JOIN users ON users.id = company_members.user_id AND users.deleted_at IS NULL").
+		Where("company_members.company_id = ? AND users.email = ?", invitation.CompanyID, invitation.Email).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrMemberExists
+	}
+
+	return cg.db.Create(invitation).Error
+}
+
+// InvitationByToken expects the token to already be hashed
+func (cg *companyGorm) InvitationByToken(tokenHash string) (*CompanyInvitation, error) {
+	var invitation CompanyInvitation
+	err := first(cg.db.Where("token_hash = ?", tokenHash), &invitation)
+
+	return &invitation, err
+}
+
+func (cg *companyGorm) Invitations(companyID uint) ([]CompanyInvitation, error) {
+	var invitations []CompanyInvitation
+	err := cg.db.
+		Where("company_id = ? AND accepted_at IS NULL AND expires_at > ?", companyID, time.Now()).
+		Order("id").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (cg *companyGorm) RevokeInvitation(companyID, id uint) error {
+	db := cg.db.Where("company_id = ? AND id = ? AND accepted_at IS NULL", companyID, id).
+		Delete(&CompanyInvitation{})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (cg *companyGorm) ClaimInvitation(invitation *CompanyInvitation, userID uint) (*CompanyMember, error) {
+	member := CompanyMember{
+		CompanyID: invitation.CompanyID,
+		UserID:    userID,
+		Role:      invitation.Role,
+	}
+	err := transaction(cg.db, func(tx *gorm.DB) error {
+		// The invitation can only be claimed once, even by
+		// concurrent requests.
+		db := tx.Model(&CompanyInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrInvitationInvalid
+		}
+		var count int
+		err := tx.Model(&CompanyMember{}).
+			Where("company_id = ? AND user_id = ?", member.CompanyID, userID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrMemberExists
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventCompanyMemberAdded, aggregateCompany, member.CompanyID, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// createCompany creates the company and its owner membership
+// within the transaction.
+func createCompany(tx *gorm.DB, company *Company, ownerID uint) error {
+	company.Members = nil
+	if err := tx.Create(company).Error; err != nil {
+		return err
+	}
+	owner := CompanyMember{
+		CompanyID: company.ID,
+		UserID:    ownerID,
+		Role:      MemberOwner,
+	}
+	if err := tx.Create(&owner).Error; err != nil {
+		return err
+	}
+	company.Members = []CompanyMember{owner}
+	return recordEvent(tx, EventCompanyCreated, aggregateCompany, company.ID, company)
+}
+
+func requireAnotherOwner(tx *gorm.DB, companyID uint) error {
+	var owners int
+	err := tx.Model(&CompanyMember{}).
+		Where("company_id = ? AND role = ?", companyID, MemberOwner).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// setProfileCompany creates the company of a company profile
+// that has none yet, owned by the user of the profile, and
+// moves the job posts of that user to it. The company of a
+// profile is otherwise kept named after the profile.
+func setProfileCompany(tx *gorm.DB, profile *CompanyProfile) error {
+	if profile.CompanyID != 0 {
+		return tx.Model(&Company{}).Where("id = ?", profile.CompanyID).
+			UpdateColumn("name", profile.CompanyName).Error
+	}
+	company := Company{Name: profile.CompanyName}
+	if err := createCompany(tx, &company, profile.UserID); err != nil {
+		return err
+	}
+	err := tx.Model(profile).UpdateColumn("company_id", company.ID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&JobPost{}).
+		Where("user_id = ? AND (company_id = 0 OR company_id IS NULL)", profile.UserID).
+		UpdateColumn("company_id", company.ID).Error
+}
+
+// setJobPostCompany attaches a job post created without a
+// company to the first company its author can post for.
+func setJobPostCompany(tx *gorm.DB, jobPost *JobPost) error {
+	if jobPost.CompanyID != 0 {
+		return nil
+	}
+	var member CompanyMember
+	err := tx.Where("user_id = ? AND role IN (?)", jobPost.UserID,
+		[]MemberRole{MemberOwner, MemberAdmin, MemberRecruiter}).
+		Order("id").First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	jobPost.CompanyID = member.CompanyID
+	return nil
+}
+
+// backfillCompanies creates the companies of the profiles that
+// existed before companies were introduced.
+func backfillCompanies(db *gorm.DB) error {
+	var profiles []CompanyProfile
+	if err := db.Where("company_id = 0 OR company_id IS NULL").Order("id").Find(&profiles).Error; err != nil {
+		return err
+	}
+	for i := range profiles {
+		err := transaction(db, func(tx *gorm.DB) error {
+			return setProfileCompany(tx, &profiles[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type companyInvitationValFunc func(*CompanyInvitation) error
+
+func runCompanyInvitationValFuncs(invitation *CompanyInvitation, fns ...companyInvitationValFunc) error {
+	for _, fn := range fns {
+		if err := fn(invitation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// EventType is the type of a DomainEvent
+type EventType string
+
+const (
+	EventJobPostCreated      EventType = "JobPostCreated"
+	EventJobPostUpdated      EventType = "JobPostUpdated"
+	EventJobPostPublished    EventType = "JobPostPublished"
+	EventJobPostClosed       EventType = "JobPostClosed"
+	EventJobPostDeleted      EventType = "JobPostDeleted"
+	EventJobPostRestored     EventType = "JobPostRestored"
+	EventJobPostSkillAdded   EventType = "JobPostSkillAdded"
+	EventJobPostSkillRemoved EventType = "JobPostSkillRemoved"
+
+	EventUserRegistered   EventType = "UserRegistered"
+	EventUserUpdated      EventType = "UserUpdated"
+	EventUserDeleted      EventType = "UserDeleted"
+	EventUserRestored     EventType = "UserRestored"
+	EventUserSkillAdded   EventType = "UserSkillAdded"
+	EventUserSkillRemoved EventType = "UserSkillRemoved"
+
+	EventCompanyProfileSkillAdded   EventType = "CompanyProfileSkillAdded"
+	EventCompanyProfileSkillRemoved EventType = "CompanyProfileSkillRemoved"
+	EventCompanyBenefitAdded        EventType = "CompanyBenefitAdded"
+	EventCompanyBenefitUpdated      EventType = "CompanyBenefitUpdated"
+	EventCompanyBenefitRemoved      EventType = "CompanyBenefitRemoved"
+	EventCompanyLogoUpdated         EventType = "CompanyLogoUpdated"
+	EventCompanyDomainVerified      EventType = "CompanyDomainVerified"
+
+	EventCompanyCreated       EventType = "CompanyCreated"
+	EventCompanyMemberAdded   EventType = "CompanyMemberAdded"
+	EventCompanyMemberUpdated EventType = "CompanyMemberUpdated"
+	EventCompanyMemberRemoved EventType = "CompanyMemberRemoved"
+
+	EventApplicationReceived EventType = "ApplicationReceived"
+)
+
+// The aggregates events are recorded for
+const (
+	aggregateJobPost        = "job_post"
+	aggregateUser           = "user"
+	aggregateCompanyProfile = "company_profile"
+	aggregateCompany        = "company"
+	aggregateApplication    = "application"
+)
+
+// maxEventAttempts is the number of times the subscribers of an
+// event are called before giving up on it.
+const maxEventAttempts = 10
+
+// DomainEvent is a change made to the models, written in the
+// same transaction as the change itself so no change goes
+// unnoticed by the subscribers of the events.
+type DomainEvent struct {
+	ID            uint      `gorm:"primary_key" json:"id"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Type          EventType `gorm:"not null;index" json:"type"`
+	AggregateType string    `gorm:"not null" json:"aggregateType"`
+	AggregateID   uint      `gorm:"not null" json:"aggregateId"`
+	// Payload is the JSON encoded state of the aggregate after
+	// the change, or one of the *EventPayload types.
+	Payload      string     `gorm:"type:text;not null" json:"payload"`
+	DispatchedAt *time.Time `gorm:"index" json:"dispatchedAt,omitempty"`
+	Attempts     uint       `json:"attempts"`
+	LastError    string     `json:"lastError,omitempty"`
+}
+
+// Decode decodes the payload of the event into dst
+func (e *DomainEvent) Decode(dst interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), dst)
+}
+
+// SkillEventPayload is the payload of the events adding or
+// removing a skill from its owner.
+type SkillEventPayload struct {
+	OwnerID uint `json:"ownerId"`
+	SkillID uint `json:"skillId"`
+}
+
+// DeletedEventPayload is the payload of the events deleting an
+// aggregate.
+type DeletedEventPayload struct {
+	ID uint `json:"id"`
+}
+
+type EventService interface {
+	EventDB
+}
+
+// EventDB is used by the event dispatcher to relay the events,
+// events are recorded along the changes by the other services.
+type EventDB interface {
+	// Pending returns the events still to be dispatched, oldest
+	// first.
+	Pending(limit int) ([]DomainEvent, error)
+	MarkDispatched(event *DomainEvent) error
+	MarkFailed(event *DomainEvent, cause error) error
+}
+
+func NewEventService(db *gorm.DB) EventService {
+	return &eventGorm{db}
+}
+
+var _ EventDB = &eventGorm{}
+
+type eventGorm struct {
+	db *gorm.DB
+}
+
+func (eg *eventGorm) Pending(limit int) ([]DomainEvent, error) {
+	var events []DomainEvent
+	err := eg.db.Where("dispatched_at IS NULL AND attempts < ?", maxEventAttempts).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (eg *eventGorm) MarkDispatched(event *DomainEvent) error {
+	now := time.Now()
+	event.Attempts++
+	event.DispatchedAt = &now
+	event.LastError = ""
+	return eg.db.Save(event).Error
+}
+
+func (eg *eventGorm) MarkFailed(event *DomainEvent, cause error) error {
+	event.Attempts++
+	event.LastError = cause.Error()
+	return eg.db.Save(event).Error
+}
+
+// recordEvent writes the event to the outbox, tx must be the
+// transaction making the change.
+func recordEvent(tx *gorm.DB, eventType EventType, aggregateType string, aggregateID uint, payload interface{}) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&DomainEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(b),
+	}).Error
+}
+
+// transaction runs fn in a database transaction, which is
+// committed if fn does not return an error.
+func transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
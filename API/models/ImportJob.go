@@ -0,0 +1,98 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// ImportStatus is the progress of an ImportJob
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportJob tracks a bulk import of job posts running in the
+// background. A dry run validates every row without creating
+// any job post. The job posts are created for CompanyID when it
+// is set.
+type ImportJob struct {
+	gorm.Model
+	UserID    uint             `gorm:"not null;index" json:"userId"`
+	CompanyID uint             `json:"companyId,omitempty"`
+	Format    string           `gorm:"not null" json:"format"`
+	DryRun    bool             `json:"dryRun"`
+	Status    ImportStatus     `gorm:"not null" json:"status"`
+	Total     int              `json:"total"`
+	Imported  int              `json:"imported"`
+	Failed    int              `json:"failed"`
+	Error     string           `json:"error,omitempty"`
+	Errors    []ImportRowError `gorm:"preload:false" json:"errors"`
+}
+
+// ImportRowError is the reason a row of an import was rejected
+type ImportRowError struct {
+	ID          uint   `gorm:"primary_key" json:"-"`
+	ImportJobID uint   `gorm:"not null;index" json:"-"`
+	Row         int    `gorm:"column:row_num" json:"row"`
+	Field       string `json:"field,omitempty"`
+	Message     string `json:"message"`
+}
+
+type ImportJobService interface {
+	ImportJobDB
+}
+
+type ImportJobDB interface {
+	// ByID returns the import job along with its row errors
+	ByID(id uint) (*ImportJob, error)
+	Create(job *ImportJob) error
+	// Update saves the job, including the row errors added
+	// since the last update.
+	Update(job *ImportJob) error
+}
+
+func NewImportJobService(db *gorm.DB) ImportJobService {
+	return &importJobValidator{
+		&importJobGorm{db},
+	}
+}
+
+type importJobValidator struct {
+	ImportJobDB
+}
+
+func (ijv *importJobValidator) Create(job *ImportJob) error {
+	if job.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	if job.Status == "" {
+		job.Status = ImportPending
+	}
+
+	return ijv.ImportJobDB.Create(job)
+}
+
+var _ ImportJobDB = &importJobGorm{}
+
+type importJobGorm struct {
+	db *gorm.DB
+}
+
+func (ijg *importJobGorm) ByID(id uint) (*ImportJob, error) {
+	var job ImportJob
+	db := ijg.db.Preload("Errors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("row_num")
+	}).Where("id = ?", id)
+	err := first(db, &job)
+
+	return &job, err
+}
+
+func (ijg *importJobGorm) Create(job *ImportJob) error {
+	return ijg.db.Create(job).Error
+}
+
+func (ijg *importJobGorm) Update(job *ImportJob) error {
+	return ijg.db.Save(job).Error
+}
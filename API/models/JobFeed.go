@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// JobFeed is an XML job feed published by a company, its
+// entries are kept in sync with the job posts of the company.
+// CategoryID and LocationID are used for the entries whose
+// category or location is not part of the catalog.
+type JobFeed struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;index" json:"userId"`
+	URL          string     `gorm:"not null" json:"url"`
+	CategoryID   uint       `gorm:"not null" json:"categoryId"`
+	LocationID   uint       `gorm:"not null" json:"locationId"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	LastCreated  int        `json:"lastCreated"`
+	LastUpdated  int        `json:"lastUpdated"`
+	LastClosed   int        `json:"lastClosed"`
+	LastRejected int        `json:"lastRejected"`
+}
+
+type JobFeedService interface {
+	JobFeedDB
+}
+
+type JobFeedDB interface {
+	ByID(id uint) (*JobFeed, error)
+	ByUserID(userID uint) ([]JobFeed, error)
+	FindAll() ([]JobFeed, error)
+	Create(feed *JobFeed) error
+	Update(feed *JobFeed) error
+	Delete(id uint) error
+}
+
+func NewJobFeedService(db *gorm.DB) JobFeedService {
+	return &jobFeedValidator{
+		&jobFeedGorm{db},
+	}
+}
+
+type jobFeedValidator struct {
+	JobFeedDB
+}
+
+func (jfv *jobFeedValidator) Create(feed *JobFeed) error {
+	err := runJobFeedValFuncs(feed,
+		jfv.userIDRequired,
+		jfv.urlValid,
+		jfv.defaultsRequired)
+	if err != nil {
+		return err
+	}
+
+	return jfv.JobFeedDB.Create(feed)
+}
+
+func (jfv *jobFeedValidator) Delete(id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return jfv.JobFeedDB.Delete(id)
+}
+
+func (jfv *jobFeedValidator) userIDRequired(feed *JobFeed) error {
+	if feed.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	return nil
+}
+
+func (jfv *jobFeedValidator) urlValid(feed *JobFeed) error {
+	u, ok := publicURL(feed.URL)
+	if !ok {
+		return ErrJobFeedURLInvalid
+	}
+	feed.URL = u.String()
+	return nil
+}
+
+func (jfv *jobFeedValidator) defaultsRequired(feed *JobFeed) error {
+	if feed.CategoryID <= 0 {
+		return ErrCategoryIDRequired
+	}
+	if feed.LocationID <= 0 {
+		return ErrLocationIDRequired
+	}
+	return nil
+}
+
+var _ JobFeedDB = &jobFeedGorm{}
+
+type jobFeedGorm struct {
+	db *gorm.DB
+}
+
+func (jfg *jobFeedGorm) ByID(id uint) (*JobFeed, error) {
+	var feed JobFeed
+	err := first(jfg.db.Where("id = ?", id), &feed)
+
+	return &feed, err
+}
+
+func (jfg *jobFeedGorm) ByUserID(userID uint) ([]JobFeed, error) {
+	var feeds []JobFeed
+	err := jfg.db.Where("user_id = ?", userID).Find(&feeds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+func (jfg *jobFeedGorm) FindAll() ([]JobFeed, error) {
+	var feeds []JobFeed
+	err := jfg.db.Find(&feeds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+func (jfg *jobFeedGorm) Create(feed *JobFeed) error {
+	return jfg.db.Create(feed).Error
+}
+
+func (jfg *jobFeedGorm) Update(feed *JobFeed) error {
+	return jfg.db.Save(feed).Error
+}
+
+func (jfg *jobFeedGorm) Delete(id uint) error {
+	feed := JobFeed{Model: gorm.Model{ID: id}}
+	return jfg.db.Delete(&feed).Error
+}
+
+type jobFeedValFunc func(*JobFeed) error
+
+func runJobFeedValFuncs(feed *JobFeed, fns ...jobFeedValFunc) error {
+	for _, fn := range fns {
+		if err := fn(feed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
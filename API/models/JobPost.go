@@ -4,30 +4,140 @@ import (
 	"fmt"
 	"github.com/jinzhu/gorm"
 	"strings"
+	"time"
 )
 
 // JobPost represents a job post
 type JobPost struct {
 	gorm.Model
-	UserID      uint      `gorm:"not_null" json:"userId"`
-	Title       string    `gorm:"not_null" json:"title"`
-	Location    *Location `json:"location,omitempty"`
-	LocationID  uint      `gorm:"not_null" json:"locationId"`
-	Category    *Category `json:"category,omitempty"`
-	CategoryID  uint      `gorm:"not_null" json:"categoryId"`
-	Description string    `gorm:"not_null" json:"description"`
-	ApplyAt     string    `gorm:"not_null" json:"applyAt"`
-	Skills      []Skill   `gorm:"many2many:job_post_skills;" json:"skills,omitempty"`
+	UserID      uint       `gorm:"not_null" json:"userId"`
+	CompanyID   uint       `gorm:"index" json:"companyId,omitempty"`
+	Title       string     `gorm:"not_null" json:"title"`
+	Slug        string     `gorm:"index" json:"slug,omitempty"`
+	Location    *Location  `json:"location,omitempty"`
+	LocationID  uint       `gorm:"not_null" json:"locationId"`
+	Category    *Category  `json:"category,omitempty"`
+	CategoryID  uint       `gorm:"not_null" json:"categoryId"`
+	Description string     `gorm:"not_null" json:"description"`
+	ApplyAt     string     `gorm:"not_null" json:"applyAt"`
+	Skills      []Skill    `gorm:"many2many:job_post_skills;" json:"skills,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	ClosedAt    *time.Time `json:"closedAt,omitempty"`
+	// Version goes up with every change, Update fails with
+	// ErrVersionConflict unless it is the version stored.
+	Version uint `gorm:"not null;default:1" json:"version"`
+	// ValidThrough is when the job post stops being listed
+	ValidThrough *time.Time `json:"validThrough,omitempty"`
+	// AnnouncedAt is when the JobPostPublished event was recorded,
+	// job posts scheduled for later are announced once their
+	// publication date is reached.
+	AnnouncedAt *time.Time `gorm:"index" json:"-"`
+	// The salary is either a range or, when SalaryMax is zero,
+	// the single SalaryMin value, paid every SalaryPeriod.
+	SalaryMin      uint         `json:"salaryMin,omitempty"`
+	SalaryMax      uint         `json:"salaryMax,omitempty"`
+	SalaryCurrency string       `json:"salaryCurrency,omitempty"`
+	SalaryPeriod   SalaryPeriod `json:"salaryPeriod,omitempty"`
+	// JobFeedID and ExternalRef identify the entry of the job
+	// feed the job post was imported from, they are unique
+	// among the imported job posts.
+	JobFeedID   uint   `gorm:"index" json:"jobFeedId,omitempty"`
+	ExternalRef string `json:"externalRef,omitempty"`
+	// SkillRequirements are managed through AddSkill and
+	// SetSkillRequirement,
+	// as they share the job_post_skills table with Skills.
+	SkillRequirements []JobPostSkill `gorm:"foreignkey:JobPostID;save_associations:false" json:"skillRequirements,omitempty"`
+	// Bookmarked and Applied tell whether the user making the
+	// request bookmarked or applied to the job post, they are
+	// only set by SetUserStatus.
+	Bookmarked *bool `gorm:"-" json:"bookmarked,omitempty"`
+	Applied    *bool `gorm:"-" json:"applied,omitempty"`
+	// CompanyVerified tells whether the company of the job post
+	// verified its domain, it is only set by SetCompanyVerified.
+	CompanyVerified *bool `gorm:"-" json:"companyVerified,omitempty"`
+	// Company is the profile of the company of the job post, or
+	// of its author without a company, it is only set by
+	// ByIDWith.
+	Company *CompanyProfile `gorm:"-" json:"company,omitempty"`
+	// EditedBy is the user making a change, recorded as the
+	// author of the revision.
+	EditedBy uint `gorm:"-" json:"-"`
+}
+
+// SalaryPeriod is the period a salary is paid for, using the
+// unit names of schema.org.
+type SalaryPeriod string
+
+const (
+	SalaryHour  SalaryPeriod = "HOUR"
+	SalaryDay   SalaryPeriod = "DAY"
+	SalaryWeek  SalaryPeriod = "WEEK"
+	SalaryMonth SalaryPeriod = "MONTH"
+	SalaryYear  SalaryPeriod = "YEAR"
+)
+
+func (sp SalaryPeriod) Valid() bool {
+	switch sp {
+	case SalaryHour, SalaryDay, SalaryWeek, SalaryMonth, SalaryYear:
+		return true
+	}
+	return false
+}
+
+// HasSalary reports whether the salary of the job post is known
+func (jp *JobPost) HasSalary() bool {
+	return jp.SalaryMin > 0 || jp.SalaryMax > 0
+}
+
+// IsListed reports whether the job post is published, open and
+// not expired at now. The job posts are queried with the same
+// conditions by listed.
+func (jp *JobPost) IsListed(now time.Time) bool {
+	if jp.ClosedAt != nil || jp.PublishedAt == nil || jp.PublishedAt.After(now) {
+		return false
+	}
+	return jp.ValidThrough == nil || jp.ValidThrough.After(now)
 }
 
 type JobPostService interface {
 	JobPostDB
+
+	// Recommend ranks the published job posts sharing skills
+	// with the candidate, as described by RankJobPosts, and
+	// returns at most limit of them.
+	Recommend(candidate *User, locationID uint, limit int) ([]JobRecommendation, error)
 }
 
 type jobPostService struct {
 	JobPostDB
 }
 
+func (jps *jobPostService) Recommend(candidate *User, locationID uint, limit int) ([]JobRecommendation, error) {
+	if len(candidate.Skills) == 0 {
+		return []JobRecommendation{}, nil
+	}
+	found, err := jps.FindAll(JobPost{Skills: candidate.Skills})
+	if err != nil {
+		return nil, err
+	}
+
+	// Posts matching several skills are returned once per skill
+	var jobPosts []JobPost
+	seen := map[uint]bool{}
+	for _, jp := range found {
+		if !seen[jp.ID] {
+			seen[jp.ID] = true
+			jobPosts = append(jobPosts, jp)
+		}
+	}
+
+	recommendations := RankJobPosts(jobPosts, candidate.Skills, locationID, time.Now())
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations, nil
+}
+
 func NewJobPostService(db *gorm.DB) JobPostService {
 	return &jobPostService{
 		JobPostDB: &jobPostValidator{
@@ -39,10 +149,72 @@ func NewJobPostService(db *gorm.DB) JobPostService {
 type JobPostDB interface {
 	FindAll(filters JobPost) ([]JobPost, error)
 	ByUserID(id uint) ([]JobPost, error)
+	ByCompanyID(id uint) ([]JobPost, error)
 	ByID(id uint) (*JobPost, error)
+	// ByIDWith returns the job post with its location, category,
+	// skills and company, and the associations in include:
+	// skillRequirements, company.benefits and company.skills.
+	ByIDWith(id uint, include ...string) (*JobPost, error)
+	// ByJobFeedID returns every job post imported from the feed,
+	// including the closed and deleted ones.
+	ByJobFeedID(feedID uint) ([]JobPost, error)
+	// BySlug returns the job post with its location, category
+	// and skills. Slugs are assigned on creation and never
+	// change.
+	BySlug(slug string) (*JobPost, error)
+	// Listed returns every job post currently listed, without
+	// their associations.
+	Listed(now time.Time) ([]JobPost, error)
+	// Create also attaches the Skills of the job post, which
+	// must be resolved to their IDs, in the same transaction.
 	Create(jobPost *JobPost) error
+	// Validate runs the checks of Create without creating the
+	// job post.
+	Validate(jobPost *JobPost) error
 	Update(jobPost *JobPost) error
 	Delete(id uint) error
+	// Deleted returns the deleted job posts of the user without
+	// a company and of the companies provided, most recently
+	// deleted first.
+	Deleted(userID uint, companyIDs []uint) ([]JobPost, error)
+	// DeletedByID returns the job post only if it is deleted
+	DeletedByID(id uint) (*JobPost, error)
+	// Restore undoes the Delete of the job post
+	Restore(id uint) error
+	// Purge permanently deletes the deleted job post along with
+	// its skills, bookmarks, revisions and applications.
+	Purge(id uint) error
+	// PurgeDeleted purges the job posts deleted before the time
+	// provided and returns how many there were.
+	PurgeDeleted(before time.Time) (int, error)
+	// AnnounceDue announces the job posts listed since their
+	// publication date was reached, and returns how many there
+	// were.
+	AnnounceDue(now time.Time) (int, error)
+	// Close stops the job post from being listed while keeping
+	// it available to its owner.
+	Close(id uint) error
+	// AddSkill attaches the skill to the job post along with its
+	// requirements, or updates them when the skill is already
+	// attached, as a single revision.
+	AddSkill(req *JobPostSkill) error
+	// SetSkillRequirement updates the requirements on a skill
+	// already attached to the job post, as a new revision when
+	// they change.
+	SetSkillRequirement(req *JobPostSkill) error
+	// Revisions returns the revisions of the job post, oldest
+	// first, along with their changes.
+	Revisions(jobPostID uint) ([]JobPostRevision, error)
+	Revision(jobPostID, number uint) (*JobPostRevision, error)
+	// RestoreRevision sets the fields and skills of the job post
+	// back to the ones of the revision, as a new revision.
+	RestoreRevision(jobPost *JobPost, revision *JobPostRevision) error
+	// SetUserStatus sets whether the user bookmarked or applied
+	// to each of the job posts, using a single query.
+	SetUserStatus(userID uint, jobPosts []JobPost) error
+	// SetCompanyVerified sets whether the company of each of
+	// the job posts verified its domain, using a single query.
+	SetCompanyVerified(jobPosts []JobPost) error
 }
 
 type jobPostValidator struct {
@@ -51,8 +223,7 @@ type jobPostValidator struct {
 
 func (jpv *jobPostValidator) Create(jobPost *JobPost) error {
 
-	err := runJobPostValFuncs(
-		jobPost, jpv.userIDRequired, jpv.titleRequired, jpv.locationIDRequired, jpv.categoryIDRequired, jpv.descriptionRequired, jpv.applyAtRequired)
+	err := jpv.Validate(jobPost)
 
 	if err != nil {
 		return err
@@ -60,16 +231,32 @@ func (jpv *jobPostValidator) Create(jobPost *JobPost) error {
 	return jpv.JobPostDB.Create(jobPost)
 }
 
+func (jpv *jobPostValidator) Validate(jobPost *JobPost) error {
+	return runJobPostValFuncs(
+		jobPost, jpv.userIDRequired, jpv.titleRequired, jpv.locationIDRequired, jpv.categoryIDRequired, jpv.descriptionRequired, jpv.applyAtRequired, jpv.salaryValid, jpv.setPublishedAt, jpv.validThroughValid)
+}
+
 func (jpv *jobPostValidator) Update(jobPost *JobPost) error {
 
 	err := runJobPostValFuncs(
-		jobPost, jpv.userIDRequired, jpv.titleRequired, jpv.locationIDRequired, jpv.categoryIDRequired, jpv.descriptionRequired, jpv.applyAtRequired)
+		jobPost, jpv.userIDRequired, jpv.titleRequired, jpv.locationIDRequired, jpv.categoryIDRequired, jpv.descriptionRequired, jpv.applyAtRequired, jpv.salaryValid, jpv.validThroughValid)
 	if err != nil {
 		return err
 	}
 	return jpv.JobPostDB.Update(jobPost)
 }
 
+func (jpv *jobPostValidator) RestoreRevision(jobPost *JobPost, revision *JobPostRevision) error {
+	restored := *jobPost
+	revision.JobPost.Apply(&restored)
+	err := runJobPostValFuncs(
+		&restored, jpv.userIDRequired, jpv.titleRequired, jpv.locationIDRequired, jpv.categoryIDRequired, jpv.descriptionRequired, jpv.applyAtRequired, jpv.salaryValid, jpv.validThroughValid)
+	if err != nil {
+		return err
+	}
+	return jpv.JobPostDB.RestoreRevision(jobPost, revision)
+}
+
 func (jpv *jobPostValidator) Delete(id uint) error {
 
 	if id <= 0 {
@@ -79,6 +266,37 @@ func (jpv *jobPostValidator) Delete(id uint) error {
 	return jpv.JobPostDB.Delete(id)
 }
 
+func (jpv *jobPostValidator) Close(id uint) error {
+
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return jpv.JobPostDB.Close(id)
+}
+
+func (jpv *jobPostValidator) AddSkill(req *JobPostSkill) error {
+	if req.JobPostID <= 0 || req.SkillID <= 0 {
+		return ErrIDInvalid
+	}
+	if !req.MinProficiency.Valid() {
+		return ErrProficiencyInvalid
+	}
+
+	return jpv.JobPostDB.AddSkill(req)
+}
+
+func (jpv *jobPostValidator) SetSkillRequirement(req *JobPostSkill) error {
+	if req.JobPostID <= 0 || req.SkillID <= 0 {
+		return ErrIDInvalid
+	}
+	if !req.MinProficiency.Valid() {
+		return ErrProficiencyInvalid
+	}
+
+	return jpv.JobPostDB.SetSkillRequirement(req)
+}
+
 func (jpv *jobPostValidator) userIDRequired(jp *JobPost) error {
 	if jp.UserID <= 0 {
 		return ErrUserIDRequired
@@ -126,6 +344,48 @@ func (jpv *jobPostValidator) applyAtRequired(jp *JobPost) error {
 	return nil
 }
 
+// salaryValid normalizes the currency and requires a currency
+// and a period whenever an amount is provided.
+func (jpv *jobPostValidator) salaryValid(jp *JobPost) error {
+	jp.SalaryCurrency = strings.ToUpper(strings.TrimSpace(jp.SalaryCurrency))
+	if !jp.HasSalary() {
+		if jp.SalaryCurrency != "" || jp.SalaryPeriod != "" {
+			return ErrSalaryInvalid
+		}
+		return nil
+	}
+	if jp.SalaryMax > 0 && jp.SalaryMin > jp.SalaryMax {
+		return ErrSalaryInvalid
+	}
+	if len(jp.SalaryCurrency) != 3 || strings.Trim(jp.SalaryCurrency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return ErrSalaryInvalid
+	}
+	if !jp.SalaryPeriod.Valid() {
+		return ErrSalaryInvalid
+	}
+
+	return nil
+}
+
+func (jpv *jobPostValidator) validThroughValid(jp *JobPost) error {
+	if jp.ValidThrough != nil && jp.PublishedAt != nil && !jp.ValidThrough.After(*jp.PublishedAt) {
+		return ErrValidThroughInvalid
+	}
+
+	return nil
+}
+
+// setPublishedAt publishes the job post as soon as it is
+// created, unless a publication date was already provided.
+func (jpv *jobPostValidator) setPublishedAt(jp *JobPost) error {
+	if jp.PublishedAt == nil {
+		now := time.Now()
+		jp.PublishedAt = &now
+	}
+
+	return nil
+}
+
 var _ JobPostDB = &jobPostGorm{}
 
 type jobPostGorm struct {
@@ -134,7 +394,7 @@ type jobPostGorm struct {
 
 func (jpg *jobPostGorm) FindAll(filters JobPost) ([]JobPost, error) {
 	var jobPosts []JobPost
-	db := jpg.db.Set("gorm:auto_preload", true)
+	db := listed(jpg.db.Set("gorm:auto_preload", true), time.Now())
 	db = db.Where("UPPER(title) LIKE ?", fmt.Sprintf("%%%s%%", strings.ToUpper(filters.Title)))
 	filters.Title = ""
 	if len(filters.Skills) != 0 {
@@ -145,6 +405,12 @@ func (jpg *jobPostGorm) FindAll(filters JobPost) ([]JobPost, error) {
 		db = db.Joins("JOIN job_post_skills ON job_post_skills.job_post_id = job_posts.id AND job_post_skills.skill_id IN (?)", skillIds)
 		filters.Skills = nil
 	}
+	// Every skill requirement provided must be a must-have of
+	// the job post
+	for _, req := range filters.SkillRequirements {
+		db = db.Where("EXISTS (SELECT 1 FROM job_post_skills jps WHERE jps.job_post_id = job_posts.id AND jps.skill_id = ? AND jps.required)", req.SkillID)
+	}
+	filters.SkillRequirements = nil
 
 	err := db.Where(filters).Find(&jobPosts).Error
 
@@ -154,16 +420,394 @@ func (jpg *jobPostGorm) FindAll(filters JobPost) ([]JobPost, error) {
 // Create will create the provided jobPost and backfill data
 // like the ID, CreatedAt, and UpdatedAt fields.
 func (jpg *jobPostGorm) Create(jobPost *JobPost) error {
-	return jpg.db.Set("gorm:association_autoupdate", false).Create(jobPost).Error
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		jobPost.Slug = ""
+		jobPost.Version = 1
+		jobPost.AnnouncedAt = nil
+		if err := setJobPostCompany(tx, jobPost); err != nil {
+			return err
+		}
+		skills := jobPost.Skills
+		jobPost.Skills = nil
+		err := tx.Set("gorm:association_autoupdate", false).Create(jobPost).Error
+		if err != nil {
+			return err
+		}
+		if err := setJobPostSlug(tx, jobPost); err != nil {
+			return err
+		}
+		for _, skill := range skills {
+			if err := tx.Model(jobPost).Association("Skills").Append(skill).Error; err != nil {
+				return err
+			}
+			if err := recordSkillEvent(tx, jobPost, skill, true); err != nil {
+				return err
+			}
+		}
+		if err := recordJobPostRevision(tx, jobPost.ID, jobPost.EditedBy); err != nil {
+			return err
+		}
+		err = recordEvent(tx, EventJobPostCreated, aggregateJobPost, jobPost.ID, jobPost)
+		if err != nil {
+			return err
+		}
+		return announceJobPost(tx, jobPost, time.Now())
+	})
+}
+
+// listed restricts db to the job posts listed at now, the same
+// as IsListed does, scheduled job posts are only found once they
+// are published.
+func listed(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("job_posts.closed_at IS NULL AND job_posts.published_at <= ?", now).
+		Where("job_posts.valid_through IS NULL OR job_posts.valid_through > ?", now)
+}
+
+// announceJobPost records the JobPostPublished event of the job
+// post if it is listed and was not announced yet.
+func announceJobPost(tx *gorm.DB, jobPost *JobPost, now time.Time) error {
+	db := listed(tx.Model(&JobPost{}), now).
+		Where("id = ? AND announced_at IS NULL", jobPost.ID).
+		UpdateColumn("announced_at", now)
+	if db.Error != nil || db.RowsAffected == 0 {
+		return db.Error
+	}
+	jobPost.AnnouncedAt = &now
+	return recordEvent(tx, EventJobPostPublished, aggregateJobPost, jobPost.ID, jobPost)
+}
+
+func (jpg *jobPostGorm) AnnounceDue(now time.Time) (int, error) {
+	var ids []uint
+	err := listed(jpg.db.Model(&JobPost{}), now).
+		Where("announced_at IS NULL").
+		Order("published_at").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		err := transaction(jpg.db, func(tx *gorm.DB) error {
+			var jobPost JobPost
+			if err := first(tx.Where("id = ?", id), &jobPost); err != nil {
+				return err
+			}
+			return announceJobPost(tx, &jobPost, now)
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// Validate has nothing to check in the database
+func (jpg *jobPostGorm) Validate(jobPost *JobPost) error {
+	return nil
 }
 
 func (jpg *jobPostGorm) Update(jobPost *JobPost) error {
-	return jpg.db.Save(jobPost).Error
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		// Job posts keep their slug and can not be moved to
+		// another company
+		var stored JobPost
+		if err := tx.Select("slug, company_id").Where("id = ?", jobPost.ID).First(&stored).Error; err != nil {
+			return err
+		}
+		jobPost.Slug = stored.Slug
+		jobPost.CompanyID = stored.CompanyID
+		if err := bumpVersion(tx, "job_posts", jobPost.ID, jobPost.Version); err != nil {
+			return err
+		}
+		jobPost.Version++
+		// The announcement is only ever set by announceJobPost
+		if err := tx.Omit("announced_at").Save(jobPost).Error; err != nil {
+			return err
+		}
+		if err := setJobPostSlug(tx, jobPost); err != nil {
+			return err
+		}
+		if err := recordJobPostRevision(tx, jobPost.ID, jobPost.EditedBy); err != nil {
+			return err
+		}
+		if err := recordEvent(tx, EventJobPostUpdated, aggregateJobPost, jobPost.ID, jobPost); err != nil {
+			return err
+		}
+		return announceJobPost(tx, jobPost, time.Now())
+	})
+}
+
+func (jpg *jobPostGorm) Close(id uint) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		var jobPost JobPost
+		if err := first(tx.Where("id = ?", id), &jobPost); err != nil {
+			return err
+		}
+		if err := bumpVersion(tx, "job_posts", jobPost.ID, jobPost.Version); err != nil {
+			return err
+		}
+		jobPost.Version++
+		if err := tx.Model(&jobPost).Update("closed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventJobPostClosed, aggregateJobPost, jobPost.ID, jobPost)
+	})
+}
+
+func (jpg *jobPostGorm) AddSkill(req *JobPostSkill) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		var skill Skill
+		err := first(tx.Where("id = ?", req.SkillID), &skill)
+		if err == ErrNotFound {
+			return ErrSkillUnknown
+		}
+		if err != nil {
+			return err
+		}
+		var stored JobPostSkill
+		err = first(tx.Where("job_post_id = ? AND skill_id = ?", req.JobPostID, req.SkillID), &stored)
+		switch err {
+		case ErrNotFound:
+			// Create would leave Required to its default when false
+			err := tx.Exec(`INSERT INTO job_post_skills (job_post_id, skill_id, required, min_proficiency, min_years)
+				VALUES (?, ?, ?, ?, ?)`, req.JobPostID, req.SkillID, req.Required, req.MinProficiency, req.MinYears).Error
+			if err != nil {
+				return err
+			}
+			jobPost := JobPost{}
+			jobPost.ID = req.JobPostID
+			if err := recordSkillEvent(tx, &jobPost, skill, true); err != nil {
+				return err
+			}
+		case nil:
+			if stored.Required == req.Required && stored.MinProficiency == req.MinProficiency && stored.MinYears == req.MinYears {
+				return nil
+			}
+			err := tx.Model(&JobPostSkill{}).
+				Where("job_post_id = ? AND skill_id = ?", req.JobPostID, req.SkillID).
+				Updates(map[string]interface{}{
+					"required":        req.Required,
+					"min_proficiency": req.MinProficiency,
+					"min_years":       req.MinYears,
+				}).Error
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		if _, err := incrementVersion(tx, "job_posts", req.JobPostID); err != nil {
+			return err
+		}
+		return recordJobPostRevision(tx, req.JobPostID, req.EditedBy)
+	})
+}
+
+func (jpg *jobPostGorm) SetSkillRequirement(req *JobPostSkill) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		var stored JobPostSkill
+		db := tx.Where("job_post_id = ? AND skill_id = ?", req.JobPostID, req.SkillID)
+		if err := first(db, &stored); err != nil {
+			return err
+		}
+		if stored.Required == req.Required && stored.MinProficiency == req.MinProficiency && stored.MinYears == req.MinYears {
+			return nil
+		}
+		err := tx.Model(&JobPostSkill{}).
+			Where("job_post_id = ? AND skill_id = ?", req.JobPostID, req.SkillID).
+			Updates(map[string]interface{}{
+				"required":        req.Required,
+				"min_proficiency": req.MinProficiency,
+				"min_years":       req.MinYears,
+			}).Error
+		if err != nil {
+			return err
+		}
+		if _, err := incrementVersion(tx, "job_posts", req.JobPostID); err != nil {
+			return err
+		}
+		return recordJobPostRevision(tx, req.JobPostID, req.EditedBy)
+	})
+}
+
+func (jpg *jobPostGorm) SetUserStatus(userID uint, jobPosts []JobPost) error {
+	if len(jobPosts) == 0 {
+		return nil
+	}
+	var ids []uint
+	for _, jp := range jobPosts {
+		ids = append(ids, jp.ID)
+	}
+
+	rows, err := jpg.db.Raw(`
+		SELECT job_post_id, 'bookmarked' FROM bookmarks
+		WHERE user_id = ? AND job_post_id IN (?) AND deleted_at IS NULL
+		UNION ALL
+		SELECT job_post_id, 'applied' FROM applications
+		WHERE user_id = ? AND job_post_id IN (?) AND deleted_at IS NULL`,
+		userID, ids, userID, ids).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	bookmarked := map[uint]bool{}
+	applied := map[uint]bool{}
+	for rows.Next() {
+		var jobPostID uint
+		var kind string
+		if err := rows.Scan(&jobPostID, &kind); err != nil {
+			return err
+		}
+		if kind == "bookmarked" {
+			bookmarked[jobPostID] = true
+		} else {
+			applied[jobPostID] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range jobPosts {
+		isBookmarked := bookmarked[jobPosts[i].ID]
+		isApplied := applied[jobPosts[i].ID]
+		jobPosts[i].Bookmarked = &isBookmarked
+		jobPosts[i].Applied = &isApplied
+	}
+	return nil
+}
+
+func (jpg *jobPostGorm) SetCompanyVerified(jobPosts []JobPost) error {
+	var companyIDs []uint
+	for _, jp := range jobPosts {
+		if jp.CompanyID != 0 {
+			companyIDs = append(companyIDs, jp.CompanyID)
+		}
+	}
+	verified := map[uint]bool{}
+	if len(companyIDs) > 0 {
+		var profiles []CompanyProfile
+		err := jpg.db.Select("company_id, verified_at").
+			Where("company_id IN (?) AND verified_at IS NOT NULL", companyIDs).
+			Find(&profiles).Error
+		if err != nil {
+			return err
+		}
+		for _, profile := range profiles {
+			verified[profile.CompanyID] = true
+		}
+	}
+
+	for i := range jobPosts {
+		isVerified := verified[jobPosts[i].CompanyID]
+		jobPosts[i].CompanyVerified = &isVerified
+	}
+	return nil
 }
 
 func (jpg *jobPostGorm) Delete(id uint) error {
-	jobPost := JobPost{Model: gorm.Model{ID: id}}
-	return jpg.db.Delete(&jobPost).Error
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		jobPost := JobPost{Model: gorm.Model{ID: id}}
+		if err := tx.Delete(&jobPost).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventJobPostDeleted, aggregateJobPost, id, DeletedEventPayload{ID: id})
+	})
+}
+
+func (jpg *jobPostGorm) Deleted(userID uint, companyIDs []uint) ([]JobPost, error) {
+	var jobPosts []JobPost
+	db := jpg.db.Unscoped().Where("deleted_at IS NOT NULL")
+	if len(companyIDs) > 0 {
+		db = db.Where("((company_id IS NULL OR company_id = 0) AND user_id = ?) OR company_id IN (?)", userID, companyIDs)
+	} else {
+		db = db.Where("(company_id IS NULL OR company_id = 0) AND user_id = ?", userID)
+	}
+	err := db.Order("deleted_at DESC").Find(&jobPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobPosts, nil
+}
+
+func (jpg *jobPostGorm) DeletedByID(id uint) (*JobPost, error) {
+	var jobPost JobPost
+	db := jpg.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id)
+	err := first(db, &jobPost)
+
+	return &jobPost, err
+}
+
+func (jpg *jobPostGorm) Restore(id uint) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		db := tx.Unscoped().Model(&JobPost{}).Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			})
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		var jobPost JobPost
+		if err := first(tx.Where("id = ?", id), &jobPost); err != nil {
+			return err
+		}
+		return recordEvent(tx, EventJobPostRestored, aggregateJobPost, id, jobPost)
+	})
+}
+
+func (jpg *jobPostGorm) Purge(id uint) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		var jobPost JobPost
+		if err := first(tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id), &jobPost); err != nil {
+			return err
+		}
+		return purgeJobPosts(tx, []uint{id})
+	})
+}
+
+func (jpg *jobPostGorm) PurgeDeleted(before time.Time) (int, error) {
+	var ids []uint
+	err := transaction(jpg.db, func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&JobPost{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		return purgeJobPosts(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purgeJobPosts permanently deletes the job posts along with
+// their skills, bookmarks, revisions, applications and the
+// alerts sent about them.
+func purgeJobPosts(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("job_post_id IN (?)", ids).Delete(&JobPostSkill{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("job_post_id IN (?)", ids).Delete(&Bookmark{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("job_post_id IN (?)", ids).Delete(&JobPostRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("job_post_id IN (?)", ids).Delete(&SentAlert{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("job_post_id IN (?)", ids).Delete(&Application{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN (?)", ids).Delete(&JobPost{}).Error
 }
 
 func (jpg *jobPostGorm) ByID(id uint) (*JobPost, error) {
@@ -175,6 +819,50 @@ func (jpg *jobPostGorm) ByID(id uint) (*JobPost, error) {
 
 }
 
+func (jpg *jobPostGorm) ByIDWith(id uint, include ...string) (*JobPost, error) {
+	db := jpg.db.Preload("Location").Preload("Category").Preload("Skills")
+	profileDB := jpg.db
+	for _, name := range include {
+		switch name {
+		case "skillRequirements":
+			db = db.Preload("SkillRequirements")
+		case "company.benefits":
+			profileDB = profileDB.Preload("CompanyBenefits")
+		case "company.skills":
+			profileDB = profileDB.Preload("Skills")
+		default:
+			return nil, ErrIncludeInvalid
+		}
+	}
+	var jobPost JobPost
+	if err := first(db.Where("id = ?", id), &jobPost); err != nil {
+		return nil, err
+	}
+
+	if jobPost.CompanyID != 0 {
+		profileDB = profileDB.Where("company_id = ?", jobPost.CompanyID)
+	} else {
+		profileDB = profileDB.Where("user_id = ?", jobPost.UserID)
+	}
+	var profile CompanyProfile
+	switch err := first(profileDB, &profile); err {
+	case nil:
+		jobPost.Company = &profile
+	case ErrNotFound:
+	default:
+		return nil, err
+	}
+	return &jobPost, nil
+}
+
+func (jpg *jobPostGorm) BySlug(slug string) (*JobPost, error) {
+	var jobPost JobPost
+	db := jpg.db.Set("gorm:auto_preload", true).Where("slug = ?", slug)
+	err := first(db, &jobPost)
+
+	return &jobPost, err
+}
+
 func (jpg *jobPostGorm) ByUserID(id uint) ([]JobPost, error) {
 	var jobPosts []JobPost
 	err := jpg.db.Where("user_id = ?", id).Find(&jobPosts).Error
@@ -185,14 +873,50 @@ func (jpg *jobPostGorm) ByUserID(id uint) ([]JobPost, error) {
 	return jobPosts, nil
 }
 
+func (jpg *jobPostGorm) ByCompanyID(id uint) ([]JobPost, error) {
+	var jobPosts []JobPost
+	err := jpg.db.Where("company_id = ?", id).Find(&jobPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobPosts, nil
+}
+
+func (jpg *jobPostGorm) Listed(now time.Time) ([]JobPost, error) {
+	var jobPosts []JobPost
+	err := listed(jpg.db, now).
+		Order("id").
+		Find(&jobPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobPosts, nil
+}
+
+func (jpg *jobPostGorm) ByJobFeedID(feedID uint) ([]JobPost, error) {
+	var jobPosts []JobPost
+	err := jpg.db.Unscoped().Where("job_feed_id = ?", feedID).Find(&jobPosts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return jobPosts, nil
+}
+
 type jobPostValFunc func(*JobPost) error
 
+// runJobPostValFuncs runs every function, gathering the field
+// errors in a ValidationError. Any other error is returned
+// right away.
 func runJobPostValFuncs(jobPost *JobPost, fns ...jobPostValFunc) error {
+	var verr ValidationError
 	for _, fn := range fns {
-		if err := fn(jobPost); err != nil {
+		if err := fn(jobPost); err != nil && !verr.add(err) {
 			return err
 		}
 	}
 
-	return nil
+	return verr.err()
 }
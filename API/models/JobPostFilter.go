@@ -0,0 +1,133 @@
+package models
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseJobPostFilter builds the filters used by JobPostDB.FindAll
+// out of the GET /jobs query parameters:
+//
+//	q   text the title must contain
+//	u   ID of the user who posted the job
+//	l   location ID
+//	c   category ID
+//	sk  comma separated IDs of skills, any of which must match
+//	rsk comma separated IDs of skills the post must require
+//
+// Unknown parameters and malformed IDs are ignored.
+func ParseJobPostFilter(values url.Values) JobPost {
+	filters := JobPost{}
+	filters.Title = values.Get("q")
+	if userId, err := strconv.Atoi(values.Get("u")); err == nil {
+		filters.UserID = uint(userId)
+	}
+	if locationId, err := strconv.Atoi(values.Get("l")); err == nil {
+		filters.LocationID = uint(locationId)
+	}
+	if categoryId, err := strconv.Atoi(values.Get("c")); err == nil {
+		filters.CategoryID = uint(categoryId)
+	}
+	for _, id := range parseIDList(values.Get("sk")) {
+		skill := Skill{}
+		skill.ID = id
+		filters.Skills = append(filters.Skills, skill)
+	}
+	for _, id := range parseIDList(values.Get("rsk")) {
+		filters.SkillRequirements = append(filters.SkillRequirements, JobPostSkill{SkillID: id, Required: true})
+	}
+
+	return filters
+}
+
+// EncodeJobPostFilter is the inverse of ParseJobPostFilter,
+// it returns the query parameters describing the filters.
+func EncodeJobPostFilter(filters JobPost) url.Values {
+	values := url.Values{}
+	if filters.Title != "" {
+		values.Set("q", filters.Title)
+	}
+	if filters.UserID > 0 {
+		values.Set("u", strconv.Itoa(int(filters.UserID)))
+	}
+	if filters.LocationID > 0 {
+		values.Set("l", strconv.Itoa(int(filters.LocationID)))
+	}
+	if filters.CategoryID > 0 {
+		values.Set("c", strconv.Itoa(int(filters.CategoryID)))
+	}
+	var ids []string
+	for _, skill := range filters.Skills {
+		ids = append(ids, strconv.Itoa(int(skill.ID)))
+	}
+	if len(ids) > 0 {
+		values.Set("sk", strings.Join(ids, ","))
+	}
+	ids = nil
+	for _, req := range filters.SkillRequirements {
+		ids = append(ids, strconv.Itoa(int(req.SkillID)))
+	}
+	if len(ids) > 0 {
+		values.Set("rsk", strings.Join(ids, ","))
+	}
+
+	return values
+}
+
+// MatchesJobPostFilter reports whether the job post would be
+// returned by JobPostDB.FindAll for the provided filters. The
+// job post must have its skills and skill requirements loaded.
+func MatchesJobPostFilter(filters JobPost, jp *JobPost) bool {
+	if !strings.Contains(strings.ToUpper(jp.Title), strings.ToUpper(filters.Title)) {
+		return false
+	}
+	if filters.UserID > 0 && filters.UserID != jp.UserID {
+		return false
+	}
+	if filters.LocationID > 0 && filters.LocationID != jp.LocationID {
+		return false
+	}
+	if filters.CategoryID > 0 && filters.CategoryID != jp.CategoryID {
+		return false
+	}
+	if len(filters.Skills) > 0 {
+		hasSkill := false
+		for _, skill := range filters.Skills {
+			for _, jpSkill := range jp.Skills {
+				if skill.ID == jpSkill.ID {
+					hasSkill = true
+				}
+			}
+		}
+		if !hasSkill {
+			return false
+		}
+	}
+	for _, req := range filters.SkillRequirements {
+		hasSkill := false
+		for _, jpSkill := range jp.Skills {
+			if jpSkill.ID == req.SkillID {
+				hasSkill = jp.skillRequirement(req.SkillID).Required
+			}
+		}
+		if !hasSkill {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseIDList(list string) []uint {
+	var ids []uint
+	if list == "" {
+		return ids
+	}
+	for _, idStr := range strings.Split(list, ",") {
+		if id, err := strconv.Atoi(idStr); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,293 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// JobPostRevision is the state of a job post after one of its
+// changes: its creation, an update, a skill added or removed, a
+// requirement changed or the restore of an earlier revision.
+// Revisions are never changed, the job post is locked while one
+// is recorded so their numbers follow the order of the changes.
+// They are deleted when the job post is purged.
+type JobPostRevision struct {
+	ID        uint      `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	JobPostID uint      `gorm:"not null;unique_index:idx_job_post_revision" json:"jobPostId"`
+	// Number counts the revisions of the job post from 1
+	Number uint `gorm:"not null;unique_index:idx_job_post_revision" json:"number"`
+	// AuthorID is the user making the change, the owner of the
+	// import or job feed for the changes they make.
+	AuthorID uint `gorm:"index" json:"authorId,omitempty"`
+	// Snapshot is the JSON encoded JobPostSnapshot, decoded into
+	// JobPost when the revision is read.
+	Snapshot string          `gorm:"type:text;not null" json:"-"`
+	JobPost  JobPostSnapshot `gorm:"-" json:"jobPost"`
+	// Changes, SkillsAdded and SkillsRemoved are the difference
+	// with the previous revision, set by Revisions. The first
+	// revision recorded lists every field.
+	Changes       []FieldChange `gorm:"-" json:"changes"`
+	SkillsAdded   []uint        `gorm:"-" json:"skillsAdded"`
+	SkillsRemoved []uint        `gorm:"-" json:"skillsRemoved"`
+}
+
+// JobPostSnapshot holds the fields of a job post its authors
+// can change, along with its skills and their requirements.
+type JobPostSnapshot struct {
+	Title          string       `json:"title"`
+	LocationID     uint         `json:"locationId"`
+	CategoryID     uint         `json:"categoryId"`
+	Description    string       `json:"description"`
+	ApplyAt        string       `json:"applyAt"`
+	PublishedAt    *time.Time   `json:"publishedAt"`
+	ValidThrough   *time.Time   `json:"validThrough"`
+	SalaryMin      uint         `json:"salaryMin"`
+	SalaryMax      uint         `json:"salaryMax"`
+	SalaryCurrency string       `json:"salaryCurrency"`
+	SalaryPeriod   SalaryPeriod `json:"salaryPeriod"`
+	SkillIDs       []uint       `json:"skillIds"`
+	// SkillRequirements are the job_post_skills rows, by skill.
+	// Revisions recorded before requirements were part of the
+	// snapshots only have SkillIDs.
+	SkillRequirements []JobPostSkill `json:"skillRequirements,omitempty"`
+}
+
+// FieldChange is a field of a job post changed by a revision,
+// the field is named as in the JSON of the job post. The
+// requirements changed on a skill kept by the revision are a
+// skillRequirements change from and to the JobPostSkill.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+func newJobPostSnapshot(jp *JobPost, requirements []JobPostSkill) JobPostSnapshot {
+	skillIDs := make([]uint, len(requirements))
+	for i := range requirements {
+		requirements[i].JobPostID = 0
+		skillIDs[i] = requirements[i].SkillID
+	}
+	return JobPostSnapshot{
+		Title:             jp.Title,
+		LocationID:        jp.LocationID,
+		CategoryID:        jp.CategoryID,
+		Description:       jp.Description,
+		ApplyAt:           jp.ApplyAt,
+		PublishedAt:       jp.PublishedAt,
+		ValidThrough:      jp.ValidThrough,
+		SalaryMin:         jp.SalaryMin,
+		SalaryMax:         jp.SalaryMax,
+		SalaryCurrency:    jp.SalaryCurrency,
+		SalaryPeriod:      jp.SalaryPeriod,
+		SkillIDs:          skillIDs,
+		SkillRequirements: requirements,
+	}
+}
+
+// requirements returns the requirements of the snapshot, the
+// skills of older snapshots get the default ones.
+func (s JobPostSnapshot) requirements() []JobPostSkill {
+	if len(s.SkillRequirements) > 0 || len(s.SkillIDs) == 0 {
+		return s.SkillRequirements
+	}
+	requirements := make([]JobPostSkill, len(s.SkillIDs))
+	for i, id := range s.SkillIDs {
+		requirements[i] = JobPostSkill{SkillID: id, Required: true}
+	}
+	return requirements
+}
+
+// Apply sets the fields of the job post to the ones of the
+// snapshot, the skills are left to the caller.
+func (s JobPostSnapshot) Apply(jp *JobPost) {
+	jp.Title = s.Title
+	jp.LocationID = s.LocationID
+	jp.CategoryID = s.CategoryID
+	jp.Description = s.Description
+	jp.ApplyAt = s.ApplyAt
+	jp.PublishedAt = s.PublishedAt
+	jp.ValidThrough = s.ValidThrough
+	jp.SalaryMin = s.SalaryMin
+	jp.SalaryMax = s.SalaryMax
+	jp.SalaryCurrency = s.SalaryCurrency
+	jp.SalaryPeriod = s.SalaryPeriod
+}
+
+// DiffJobPostSnapshots returns the fields changed from prev to
+// next, in the order of JobPostSnapshot followed by the changed
+// requirements, and the skills added and removed. A nil prev
+// changes every field.
+func DiffJobPostSnapshots(prev *JobPostSnapshot, next JobPostSnapshot) (changes []FieldChange, added, removed []uint) {
+	changes, added, removed = []FieldChange{}, []uint{}, []uint{}
+	if prev == nil {
+		prev = &JobPostSnapshot{}
+	}
+	prevValue, nextValue := reflect.ValueOf(*prev), reflect.ValueOf(next)
+	for i := 0; i < nextValue.NumField(); i++ {
+		field := nextValue.Type().Field(i)
+		if field.Name == "SkillIDs" || field.Name == "SkillRequirements" {
+			continue
+		}
+		from, to := prevValue.Field(i).Interface(), nextValue.Field(i).Interface()
+		if sameValue(from, to) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field: strings.Split(field.Tag.Get("json"), ",")[0],
+			From:  from,
+			To:    to,
+		})
+	}
+
+	prevRequirements := map[uint]JobPostSkill{}
+	for _, req := range prev.requirements() {
+		prevRequirements[req.SkillID] = req
+	}
+	for _, req := range next.requirements() {
+		if from, ok := prevRequirements[req.SkillID]; ok && from != req {
+			changes = append(changes, FieldChange{Field: "skillRequirements", From: from, To: req})
+		}
+	}
+
+	had := map[uint]bool{}
+	for _, id := range prev.SkillIDs {
+		had[id] = true
+	}
+	has := map[uint]bool{}
+	for _, id := range next.SkillIDs {
+		has[id] = true
+		if !had[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range prev.SkillIDs {
+		if !has[id] {
+			removed = append(removed, id)
+		}
+	}
+	return changes, added, removed
+}
+
+// sameValue compares the fields of two snapshots, times are
+// equal whatever their location.
+func sameValue(a, b interface{}) bool {
+	if aTime, ok := a.(*time.Time); ok {
+		bTime := b.(*time.Time)
+		if aTime == nil || bTime == nil {
+			return aTime == bTime
+		}
+		return aTime.Equal(*bTime)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// recordJobPostRevision records the job post as stored in tx as
+// its next revision. The job post stays locked until tx ends,
+// so concurrent changes are numbered one after the other.
+func recordJobPostRevision(tx *gorm.DB, jobPostID, authorID uint) error {
+	var jobPost JobPost
+	locked := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", jobPostID)
+	if err := first(locked, &jobPost); err != nil {
+		return err
+	}
+	var requirements []JobPostSkill
+	err := tx.Where("job_post_id = ?", jobPostID).Order("skill_id").Find(&requirements).Error
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(newJobPostSnapshot(&jobPost, requirements))
+	if err != nil {
+		return err
+	}
+	var last JobPostRevision
+	err = tx.Select("number").Where("job_post_id = ?", jobPostID).
+		Order("number DESC").First(&last).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return tx.Create(&JobPostRevision{
+		JobPostID: jobPostID,
+		Number:    last.Number + 1,
+		AuthorID:  authorID,
+		Snapshot:  string(snapshot),
+	}).Error
+}
+
+func (jpg *jobPostGorm) Revisions(jobPostID uint) ([]JobPostRevision, error) {
+	var revisions []JobPostRevision
+	err := jpg.db.Where("job_post_id = ?", jobPostID).Order("number").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	var prev *JobPostSnapshot
+	for i := range revisions {
+		if err := json.Unmarshal([]byte(revisions[i].Snapshot), &revisions[i].JobPost); err != nil {
+			return nil, err
+		}
+		revisions[i].Changes, revisions[i].SkillsAdded, revisions[i].SkillsRemoved =
+			DiffJobPostSnapshots(prev, revisions[i].JobPost)
+		prev = &revisions[i].JobPost
+	}
+	return revisions, nil
+}
+
+func (jpg *jobPostGorm) Revision(jobPostID, number uint) (*JobPostRevision, error) {
+	var revision JobPostRevision
+	db := jpg.db.Where("job_post_id = ? AND number = ?", jobPostID, number)
+	if err := first(db, &revision); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(revision.Snapshot), &revision.JobPost); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (jpg *jobPostGorm) RestoreRevision(jobPost *JobPost, revision *JobPostRevision) error {
+	return transaction(jpg.db, func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, "job_posts", jobPost.ID, jobPost.Version); err != nil {
+			return err
+		}
+		jobPost.Version++
+		revision.JobPost.Apply(jobPost)
+		if err := tx.Set("gorm:association_autoupdate", false).Omit("announced_at").Save(jobPost).Error; err != nil {
+			return err
+		}
+
+		// The requirements are restored as they were, skills
+		// deleted since the revision are not
+		if err := tx.Where("job_post_id = ?", jobPost.ID).Delete(&JobPostSkill{}).Error; err != nil {
+			return err
+		}
+		for _, req := range revision.JobPost.requirements() {
+			switch err := first(tx.Where("id = ?", req.SkillID), &Skill{}); err {
+			case nil:
+			case ErrNotFound:
+				continue
+			default:
+				return err
+			}
+			// Not created with gorm, which would replace a false
+			// Required with the default of the column
+			err := tx.Exec(`INSERT INTO job_post_skills (job_post_id, skill_id, required, min_proficiency, min_years)
+				VALUES (?, ?, ?, ?, ?)`, jobPost.ID, req.SkillID, req.Required, req.MinProficiency, req.MinYears).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := recordJobPostRevision(tx, jobPost.ID, jobPost.EditedBy); err != nil {
+			return err
+		}
+		if err := recordEvent(tx, EventJobPostUpdated, aggregateJobPost, jobPost.ID, jobPost); err != nil {
+			return err
+		}
+		return announceJobPost(tx, jobPost, time.Now())
+	})
+}
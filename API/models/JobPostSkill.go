@@ -0,0 +1,66 @@
+package models
+
+// ProficiencyLevel is the minimum level of a skill a job post
+// expects from candidates.
+type ProficiencyLevel string
+
+const (
+	ProficiencyBeginner     ProficiencyLevel = "beginner"
+	ProficiencyIntermediate ProficiencyLevel = "intermediate"
+	ProficiencyAdvanced     ProficiencyLevel = "advanced"
+	ProficiencyExpert       ProficiencyLevel = "expert"
+)
+
+// Valid reports whether the level is one of the known levels,
+// an empty level meaning no minimum proficiency.
+func (pl ProficiencyLevel) Valid() bool {
+	switch pl {
+	case "", ProficiencyBeginner, ProficiencyIntermediate, ProficiencyAdvanced, ProficiencyExpert:
+		return true
+	}
+	return false
+}
+
+const (
+	requiredSkillWeight = 1
+	optionalSkillWeight = 0.5
+)
+
+// JobPostSkill holds what a job post expects from candidates
+// on one of its skills. It is stored on the job_post_skills
+// join table, so every skill attached to a job post has one,
+// and skills are required unless stated otherwise.
+type JobPostSkill struct {
+	JobPostID      uint             `gorm:"primary_key;auto_increment:false" json:"-"`
+	SkillID        uint             `gorm:"primary_key;auto_increment:false" json:"skillId"`
+	Required       bool             `gorm:"not null;default:true" json:"required"`
+	MinProficiency ProficiencyLevel `json:"minProficiency,omitempty"`
+	MinYears       uint             `json:"minYears,omitempty"`
+	// EditedBy is the user changing the requirements, recorded
+	// as the author of the revision.
+	EditedBy uint `gorm:"-" json:"-"`
+}
+
+func (JobPostSkill) TableName() string {
+	return "job_post_skills"
+}
+
+// skillRequirement returns the requirements of the job post on
+// the provided skill.
+func (jp *JobPost) skillRequirement(skillID uint) JobPostSkill {
+	for _, req := range jp.SkillRequirements {
+		if req.SkillID == skillID {
+			return req
+		}
+	}
+	return JobPostSkill{JobPostID: jp.ID, SkillID: skillID, Required: true}
+}
+
+// skillWeight returns how much the skill counts towards a
+// match with this job post.
+func (jp *JobPost) skillWeight(skillID uint) float64 {
+	if jp.skillRequirement(skillID).Required {
+		return requiredSkillWeight
+	}
+	return optionalSkillWeight
+}
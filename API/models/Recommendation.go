@@ -0,0 +1,115 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// recencyWindow is the age after which a job post no
+	// longer gets a recency boost.
+	recencyWindow   = 30 * 24 * time.Hour
+	maxRecencyBoost = 0.2
+	locationBoost   = 0.1
+)
+
+// JobRecommendation is a published job post ranked for a
+// candidate, along with the reasons it was recommended.
+type JobRecommendation struct {
+	JobPost       JobPost  `json:"jobPost"`
+	Score         float64  `json:"score"`
+	MatchedSkills []Skill  `json:"matchedSkills"`
+	Explanation   []string `json:"explanation"`
+}
+
+// RankJobPosts scores every published job post by the weighted
+// overlap between its skills and the candidate skills, where
+// nice-to-have skills count half as much as required ones, boosted
+// by how recently it was published and whether it matches the
+// preferred location (0 means no preference). Posts sharing no
+// skill with the candidate are left out, and the rest are
+// returned from best to worst match.
+func RankJobPosts(jobPosts []JobPost, skills []Skill, locationID uint, now time.Time) []JobRecommendation {
+	candidateSkills := make(map[uint]bool, len(skills))
+	for _, skill := range skills {
+		candidateSkills[skill.ID] = true
+	}
+
+	recommendations := []JobRecommendation{}
+	for _, jp := range jobPosts {
+		if jp.PublishedAt == nil || len(jp.Skills) == 0 {
+			continue
+		}
+
+		var matched []Skill
+		var matchedWeight, totalWeight float64
+		var required, matchedRequired, optional, matchedOptional int
+		for _, skill := range jp.Skills {
+			isRequired := jp.skillRequirement(skill.ID).Required
+			isMatch := candidateSkills[skill.ID]
+			if isRequired {
+				required++
+			} else {
+				optional++
+			}
+
+			weight := jp.skillWeight(skill.ID)
+			totalWeight += weight
+			if !isMatch {
+				continue
+			}
+			matched = append(matched, skill)
+			matchedWeight += weight
+			if isRequired {
+				matchedRequired++
+			} else {
+				matchedOptional++
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		rec := JobRecommendation{
+			JobPost:       jp,
+			Score:         matchedWeight / totalWeight,
+			MatchedSkills: matched,
+		}
+		if required > 0 {
+			rec.Explanation = append(rec.Explanation, fmt.Sprintf("matches %d of %d required skills", matchedRequired, required))
+		}
+		if optional > 0 {
+			rec.Explanation = append(rec.Explanation, fmt.Sprintf("matches %d of %d nice-to-have skills", matchedOptional, optional))
+		}
+
+		if age := now.Sub(*jp.PublishedAt); age < recencyWindow {
+			rec.Score += maxRecencyBoost * (1 - float64(age)/float64(recencyWindow))
+			rec.Explanation = append(rec.Explanation, fmt.Sprintf("posted %s", describeAge(age)))
+		}
+		if locationID > 0 && jp.LocationID == locationID {
+			rec.Score += locationBoost
+			rec.Explanation = append(rec.Explanation, "in your preferred location")
+		}
+
+		recommendations = append(recommendations, rec)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+
+	return recommendations
+}
+
+func describeAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
@@ -0,0 +1,309 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/samueldaviddelacruz/go-job-board/API/hash"
+)
+
+// AlertFrequency is how often a candidate is emailed about new
+// job posts matching one of their saved searches.
+type AlertFrequency string
+
+const (
+	// AlertInstant sends an email as soon as a matching job
+	// post is published.
+	AlertInstant AlertFrequency = "instant"
+	// AlertDaily sends a digest of the job posts published
+	// during the day.
+	AlertDaily AlertFrequency = "daily"
+	// AlertNone keeps the search saved without sending emails.
+	AlertNone AlertFrequency = "none"
+)
+
+// SavedSearch is a GET /jobs query saved by a candidate to be
+// alerted about new job posts matching it.
+type SavedSearch struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index" json:"userId"`
+	Name   string `json:"name"`
+	// Query holds the GET /jobs query parameters, see
+	// ParseJobPostFilter.
+	Query          string         `gorm:"not null" json:"query"`
+	Frequency      AlertFrequency `gorm:"not null" json:"frequency"`
+	LastNotifiedAt *time.Time     `json:"lastNotifiedAt,omitempty"`
+}
+
+// SentAlert records that a job post was emailed to the owner of
+// a saved search, it is not sent to them again.
+type SentAlert struct {
+	ID            uint `gorm:"primary_key"`
+	CreatedAt     time.Time
+	SavedSearchID uint `gorm:"not null;unique_index:idx_sent_alert"`
+	JobPostID     uint `gorm:"not null;unique_index:idx_sent_alert"`
+}
+
+// Filter returns the filters to use with JobPostDB.FindAll.
+func (s *SavedSearch) Filter() JobPost {
+	values, _ := url.ParseQuery(s.Query)
+	return ParseJobPostFilter(values)
+}
+
+type SavedSearchService interface {
+	SavedSearchDB
+
+	// UnsubscribeToken returns the token used to sign the
+	// unsubscribe link of the alert emails.
+	UnsubscribeToken(search *SavedSearch) string
+
+	// Unsubscribe stops the alerts of the saved search if the
+	// token is valid, otherwise ErrUnsubscribeTokenInvalid is
+	// returned.
+	Unsubscribe(id uint, token string) error
+}
+
+type SavedSearchDB interface {
+	ByID(id uint) (*SavedSearch, error)
+	ByUserID(userID uint) ([]SavedSearch, error)
+	ByFrequency(frequency AlertFrequency) ([]SavedSearch, error)
+	Create(search *SavedSearch) error
+	Update(search *SavedSearch) error
+	Delete(id uint) error
+
+	// Unsent returns the job posts that were not emailed to the
+	// owner of the saved search yet.
+	Unsent(search *SavedSearch, jobPosts []JobPost) ([]JobPost, error)
+	// MarkSent records the job posts as emailed and sets the
+	// LastNotifiedAt of the saved search.
+	MarkSent(search *SavedSearch, jobPosts []JobPost, at time.Time) error
+}
+
+func NewSavedSearchService(db *gorm.DB, hmacKey string) SavedSearchService {
+	return &savedSearchService{
+		SavedSearchDB: &savedSearchValidator{
+			&savedSearchGorm{db},
+		},
+		hmac: hash.NewHMAC(hmacKey),
+	}
+}
+
+var _ SavedSearchService = &savedSearchService{}
+
+type savedSearchService struct {
+	SavedSearchDB
+	hmac hash.HMAC
+}
+
+func (sss *savedSearchService) UnsubscribeToken(search *SavedSearch) string {
+	return sss.hmac.Hash(unsubscribeMessage(search))
+}
+
+func (sss *savedSearchService) Unsubscribe(id uint, token string) error {
+	search, err := sss.ByID(id)
+	if err == ErrNotFound {
+		return ErrUnsubscribeTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if !sss.hmac.Equal(unsubscribeMessage(search), token) {
+		return ErrUnsubscribeTokenInvalid
+	}
+	search.Frequency = AlertNone
+
+	return sss.Update(search)
+}
+
+func unsubscribeMessage(search *SavedSearch) string {
+	return fmt.Sprintf("saved-search:%d:%d", search.ID, search.UserID)
+}
+
+type savedSearchValidator struct {
+	SavedSearchDB
+}
+
+func (ssv *savedSearchValidator) Create(search *SavedSearch) error {
+	err := runSavedSearchValFuncs(search,
+		ssv.userIDRequired,
+		ssv.normalizeQuery,
+		ssv.defaultFrequency,
+		ssv.frequencyValid,
+		ssv.defaultName)
+	if err != nil {
+		return err
+	}
+
+	return ssv.SavedSearchDB.Create(search)
+}
+
+func (ssv *savedSearchValidator) Update(search *SavedSearch) error {
+	err := runSavedSearchValFuncs(search,
+		ssv.userIDRequired,
+		ssv.normalizeQuery,
+		ssv.frequencyValid,
+		ssv.defaultName)
+	if err != nil {
+		return err
+	}
+
+	return ssv.SavedSearchDB.Update(search)
+}
+
+func (ssv *savedSearchValidator) Delete(id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return ssv.SavedSearchDB.Delete(id)
+}
+
+func (ssv *savedSearchValidator) userIDRequired(s *SavedSearch) error {
+	if s.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	return nil
+}
+
+// normalizeQuery drops the query parameters that are not
+// filters so the saved query matches what FindAll uses.
+func (ssv *savedSearchValidator) normalizeQuery(s *SavedSearch) error {
+	values, err := url.ParseQuery(strings.TrimPrefix(s.Query, "?"))
+	if err != nil {
+		return ErrQueryInvalid
+	}
+	s.Query = EncodeJobPostFilter(ParseJobPostFilter(values)).Encode()
+	return nil
+}
+
+func (ssv *savedSearchValidator) defaultFrequency(s *SavedSearch) error {
+	if s.Frequency == "" {
+		s.Frequency = AlertDaily
+	}
+	return nil
+}
+
+func (ssv *savedSearchValidator) frequencyValid(s *SavedSearch) error {
+	switch s.Frequency {
+	case AlertInstant, AlertDaily, AlertNone:
+		return nil
+	}
+	return ErrFrequencyInvalid
+}
+
+func (ssv *savedSearchValidator) defaultName(s *SavedSearch) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		s.Name = s.Filter().Title
+	}
+	if s.Name == "" {
+		s.Name = "All jobs"
+	}
+	return nil
+}
+
+var _ SavedSearchDB = &savedSearchGorm{}
+
+type savedSearchGorm struct {
+	db *gorm.DB
+}
+
+func (ssg *savedSearchGorm) ByID(id uint) (*SavedSearch, error) {
+	var search SavedSearch
+	err := first(ssg.db.Where("id = ?", id), &search)
+
+	return &search, err
+}
+
+func (ssg *savedSearchGorm) ByUserID(userID uint) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := ssg.db.Where("user_id = ?", userID).Find(&searches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (ssg *savedSearchGorm) ByFrequency(frequency AlertFrequency) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := ssg.db.Where("frequency = ?", frequency).Find(&searches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+func (ssg *savedSearchGorm) Create(search *SavedSearch) error {
+	return ssg.db.Create(search).Error
+}
+
+func (ssg *savedSearchGorm) Update(search *SavedSearch) error {
+	return ssg.db.Save(search).Error
+}
+
+func (ssg *savedSearchGorm) Delete(id uint) error {
+	search := SavedSearch{Model: gorm.Model{ID: id}}
+	return ssg.db.Delete(&search).Error
+}
+
+func (ssg *savedSearchGorm) Unsent(search *SavedSearch, jobPosts []JobPost) ([]JobPost, error) {
+	if len(jobPosts) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, len(jobPosts))
+	for i, jp := range jobPosts {
+		ids[i] = jp.ID
+	}
+	var sentIDs []uint
+	err := ssg.db.Model(&SentAlert{}).
+		Where("saved_search_id = ? AND job_post_id IN (?)", search.ID, ids).
+		Pluck("job_post_id", &sentIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	sent := make(map[uint]bool, len(sentIDs))
+	for _, id := range sentIDs {
+		sent[id] = true
+	}
+	var unsent []JobPost
+	for _, jp := range jobPosts {
+		if !sent[jp.ID] {
+			unsent = append(unsent, jp)
+		}
+	}
+	return unsent, nil
+}
+
+func (ssg *savedSearchGorm) MarkSent(search *SavedSearch, jobPosts []JobPost, at time.Time) error {
+	return transaction(ssg.db, func(tx *gorm.DB) error {
+		for _, jp := range jobPosts {
+			err := tx.Exec(`INSERT INTO sent_alerts (created_at, saved_search_id, job_post_id)
+				VALUES (?, ?, ?) ON CONFLICT DO NOTHING`, at, search.ID, jp.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(search).UpdateColumn("last_notified_at", &at).Error; err != nil {
+			return err
+		}
+		search.LastNotifiedAt = &at
+		return nil
+	})
+}
+
+type savedSearchValFunc func(*SavedSearch) error
+
+func runSavedSearchValFuncs(search *SavedSearch, fns ...savedSearchValFunc) error {
+	for _, fn := range fns {
+		if err := fn(search); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
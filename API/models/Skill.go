@@ -1,18 +1,61 @@
 package models
 
-import "github.com/jinzhu/gorm"
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/jinzhu/gorm"
+)
+
+// Skill represents a canonical skill of the taxonomy. Every
+// spelling of a skill ("Go", "golang", "Go lang") resolves to
+// a single Skill through its normalized name and its aliases.
 type Skill struct {
 	gorm.Model
-	SkillName string
+	SkillName      string       `gorm:"not null;unique_index"`
+	NormalizedName string       `gorm:"not null;unique_index" json:"-"`
+	Category       string       `json:"category,omitempty"`
+	Aliases        []SkillAlias `gorm:"preload:false" json:"aliases,omitempty"`
+	RelatedSkills  []Skill      `gorm:"many2many:skill_related_skills;association_jointable_foreignkey:related_skill_id;preload:false" json:"relatedSkills,omitempty"`
+}
+
+// SkillAlias is an alternative spelling of a canonical Skill
+type SkillAlias struct {
+	gorm.Model
+	SkillID         uint   `gorm:"not null;index" json:"-"`
+	Alias           string `gorm:"not null" json:"alias"`
+	NormalizedAlias string `gorm:"not null;unique_index" json:"-"`
+}
+
+// NormalizeSkillName returns the lookup key used to match a
+// skill name against the taxonomy. Case, whitespace and
+// punctuation are dropped, except for the characters that
+// tell skills like C, C++ and C# apart.
+func NormalizeSkillName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
 }
 
 type SkillsService interface {
 	SkillDB
+
+	// Autocomplete returns up to limit skills whose name or
+	// one of its aliases starts with the provided prefix.
+	Autocomplete(prefix string, limit int) ([]Skill, error)
 }
 
+var _ SkillsService = &skillsService{}
+
 type skillsService struct {
 	SkillDB
+	index *skillIndex
 }
 
 func NewSkillService(db *gorm.DB) SkillsService {
@@ -20,11 +63,45 @@ func NewSkillService(db *gorm.DB) SkillsService {
 		SkillDB: &skillsValidator{
 			&skillsGorm{db},
 		},
+		index: &skillIndex{},
+	}
+}
+
+func (ss *skillsService) Autocomplete(prefix string, limit int) ([]Skill, error) {
+	if err := ss.index.loadIfNeeded(ss.SkillDB.FindAll); err != nil {
+		return nil, err
+	}
+	return ss.index.search(NormalizeSkillName(prefix), limit), nil
+}
+
+// Create will create the provided skill and invalidate the
+// autocomplete index so the new skill can be found.
+func (ss *skillsService) Create(skill *Skill) error {
+	if err := ss.SkillDB.Create(skill); err != nil {
+		return err
+	}
+	ss.index.invalidate()
+	return nil
+}
+
+// AddAlias will add the provided alias to the skill and
+// invalidate the autocomplete index.
+func (ss *skillsService) AddAlias(skill *Skill, alias string) error {
+	if err := ss.SkillDB.AddAlias(skill, alias); err != nil {
+		return err
 	}
+	ss.index.invalidate()
+	return nil
 }
 
 type SkillDB interface {
 	FindAll() ([]Skill, error)
+	// ByName looks up the canonical skill matching the provided
+	// name or one of its aliases.
+	ByName(name string) (*Skill, error)
+	Create(skill *Skill) error
+	AddAlias(skill *Skill, alias string) error
+	AddRelatedSkill(skill *Skill, related Skill) error
 	AddSkillToOwner(owner interface{}, skill Skill) error
 	DeleteSkillFromOwner(owner interface{}, skill Skill) error
 }
@@ -33,9 +110,71 @@ type skillsValidator struct {
 	SkillDB
 }
 
+// ByName will normalize the name before calling ByName on
+// the SkillDB field.
+func (sv *skillsValidator) ByName(name string) (*Skill, error) {
+	skill := Skill{SkillName: name}
+	if err := runSkillValFuncs(&skill, sv.normalizeName, sv.skillNameRequired); err != nil {
+		return nil, err
+	}
+	return sv.SkillDB.ByName(skill.NormalizedName)
+}
+
+func (sv *skillsValidator) Create(skill *Skill) error {
+	err := runSkillValFuncs(
+		skill,
+		sv.trimName,
+		sv.normalizeName,
+		sv.skillNameRequired,
+		sv.skillNameIsAvail,
+		sv.normalizeAliases,
+	)
+	if err != nil {
+		return err
+	}
+
+	return sv.SkillDB.Create(skill)
+}
+
+func (sv *skillsValidator) AddAlias(skill *Skill, alias string) error {
+	if err := runSkillValFuncs(skill, sv.skillIDRequired); err != nil {
+		return err
+	}
+	if NormalizeSkillName(alias) == "" {
+		return ErrSkillAliasRequired
+	}
+	if _, err := sv.ByName(alias); err == nil {
+		return ErrSkillNameTaken
+	} else if err != ErrNotFound {
+		return err
+	}
+
+	return sv.SkillDB.AddAlias(skill, strings.TrimSpace(alias))
+}
+
+func (sv *skillsValidator) AddRelatedSkill(skill *Skill, related Skill) error {
+	err := runSkillValFuncs(skill, sv.skillIDRequired)
+	if err != nil {
+		return err
+	}
+	err = runSkillValFuncs(&related, sv.resolveByName, sv.skillIDRequired)
+	if err != nil {
+		return err
+	}
+	if skill.ID == related.ID {
+		return ErrIDInvalid
+	}
+
+	return sv.SkillDB.AddRelatedSkill(skill, related)
+}
+
+// AddSkillToOwner accepts either the ID of the skill or any
+// of its spellings in SkillName, which is resolved to the
+// canonical skill before it is attached to the owner.
 func (sv *skillsValidator) AddSkillToOwner(owner interface{}, skill Skill) error {
 	err := runSkillValFuncs(
 		&skill,
+		sv.resolveByName,
 		sv.skillIDRequired,
 	)
 	if err != nil {
@@ -45,6 +184,19 @@ func (sv *skillsValidator) AddSkillToOwner(owner interface{}, skill Skill) error
 	return sv.SkillDB.AddSkillToOwner(owner, skill)
 }
 
+func (sv *skillsValidator) DeleteSkillFromOwner(owner interface{}, skill Skill) error {
+	err := runSkillValFuncs(
+		&skill,
+		sv.resolveByName,
+		sv.skillIDRequired,
+	)
+	if err != nil {
+		return err
+	}
+
+	return sv.SkillDB.DeleteSkillFromOwner(owner, skill)
+}
+
 func (sv *skillsValidator) skillIDRequired(s *Skill) error {
 	if s.ID <= 0 {
 		return ErrIDInvalid
@@ -52,6 +204,62 @@ func (sv *skillsValidator) skillIDRequired(s *Skill) error {
 	return nil
 }
 
+func (sv *skillsValidator) trimName(s *Skill) error {
+	s.SkillName = strings.TrimSpace(s.SkillName)
+	return nil
+}
+
+func (sv *skillsValidator) normalizeName(s *Skill) error {
+	s.NormalizedName = NormalizeSkillName(s.SkillName)
+	return nil
+}
+
+func (sv *skillsValidator) skillNameRequired(s *Skill) error {
+	if s.NormalizedName == "" {
+		return ErrSkillNameRequired
+	}
+	return nil
+}
+
+func (sv *skillsValidator) skillNameIsAvail(s *Skill) error {
+	_, err := sv.SkillDB.ByName(s.NormalizedName)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ErrSkillNameTaken
+}
+
+func (sv *skillsValidator) normalizeAliases(s *Skill) error {
+	for i := range s.Aliases {
+		s.Aliases[i].Alias = strings.TrimSpace(s.Aliases[i].Alias)
+		s.Aliases[i].NormalizedAlias = NormalizeSkillName(s.Aliases[i].Alias)
+		if s.Aliases[i].NormalizedAlias == "" {
+			return ErrSkillAliasRequired
+		}
+	}
+	return nil
+}
+
+// resolveByName will look up the canonical skill when only
+// a SkillName was provided.
+func (sv *skillsValidator) resolveByName(s *Skill) error {
+	if s.ID > 0 || NormalizeSkillName(s.SkillName) == "" {
+		return nil
+	}
+	found, err := sv.ByName(s.SkillName)
+	if err == ErrNotFound {
+		return ErrSkillUnknown
+	}
+	if err != nil {
+		return err
+	}
+	*s = *found
+	return nil
+}
+
 var _ SkillDB = &skillsGorm{}
 
 type skillsGorm struct {
@@ -61,19 +269,124 @@ type skillsGorm struct {
 func (sg skillsGorm) FindAll() ([]Skill, error) {
 	var skills []Skill
 
-	err := sg.db.Find(&skills).Error
+	err := sg.db.Preload("Aliases").Find(&skills).Error
 	if err != nil {
 		return nil, err
 	}
 
 	return skills, nil
 }
+
+// ByName expects the name to already be normalized.
+func (sg skillsGorm) ByName(normalizedName string) (*Skill, error) {
+	var skill Skill
+	aliases := sg.db.Table("skill_aliases").
+		Select("skill_id").
+		Where("normalized_alias = ? AND deleted_at IS NULL", normalizedName).
+		QueryExpr()
+	db := sg.db.Where("normalized_name = ? OR id IN (?)", normalizedName, aliases)
+	err := first(db, &skill)
+
+	return &skill, err
+}
+
+func (sg skillsGorm) Create(skill *Skill) error {
+	return sg.db.Create(skill).Error
+}
+
+func (sg skillsGorm) AddAlias(skill *Skill, alias string) error {
+	return sg.db.Model(skill).Association("Aliases").Append(SkillAlias{
+		Alias:           alias,
+		NormalizedAlias: NormalizeSkillName(alias),
+	}).Error
+}
+
+// AddRelatedSkill links both skills to each other, since a
+// relation between skills goes both ways.
+func (sg skillsGorm) AddRelatedSkill(skill *Skill, related Skill) error {
+	err := sg.db.Model(skill).Association("RelatedSkills").Append(related).Error
+	if err != nil {
+		return err
+	}
+	return sg.db.Model(&related).Association("RelatedSkills").Append(*skill).Error
+}
+
 func (sg skillsGorm) AddSkillToOwner(owner interface{}, skill Skill) error {
-	return sg.db.Model(owner).Association("Skills").Append(skill).Error
+	return transaction(sg.db, func(tx *gorm.DB) error {
+		if err := tx.Model(owner).Association("Skills").Append(skill).Error; err != nil {
+			return err
+		}
+		if err := incrementOwnerVersion(tx, owner); err != nil {
+			return err
+		}
+		if jobPost, ok := owner.(*JobPost); ok {
+			if err := recordJobPostRevision(tx, jobPost.ID, jobPost.EditedBy); err != nil {
+				return err
+			}
+		}
+		return recordSkillEvent(tx, owner, skill, true)
+	})
 }
 
 func (sg skillsGorm) DeleteSkillFromOwner(owner interface{}, skill Skill) error {
-	return sg.db.Model(owner).Association("Skills").Delete(skill).Error
+	return transaction(sg.db, func(tx *gorm.DB) error {
+		if err := tx.Model(owner).Association("Skills").Delete(skill).Error; err != nil {
+			return err
+		}
+		if err := incrementOwnerVersion(tx, owner); err != nil {
+			return err
+		}
+		if jobPost, ok := owner.(*JobPost); ok {
+			if err := recordJobPostRevision(tx, jobPost.ID, jobPost.EditedBy); err != nil {
+				return err
+			}
+		}
+		return recordSkillEvent(tx, owner, skill, false)
+	})
+}
+
+// incrementOwnerVersion increments the version of the job posts
+// and company profiles, users have no version.
+func incrementOwnerVersion(tx *gorm.DB, owner interface{}) error {
+	var err error
+	switch o := owner.(type) {
+	case *JobPost:
+		o.Version, err = incrementVersion(tx, "job_posts", o.ID)
+	case *CompanyProfile:
+		o.Version, err = incrementVersion(tx, "company_profiles", o.ID)
+	}
+	return err
+}
+
+// recordSkillEvent records the event matching the kind of owner
+// the skill was added to or removed from.
+func recordSkillEvent(tx *gorm.DB, owner interface{}, skill Skill, added bool) error {
+	var eventType EventType
+	var aggregateType string
+	var ownerID uint
+	switch o := owner.(type) {
+	case *JobPost:
+		eventType, aggregateType, ownerID = EventJobPostSkillRemoved, aggregateJobPost, o.ID
+		if added {
+			eventType = EventJobPostSkillAdded
+		}
+	case *User:
+		eventType, aggregateType, ownerID = EventUserSkillRemoved, aggregateUser, o.ID
+		if added {
+			eventType = EventUserSkillAdded
+		}
+	case *CompanyProfile:
+		eventType, aggregateType, ownerID = EventCompanyProfileSkillRemoved, aggregateCompanyProfile, o.ID
+		if added {
+			eventType = EventCompanyProfileSkillAdded
+		}
+	default:
+		return nil
+	}
+	return recordEvent(tx, eventType, aggregateType, ownerID, SkillEventPayload{
+		OwnerID: ownerID,
+		SkillID: skill.ID,
+	})
 }
 
 type skillValFunc func(skill *Skill) error
@@ -87,3 +400,85 @@ func runSkillValFuncs(skill *Skill, fns ...skillValFunc) error {
 
 	return nil
 }
+
+// skillIndex is an in-memory prefix index over the normalized
+// skill names and aliases, used to answer autocomplete queries
+// without hitting the database.
+type skillIndex struct {
+	mu      sync.RWMutex
+	loaded  bool
+	entries []skillIndexEntry
+	skills  map[uint]Skill
+}
+
+type skillIndexEntry struct {
+	key     string
+	skillID uint
+}
+
+func (si *skillIndex) invalidate() {
+	si.mu.Lock()
+	si.loaded = false
+	si.mu.Unlock()
+}
+
+func (si *skillIndex) loadIfNeeded(findAll func() ([]Skill, error)) error {
+	si.mu.RLock()
+	loaded := si.loaded
+	si.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	skills, err := findAll()
+	if err != nil {
+		return err
+	}
+	si.build(skills)
+	return nil
+}
+
+func (si *skillIndex) build(skills []Skill) {
+	var entries []skillIndexEntry
+	byID := make(map[uint]Skill, len(skills))
+	for _, skill := range skills {
+		byID[skill.ID] = skill
+		entries = append(entries, skillIndexEntry{key: NormalizeSkillName(skill.SkillName), skillID: skill.ID})
+		for _, alias := range skill.Aliases {
+			entries = append(entries, skillIndexEntry{key: alias.NormalizedAlias, skillID: skill.ID})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	si.mu.Lock()
+	si.entries = entries
+	si.skills = byID
+	si.loaded = true
+	si.mu.Unlock()
+}
+
+func (si *skillIndex) search(prefix string, limit int) []Skill {
+	si.mu.RLock()
+	defer si.mu.RUnlock()
+
+	skills := []Skill{}
+	seen := map[uint]bool{}
+	start := sort.Search(len(si.entries), func(i int) bool {
+		return si.entries[i].key >= prefix
+	})
+	for i := start; i < len(si.entries) && len(skills) < limit; i++ {
+		entry := si.entries[i]
+		if !strings.HasPrefix(entry.key, prefix) {
+			break
+		}
+		if seen[entry.skillID] {
+			continue
+		}
+		seen[entry.skillID] = true
+		skills = append(skills, si.skills[entry.skillID])
+	}
+
+	return skills
+}
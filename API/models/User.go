@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"github.com/jinzhu/gorm"
 	"github.com/samueldaviddelacruz/go-job-board/API/hash"
 	"golang.org/x/crypto/bcrypt"
@@ -18,12 +19,59 @@ type CompanyBenefit struct {
 type CompanyProfile struct {
 	UserID uint
 	gorm.Model
-	Website         string           `json:"website,omitempty"`
-	FoundedYear     uint             `json:"foundedYear,omitempty"`
-	Description     string           `json:"description,omitempty"`
-	CompanyBenefits []CompanyBenefit `json:"companyBenefits,omitempty"`
-	CompanyLogoUrl  string           `json:"companyLogoUrl,omitempty"`
-	Skills          []Skill          `gorm:"many2many:companyProfile_skills;" json:"skills,omitempty"`
+	CompanyID        uint             `gorm:"index" json:"companyId,omitempty"`
+	CompanyName      string           `json:"companyName,omitempty"`
+	Slug             string           `gorm:"index" json:"slug,omitempty"`
+	Website          string           `json:"website,omitempty"`
+	FoundedYear      uint             `json:"foundedYear,omitempty"`
+	Description      string           `json:"description,omitempty"`
+	CompanyBenefits  []CompanyBenefit `json:"companyBenefits,omitempty"`
+	CompanyLogoUrl   string           `json:"companyLogoUrl,omitempty"`
+	LogoThumbnailUrl string           `json:"logoThumbnailUrl,omitempty"`
+	LogoHeaderUrl    string           `json:"logoHeaderUrl,omitempty"`
+	LogoKeys         string           `json:"-"`
+	Skills           []Skill          `gorm:"many2many:companyProfile_skills;" json:"skills,omitempty"`
+	// The verification is set by VerifyCompanyDomain and lapses
+	// whenever the website changes.
+	VerifiedDomain     string     `json:"verifiedDomain,omitempty"`
+	VerificationMethod string     `json:"verificationMethod,omitempty"`
+	VerifiedAt         *time.Time `json:"verifiedAt,omitempty"`
+	Verified           bool       `gorm:"-" json:"verified"`
+	// Version goes up with every change, see JobPost.Version
+	Version uint `gorm:"not null;default:1" json:"version"`
+}
+
+// AfterFind sets whether the profile is verified
+func (cp *CompanyProfile) AfterFind() error {
+	cp.Verified = cp.VerifiedAt != nil
+	return nil
+}
+
+// setLogo copies the logo URLs and keys of another profile
+func (cp *CompanyProfile) setLogo(from CompanyProfile) {
+	cp.CompanyLogoUrl = from.CompanyLogoUrl
+	cp.LogoThumbnailUrl = from.LogoThumbnailUrl
+	cp.LogoHeaderUrl = from.LogoHeaderUrl
+	cp.LogoKeys = from.LogoKeys
+}
+
+// sameFields reports whether the fields set by updates are the
+// ones of another profile.
+func (cp *CompanyProfile) sameFields(other CompanyProfile) bool {
+	return cp.UserID == other.UserID &&
+		cp.CompanyName == other.CompanyName &&
+		cp.Website == other.Website &&
+		cp.FoundedYear == other.FoundedYear &&
+		cp.Description == other.Description
+}
+
+// setVerification copies the domain verification of another
+// profile.
+func (cp *CompanyProfile) setVerification(from CompanyProfile) {
+	cp.VerifiedDomain = from.VerifiedDomain
+	cp.VerificationMethod = from.VerificationMethod
+	cp.VerifiedAt = from.VerifiedAt
+	cp.Verified = from.VerifiedAt != nil
 }
 
 // UserRole represents the user Role
@@ -32,16 +80,25 @@ type Role struct {
 	RoleName string
 }
 
+// RoleAdmin is the role allowed on the /admin endpoints, it is
+// never assigned through the API.
+const RoleAdmin = "Admin"
+
 // User represents the User model stored in the database
 type User struct {
 	gorm.Model
 	Email          string          `gorm:"not null;unique_index" json:"email"`
 	PasswordHash   string          `gorm:"not null" json:"-"`
 	Password       string          `json:"-"`
+	RememberHash   string          `gorm:"index" json:"-"`
 	Role           *Role           `json:"-"`
 	RoleID         uint            `json:"roleId,omitempty"`
 	JobPosts       []JobPost       `json:"jobPosts,omitempty"`
 	CompanyProfile *CompanyProfile `json:"companyProfile,omitempty"`
+	Skills         []Skill         `gorm:"many2many:user_skills;" json:"skills,omitempty"`
+	// DeletionScheduledAt is when the account is erased, unless
+	// the user cancels the deletion before.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
 }
 
 // UserDB is used to interact with the users database.
@@ -58,16 +115,72 @@ type User struct {
 type UserDB interface {
 	// Methods for querying single users
 	ByID(id uint) (*User, error)
+	// ByIDWith returns the user with their company profile and
+	// skills, and the associations in include: jobPosts,
+	// companyProfile.benefits and companyProfile.skills.
+	ByIDWith(id uint, include ...string) (*User, error)
 	ByEmail(email string) (*User, error)
 	ByRemember(token string) (*User, error)
+	// IsAdmin reports whether the user has the admin role
+	IsAdmin(user *User) (bool, error)
 
 	// Methods for altering users
 	Create(user *User) error
 	Update(user *User) error
 	Delete(id uint) error
+	// Export returns everything stored about the user
+	Export(id uint) (*UserExport, error)
+	// ScheduleDeletion sets the user to be erased once the grace
+	// period is over, CancelDeletion keeps them.
+	ScheduleDeletion(user *User) error
+	CancelDeletion(user *User) error
+	// Erase anonymizes the user, removes them from their
+	// companies and deletes their personal data: company profile,
+	// OAuth connections, password resets, skills, bookmarks,
+	// saved searches, applications, imports, API keys, webhooks
+	// and job feeds. Their job posts without a company are
+	// deleted, to be purged with the other deleted job posts.
+	Erase(id uint) error
+	// EraseScheduled erases the users whose deletion is due and
+	// returns how many there were.
+	EraseScheduled(now time.Time) (int, error)
+	// Restore undoes the Delete of the user
+	Restore(id uint) error
+	// Purge permanently deletes the deleted user along with
+	// everything Erase deletes and their job posts. The job
+	// posts and company profile of a company other members still
+	// belong to are kept for the company.
+	Purge(id uint) error
+	// PurgeDeleted purges the users deleted before the time
+	// provided and returns how many there were.
+	PurgeDeleted(before time.Time) (int, error)
 	AddCompanyProfileBenefit(companyProfile *CompanyProfile, benefit CompanyBenefit) error
 	RemoveCompanyProfileBenefit(companyProfile *CompanyProfile, benefit CompanyBenefit) error
 	UpdateCompanyProfileBenefit(benefit *CompanyBenefit) error
+
+	// CompanyProfileBySlug returns the company profile with its
+	// benefits and skills. Slugs are assigned once the company
+	// is named and never change.
+	CompanyProfileBySlug(slug string) (*CompanyProfile, error)
+	// CompanyProfileForJobPost returns the profile of the
+	// company of the job post, or of its author for job posts
+	// without a company.
+	CompanyProfileForJobPost(jobPost *JobPost) (*CompanyProfile, error)
+	CompanyProfileByCompanyID(companyID uint) (*CompanyProfile, error)
+	// VerifyCompanyDomain saves the domain verification of the
+	// company profile, which Update leaves untouched.
+	VerifyCompanyDomain(profile *CompanyProfile) error
+	// UpdateCompanyLogo saves the logo URLs and keys of the
+	// company profile, which Update leaves untouched.
+	UpdateCompanyLogo(profile *CompanyProfile) error
+	// SearchCompanyProfiles returns a page of the named company
+	// profiles whose name or description contains query, with
+	// their benefits and skills, along with the number of
+	// matching profiles.
+	SearchCompanyProfiles(query string, offset, limit int) ([]CompanyProfile, int, error)
+	// CompanyProfiles returns every company profile, without
+	// their associations.
+	CompanyProfiles() ([]CompanyProfile, error)
 }
 
 // UserService is a set of methods used to manipulate and work
@@ -197,11 +310,21 @@ type userService struct {
 
 type userValFunc func(*User) error
 
+// runUserValFuncs stops at the first function failing, so
+// passwords are not hashed and emails not looked up once the
+// user is invalid. Field errors are returned in a
+// ValidationError, any other error as is.
 func runUserValFuncs(user *User, fns ...userValFunc) error {
 	for _, fn := range fns {
-		if err := fn(user); err != nil {
+		err := fn(user)
+		if err == nil {
+			continue
+		}
+		var verr ValidationError
+		if !verr.add(err) {
 			return err
 		}
+		return verr.err()
 	}
 
 	return nil
@@ -240,6 +363,14 @@ func (uv *userValidator) ByEmail(email string) (*User, error) {
 	return uv.UserDB.ByEmail(user.Email)
 }
 
+func (ug *userGorm) IsAdmin(user *User) (bool, error) {
+	var count int
+	err := ug.db.Model(&Role{}).
+		Where("id = ? AND role_name = ?", user.RoleID, RoleAdmin).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // Create will create the provided user and backfill data
 // like the ID, CreatedAt, and UpdatedAt fields.
 func (uv *userValidator) Create(user *User) error {
@@ -443,6 +574,27 @@ func (ug *userGorm) ByID(id uint) (*User, error) {
 
 }
 
+func (ug *userGorm) ByIDWith(id uint, include ...string) (*User, error) {
+	db := ug.db.Preload("CompanyProfile").Preload("Skills")
+	for _, name := range include {
+		switch name {
+		case "jobPosts":
+			db = db.Preload("JobPosts")
+		case "companyProfile.benefits":
+			db = db.Preload("CompanyProfile.CompanyBenefits")
+		case "companyProfile.skills":
+			db = db.Preload("CompanyProfile.Skills")
+		default:
+			return nil, ErrIncludeInvalid
+		}
+	}
+	var user User
+	if err := first(db.Where("id = ?", id), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // ByEmail looks up a user with a given email address and
 // returns that user.
 // If the user is found, we will return a nil error
@@ -477,31 +629,295 @@ func (ug *userGorm) ByRemember(rememberHash string) (*User, error) {
 // Create will create the provided user and backfill data
 // like the ID, CreatedAt, and UpdatedAt fields.
 func (ug *userGorm) Create(user *User) error {
-	return ug.db.Create(user).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventUserRegistered, aggregateUser, user.ID, user)
+	})
 }
 
 // Update will update the provided user with all of the data
 // in the provided the user object.
 func (ug *userGorm) Update(user *User) error {
-	return ug.db.Set("gorm:association_autoupdate", false).Save(user).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		profile := user.CompanyProfile
+		if profile != nil && profile.ID != 0 {
+			var stored CompanyProfile
+			err := tx.Select("user_id, company_name, slug, company_id, website, founded_year, description, company_logo_url, logo_thumbnail_url, logo_header_url, logo_keys, verified_domain, verification_method, verified_at").
+				Where("id = ?", profile.ID).First(&stored).Error
+			if err != nil {
+				return err
+			}
+			if profile.sameFields(stored) {
+				// Only the user changed, the profile is not saved
+				// and keeps its version
+				profile = nil
+			} else {
+				if err := bumpVersion(tx, "company_profiles", profile.ID, profile.Version); err != nil {
+					return err
+				}
+				profile.Version++
+				// The slug, the company, the logo and the
+				// verification can not be set by updates
+				profile.Slug = stored.Slug
+				profile.CompanyID = stored.CompanyID
+				profile.setLogo(stored)
+				if profile.Website == stored.Website {
+					profile.setVerification(stored)
+				} else {
+					profile.setVerification(CompanyProfile{})
+				}
+			}
+		} else if profile != nil {
+			profile.Version = 1
+			profile.CompanyID = 0
+			profile.setLogo(CompanyProfile{})
+			profile.setVerification(CompanyProfile{})
+		}
+		if err := tx.Set("gorm:association_autoupdate", false).Save(user).Error; err != nil {
+			return err
+		}
+		if profile != nil {
+			// Existing profiles are not saved along with the user
+			err := tx.Set("gorm:association_autoupdate", false).Save(profile).Error
+			if err != nil {
+				return err
+			}
+			if err := setCompanySlug(tx, profile); err != nil {
+				return err
+			}
+			if err := setProfileCompany(tx, profile); err != nil {
+				return err
+			}
+		}
+		return recordEvent(tx, EventUserUpdated, aggregateUser, user.ID, user)
+	})
 }
 
 // Delete will delete the user with the provided ID
 func (ug *userGorm) Delete(id uint) error {
-	user := User{Model: gorm.Model{ID: id}}
-	return ug.db.Delete(&user).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		user := User{Model: gorm.Model{ID: id}}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventUserDeleted, aggregateUser, id, DeletedEventPayload{ID: id})
+	})
+}
+
+func (ug *userGorm) Restore(id uint) error {
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		db := tx.Unscoped().Model(&User{}).Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if db.Error != nil {
+			return db.Error
+		}
+		if db.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		var user User
+		if err := first(tx.Where("id = ?", id), &user); err != nil {
+			return err
+		}
+		return recordEvent(tx, EventUserRestored, aggregateUser, id, user)
+	})
+}
+
+func (ug *userGorm) Purge(id uint) error {
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		var user User
+		if err := first(tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id), &user); err != nil {
+			return err
+		}
+		return purgeUsers(tx, []uint{id})
+	})
+}
+
+func (ug *userGorm) PurgeDeleted(before time.Time) (int, error) {
+	var ids []uint
+	err := transaction(ug.db, func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&User{}).Where("deleted_at < ?", before).Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		return purgeUsers(tx, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// purgeUsers permanently deletes the users along with their
+// data, their applications and their job posts.
+func purgeUsers(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := deleteUserData(tx, ids); err != nil {
+		return err
+	}
+	if err := purgeUserJobPosts(tx, ids); err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN (?)", ids).Delete(&User{}).Error
+}
+
+// purgeUserJobPosts purges the job posts of the users, except
+// the ones of a company other members still belong to, which
+// are only detached from the users.
+func purgeUserJobPosts(tx *gorm.DB, userIDs []uint) error {
+	kept := tx.Model(&CompanyMember{}).Select("company_id").QueryExpr()
+	err := tx.Unscoped().Model(&JobPost{}).
+		Where("user_id IN (?) AND company_id IN (?)", userIDs, kept).
+		UpdateColumn("user_id", 0).Error
+	if err != nil {
+		return err
+	}
+	var ids []uint
+	err = tx.Unscoped().Model(&JobPost{}).Where("user_id IN (?)", userIDs).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	return purgeJobPosts(tx, ids)
 }
 
 func (ug *userGorm) AddCompanyProfileBenefit(profile *CompanyProfile, benefit CompanyBenefit) error {
-	return ug.db.Model(profile).Association("CompanyBenefits").Append(benefit).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := tx.Model(profile).Association("CompanyBenefits").Append(&benefit).Error; err != nil {
+			return err
+		}
+		version, err := incrementVersion(tx, "company_profiles", profile.ID)
+		if err != nil {
+			return err
+		}
+		profile.Version = version
+		return recordEvent(tx, EventCompanyBenefitAdded, aggregateCompanyProfile, profile.ID, benefit)
+	})
 }
 
 func (ug *userGorm) RemoveCompanyProfileBenefit(profile *CompanyProfile, benefit CompanyBenefit) error {
-	return ug.db.Model(profile).Association("CompanyBenefits").Delete(benefit).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := tx.Model(profile).Association("CompanyBenefits").Delete(benefit).Error; err != nil {
+			return err
+		}
+		version, err := incrementVersion(tx, "company_profiles", profile.ID)
+		if err != nil {
+			return err
+		}
+		profile.Version = version
+		return recordEvent(tx, EventCompanyBenefitRemoved, aggregateCompanyProfile, profile.ID, benefit)
+	})
 }
 
 func (ug *userGorm) UpdateCompanyProfileBenefit(benefit *CompanyBenefit) error {
-	return ug.db.Save(benefit).Error
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := tx.Save(benefit).Error; err != nil {
+			return err
+		}
+		if _, err := incrementVersion(tx, "company_profiles", benefit.CompanyProfileID); err != nil {
+			return err
+		}
+		return recordEvent(tx, EventCompanyBenefitUpdated, aggregateCompanyProfile, benefit.CompanyProfileID, benefit)
+	})
+}
+
+func (ug *userGorm) CompanyProfileBySlug(slug string) (*CompanyProfile, error) {
+	var profile CompanyProfile
+	db := ug.db.Preload("CompanyBenefits").Preload("Skills").Where("slug = ?", slug)
+	err := first(db, &profile)
+
+	return &profile, err
+}
+
+func (ug *userGorm) CompanyProfileForJobPost(jobPost *JobPost) (*CompanyProfile, error) {
+	var profile CompanyProfile
+	db := ug.db.Where("user_id = ?", jobPost.UserID)
+	if jobPost.CompanyID != 0 {
+		db = ug.db.Where("company_id = ?", jobPost.CompanyID)
+	}
+	err := first(db.Preload("CompanyBenefits").Preload("Skills"), &profile)
+
+	return &profile, err
+}
+
+func (ug *userGorm) CompanyProfileByCompanyID(companyID uint) (*CompanyProfile, error) {
+	var profile CompanyProfile
+	db := ug.db.Preload("CompanyBenefits").Preload("Skills").Where("company_id = ?", companyID)
+	err := first(db, &profile)
+
+	return &profile, err
+}
+
+func (ug *userGorm) VerifyCompanyDomain(profile *CompanyProfile) error {
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, "company_profiles", profile.ID, profile.Version); err != nil {
+			return err
+		}
+		profile.Version++
+		err := tx.Model(profile).Updates(map[string]interface{}{
+			"verified_domain":     profile.VerifiedDomain,
+			"verification_method": profile.VerificationMethod,
+			"verified_at":         profile.VerifiedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		profile.Verified = profile.VerifiedAt != nil
+		return recordEvent(tx, EventCompanyDomainVerified, aggregateCompanyProfile, profile.ID, profile)
+	})
+}
+
+func (ug *userGorm) UpdateCompanyLogo(profile *CompanyProfile) error {
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, "company_profiles", profile.ID, profile.Version); err != nil {
+			return err
+		}
+		profile.Version++
+		err := tx.Model(profile).Updates(map[string]interface{}{
+			"company_logo_url":   profile.CompanyLogoUrl,
+			"logo_thumbnail_url": profile.LogoThumbnailUrl,
+			"logo_header_url":    profile.LogoHeaderUrl,
+			"logo_keys":          profile.LogoKeys,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordEvent(tx, EventCompanyLogoUpdated, aggregateCompanyProfile, profile.ID, profile)
+	})
+}
+
+func (ug *userGorm) SearchCompanyProfiles(query string, offset, limit int) ([]CompanyProfile, int, error) {
+	db := ug.db.Model(&CompanyProfile{}).Where("slug <> ''")
+	if query = strings.TrimSpace(query); query != "" {
+		like := fmt.Sprintf("%%%s%%", strings.ToUpper(query))
+		db = db.Where("UPPER(company_name) LIKE ? OR UPPER(description) LIKE ?", like, like)
+	}
+	var total int
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var profiles []CompanyProfile
+	err := db.Preload("CompanyBenefits").Preload("Skills").
+		Order("company_name").Order("id").
+		Offset(offset).Limit(limit).
+		Find(&profiles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return profiles, total, nil
+}
+
+func (ug *userGorm) CompanyProfiles() ([]CompanyProfile, error) {
+	var profiles []CompanyProfile
+	err := ug.db.Order("id").Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
 }
 
 // first will query using the provided gorm.DB and it will
@@ -514,3 +930,38 @@ func first(db *gorm.DB, dst interface{}) error {
 	}
 	return err
 }
+
+// bumpVersion increments the version of the row of table with
+// the provided ID, as long as it still is version. When another
+// request changed the row first, it will return
+// ErrVersionConflict.
+func bumpVersion(tx *gorm.DB, table string, id, version uint) error {
+	db := tx.Table(table).Where("id = ? AND version = ?", id, version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// incrementVersion increments the version of the row of table
+// with the provided ID, for the changes that are not made with
+// the version the client read, and returns the new version.
+func incrementVersion(tx *gorm.DB, table string, id uint) (uint, error) {
+	err := tx.Table(table).Where("id = ?", id).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+	var versions []uint
+	if err := tx.Table(table).Where("id = ?", id).Pluck("version", &versions).Error; err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, ErrNotFound
+	}
+	return versions[0], nil
+}
@@ -0,0 +1,331 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// accountDeletionGracePeriod is how long users can cancel the
+// deletion of their account
+const accountDeletionGracePeriod = 14 * 24 * time.Hour
+
+// UserExport is everything stored about a user, as returned by
+// Export. Secrets are left out: webhooks come without their
+// signing secret and API keys without their hash.
+type UserExport struct {
+	ExportedAt         time.Time         `json:"exportedAt"`
+	Account            ExportedAccount   `json:"account"`
+	CompanyProfile     *CompanyProfile   `json:"companyProfile"`
+	CompanyMemberships []CompanyMember   `json:"companyMemberships"`
+	JobPosts           []JobPost         `json:"jobPosts"`
+	JobFeeds           []JobFeed         `json:"jobFeeds"`
+	Applications       []Application     `json:"applications"`
+	Bookmarks          []Bookmark        `json:"bookmarks"`
+	SavedSearches      []SavedSearch     `json:"savedSearches"`
+	Webhooks           []Webhook         `json:"webhooks"`
+	APIKeys            []APIKey          `json:"apiKeys"`
+	OAuthConnections   []OAuthConnection `json:"oauthConnections"`
+}
+
+// ExportedAccount is the account of an exported user
+type ExportedAccount struct {
+	ID                  uint       `json:"id"`
+	Email               string     `json:"email"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+	Skills              []Skill    `json:"skills"`
+}
+
+// OAuthConnection is a service a user connected their account
+// to. The tokens are left out of exports, they are credentials
+// of the service.
+type OAuthConnection struct {
+	Service     string    `json:"service"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+func (ug *userGorm) Export(id uint) (*UserExport, error) {
+	var user User
+	if err := first(ug.db.Preload("Skills").Where("id = ?", id), &user); err != nil {
+		return nil, err
+	}
+	export := UserExport{
+		ExportedAt: time.Now(),
+		Account: ExportedAccount{
+			ID:                  user.ID,
+			Email:               user.Email,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+			Skills:              user.Skills,
+		},
+		CompanyMemberships: []CompanyMember{},
+		JobPosts:           []JobPost{},
+		JobFeeds:           []JobFeed{},
+		Applications:       []Application{},
+		Bookmarks:          []Bookmark{},
+		SavedSearches:      []SavedSearch{},
+		Webhooks:           []Webhook{},
+		APIKeys:            []APIKey{},
+		OAuthConnections:   []OAuthConnection{},
+	}
+	if export.Account.Skills == nil {
+		export.Account.Skills = []Skill{}
+	}
+
+	var profile CompanyProfile
+	db := ug.db.Preload("CompanyBenefits").Preload("Skills").Where("user_id = ?", id)
+	switch err := first(db, &profile); err {
+	case nil:
+		export.CompanyProfile = &profile
+	case ErrNotFound:
+	default:
+		return nil, err
+	}
+	// Deleted job posts are exported until they are purged
+	err := ug.db.Unscoped().Preload("Skills").Where("user_id = ?", id).Order("id").Find(&export.JobPosts).Error
+	if err != nil {
+		return nil, err
+	}
+	// The alert settings of saved searches are exported with
+	// them, alerts are emailed to the account email
+	for _, rows := range []interface{}{
+		&export.CompanyMemberships,
+		&export.JobFeeds,
+		&export.Bookmarks,
+		&export.SavedSearches,
+		&export.Webhooks,
+		&export.APIKeys,
+	} {
+		if err := ug.db.Where("user_id = ?", id).Order("id").Find(rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	for i := range export.Webhooks {
+		export.Webhooks[i].Secret = ""
+	}
+	err = ug.db.Preload("JobPost").Where("user_id = ?", id).Order("id").Find(&export.Applications).Error
+	if err != nil {
+		return nil, err
+	}
+	var oauths []OAuth
+	if err := ug.db.Where("user_id = ?", id).Order("id").Find(&oauths).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range oauths {
+		export.OAuthConnections = append(export.OAuthConnections, OAuthConnection{
+			Service:     o.Service,
+			ConnectedAt: o.CreatedAt,
+			Expiry:      o.Expiry,
+		})
+	}
+	return &export, nil
+}
+
+func (ug *userGorm) ScheduleDeletion(user *User) error {
+	at := time.Now().Add(accountDeletionGracePeriod)
+	if err := ug.db.Model(user).UpdateColumn("deletion_scheduled_at", &at).Error; err != nil {
+		return err
+	}
+	user.DeletionScheduledAt = &at
+	return nil
+}
+
+func (ug *userGorm) CancelDeletion(user *User) error {
+	if err := ug.db.Model(user).UpdateColumn("deletion_scheduled_at", nil).Error; err != nil {
+		return err
+	}
+	user.DeletionScheduledAt = nil
+	return nil
+}
+
+func (ug *userGorm) EraseScheduled(now time.Time) (int, error) {
+	var ids []uint
+	err := ug.db.Model(&User{}).Where("deletion_scheduled_at <= ?", now).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := ug.Erase(id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (ug *userGorm) Erase(id uint) error {
+	return transaction(ug.db, func(tx *gorm.DB) error {
+		var user User
+		if err := first(tx.Where("id = ?", id), &user); err != nil {
+			return err
+		}
+		// The email stays unique, and no password matches an
+		// empty hash
+		err := tx.Model(&user).UpdateColumns(map[string]interface{}{
+			"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", id),
+			"password_hash":         "",
+			"password":              "",
+			"remember_hash":         "",
+			"deletion_scheduled_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := deleteUserData(tx, []uint{id}); err != nil {
+			return err
+		}
+		if err := deleteUserJobPosts(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, EventUserDeleted, aggregateUser, id, DeletedEventPayload{ID: id})
+	})
+}
+
+// userOwnedTables hold rows that belong to a single user through
+// their user_id, deleteUserData deletes them.
+var userOwnedTables = []string{
+	"o_auths", "pw_resets", "user_skills", "bookmarks",
+	"saved_searches", "applications", "import_jobs", "api_keys",
+	"webhooks", "job_feeds",
+}
+
+// deleteUserData deletes the data of the users, for both Erase
+// and Purge: their company memberships, company profiles, the
+// alerts sent for their saved searches, the row errors of their
+// imports, the deliveries of their webhooks and the rows of
+// userOwnedTables.
+func deleteUserData(tx *gorm.DB, ids []uint) error {
+	if err := leaveCompanies(tx, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := eraseCompanyProfile(tx, id); err != nil {
+			return err
+		}
+	}
+	searches := tx.Model(&SavedSearch{}).Unscoped().Where("user_id IN (?)", ids).Select("id").QueryExpr()
+	if err := tx.Where("saved_search_id IN (?)", searches).Delete(&SentAlert{}).Error; err != nil {
+		return err
+	}
+	imports := tx.Model(&ImportJob{}).Unscoped().Where("user_id IN (?)", ids).Select("id").QueryExpr()
+	if err := tx.Where("import_job_id IN (?)", imports).Delete(&ImportRowError{}).Error; err != nil {
+		return err
+	}
+	hooks := tx.Model(&Webhook{}).Unscoped().Where("user_id IN (?)", ids).Select("id").QueryExpr()
+	if err := tx.Unscoped().Where("webhook_id IN (?)", hooks).Delete(&WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	for _, table := range userOwnedTables {
+		if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE user_id IN (?)", table), ids).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUserJobPosts deletes the job posts of the user without
+// a company, the job posts of a company stay with the company.
+func deleteUserJobPosts(tx *gorm.DB, userID uint) error {
+	var ids []uint
+	err := tx.Model(&JobPost{}).
+		Where("user_id = ? AND (company_id IS NULL OR company_id = 0)", userID).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.Delete(&JobPost{Model: gorm.Model{ID: id}}).Error; err != nil {
+			return err
+		}
+		if err := recordEvent(tx, EventJobPostDeleted, aggregateJobPost, id, DeletedEventPayload{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaveCompanies removes the users from their companies. When
+// the last owner of a company leaves, the remaining member who
+// joined first becomes its owner.
+func leaveCompanies(tx *gorm.DB, ids []uint) error {
+	var members []CompanyMember
+	if err := tx.Where("user_id IN (?)", ids).Find(&members).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id IN (?)", ids).Delete(&CompanyMember{}).Error; err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := recordEvent(tx, EventCompanyMemberRemoved, aggregateCompany, member.CompanyID, member); err != nil {
+			return err
+		}
+		if member.Role != MemberOwner {
+			continue
+		}
+		var owners int
+		err := tx.Model(&CompanyMember{}).
+			Where("company_id = ? AND role = ?", member.CompanyID, MemberOwner).
+			Count(&owners).Error
+		if err != nil {
+			return err
+		}
+		if owners > 0 {
+			continue
+		}
+		var heir CompanyMember
+		switch err := first(tx.Where("company_id = ?", member.CompanyID).Order("id"), &heir); err {
+		case nil:
+		case ErrNotFound:
+			continue
+		default:
+			return err
+		}
+		if err := tx.Model(&heir).Update("role", MemberOwner).Error; err != nil {
+			return err
+		}
+		if err := recordEvent(tx, EventCompanyMemberUpdated, aggregateCompany, heir.CompanyID, heir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eraseCompanyProfile deletes the company profile of the user,
+// unless other members of its company still use it, in which
+// case it is only detached from the user.
+func eraseCompanyProfile(tx *gorm.DB, userID uint) error {
+	var profile CompanyProfile
+	switch err := first(tx.Where("user_id = ?", userID), &profile); err {
+	case nil:
+	case ErrNotFound:
+		return nil
+	default:
+		return err
+	}
+	if profile.CompanyID != 0 {
+		var others int
+		err := tx.Model(&CompanyMember{}).
+			Where("company_id = ? AND user_id <> ?", profile.CompanyID, userID).
+			Count(&others).Error
+		if err != nil {
+			return err
+		}
+		if others > 0 {
+			return tx.Model(&profile).UpdateColumn("user_id", 0).Error
+		}
+	}
+	if err := tx.Unscoped().Where("company_profile_id = ?", profile.ID).Delete(&CompanyBenefit{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`DELETE FROM "companyProfile_skills" WHERE company_profile_id = ?`, profile.ID).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&profile).Error
+}
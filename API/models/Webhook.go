@@ -0,0 +1,336 @@
+package models
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/samueldaviddelacruz/go-job-board/API/rand"
+)
+
+// WebhookEvent is the type of an event sent to webhooks
+type WebhookEvent string
+
+const (
+	WebhookJobPostCreated      WebhookEvent = "job_post.created"
+	WebhookJobPostUpdated      WebhookEvent = "job_post.updated"
+	WebhookJobPostPublished    WebhookEvent = "job_post.published"
+	WebhookJobPostClosed       WebhookEvent = "job_post.closed"
+	WebhookApplicationReceived WebhookEvent = "application.received"
+	// WebhookPing is only sent by the "send test event" endpoint
+	WebhookPing WebhookEvent = "ping"
+)
+
+// WebhookEvents are the events a webhook can subscribe to
+var WebhookEvents = []WebhookEvent{
+	WebhookJobPostCreated,
+	WebhookJobPostUpdated,
+	WebhookJobPostPublished,
+	WebhookJobPostClosed,
+	WebhookApplicationReceived,
+}
+
+const webhookSecretBytes = 32
+
+// Webhook is an URL of a user notified about the events of the
+// job posts they manage, their own and the ones of the companies
+// they manage the job posts of. Payloads are signed with the
+// Secret, which is only returned when the webhook is created.
+type Webhook struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index" json:"userId"`
+	URL    string `gorm:"not null" json:"url"`
+	Secret string `gorm:"not null" json:"secret,omitempty"`
+	// Events is a comma separated list of WebhookEvent
+	Events string `gorm:"not null" json:"events"`
+}
+
+// Subscribed reports whether the webhook should be notified
+// about the event. Every webhook receives ping events.
+func (wh *Webhook) Subscribed(event WebhookEvent) bool {
+	if event == WebhookPing {
+		return true
+	}
+	for _, e := range strings.Split(wh.Events, ",") {
+		if WebhookEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an attempt to send an event to a webhook,
+// it is retried until it succeeds or NextAttemptAt is cleared.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID     uint         `gorm:"not null;index" json:"webhookId"`
+	Event         WebhookEvent `gorm:"not null" json:"event"`
+	Payload       string       `gorm:"type:text;not null" json:"payload"`
+	Attempts      uint         `json:"attempts"`
+	StatusCode    int          `json:"statusCode,omitempty"`
+	LastError     string       `json:"lastError,omitempty"`
+	NextAttemptAt *time.Time   `gorm:"index" json:"nextAttemptAt,omitempty"`
+	DeliveredAt   *time.Time   `json:"deliveredAt,omitempty"`
+}
+
+type WebhookService interface {
+	WebhookDB
+}
+
+type WebhookDB interface {
+	ByID(id uint) (*Webhook, error)
+	ByUserID(userID uint) ([]Webhook, error)
+	// Subscribed returns the webhooks of the user subscribed to
+	// the event.
+	Subscribed(userID uint, event WebhookEvent) ([]Webhook, error)
+	Create(webhook *Webhook) error
+	Delete(id uint) error
+
+	// Deliveries returns the latest deliveries of the webhook
+	Deliveries(webhookID uint, limit int) ([]WebhookDelivery, error)
+	// PendingDeliveries returns the deliveries due for another
+	// attempt.
+	PendingDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+	CreateDelivery(delivery *WebhookDelivery) error
+	UpdateDelivery(delivery *WebhookDelivery) error
+}
+
+func NewWebhookService(db *gorm.DB) WebhookService {
+	return &webhookValidator{
+		&webhookGorm{db},
+	}
+}
+
+type webhookValidator struct {
+	WebhookDB
+}
+
+func (wv *webhookValidator) Create(webhook *Webhook) error {
+	err := runWebhookValFuncs(webhook,
+		wv.userIDRequired,
+		wv.urlValid,
+		wv.eventsValid,
+		wv.setSecretIfUnset)
+	if err != nil {
+		return err
+	}
+
+	return wv.WebhookDB.Create(webhook)
+}
+
+func (wv *webhookValidator) Delete(id uint) error {
+	if id <= 0 {
+		return ErrIDInvalid
+	}
+
+	return wv.WebhookDB.Delete(id)
+}
+
+func (wv *webhookValidator) userIDRequired(wh *Webhook) error {
+	if wh.UserID <= 0 {
+		return ErrUserIDRequired
+	}
+	return nil
+}
+
+// urlValid only accepts https URLs of public hosts, so webhooks
+// can not be used to reach the network of the job board. Names
+// are resolved when payloads are sent, the sender checks the
+// addresses they resolve to.
+func (wv *webhookValidator) urlValid(wh *Webhook) error {
+	u, ok := publicURL(wh.URL)
+	if !ok {
+		return ErrWebhookURLInvalid
+	}
+	wh.URL = u.String()
+	return nil
+}
+
+// publicURL parses an absolute https URL whose host is neither
+// localhost nor an address that is not public. Names resolving
+// to such addresses are only refused when connecting.
+func publicURL(rawURL string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, false
+	}
+	if ip := net.ParseIP(host); ip != nil && !PublicIP(ip) {
+		return nil, false
+	}
+	return u, true
+}
+
+// privateNetworks are the IPv4 and IPv6 ranges used by private
+// networks, including carrier-grade NAT.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("fc00::/7"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// PublicIP reports whether ip can be reached from the internet,
+// loopback, link-local, private and unspecified addresses are
+// not.
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// eventsValid subscribes the webhook to every event when none
+// is provided.
+func (wv *webhookValidator) eventsValid(wh *Webhook) error {
+	if strings.TrimSpace(wh.Events) == "" {
+		var all []string
+		for _, event := range WebhookEvents {
+			all = append(all, string(event))
+		}
+		wh.Events = strings.Join(all, ",")
+		return nil
+	}
+
+	var events []string
+	for _, e := range strings.Split(wh.Events, ",") {
+		e = strings.TrimSpace(e)
+		known := false
+		for _, event := range WebhookEvents {
+			if WebhookEvent(e) == event {
+				known = true
+			}
+		}
+		if !known {
+			return ErrWebhookEventInvalid
+		}
+		events = append(events, e)
+	}
+	wh.Events = strings.Join(events, ",")
+	return nil
+}
+
+func (wv *webhookValidator) setSecretIfUnset(wh *Webhook) error {
+	if wh.Secret != "" {
+		return nil
+	}
+	secret, err := rand.String(webhookSecretBytes)
+	if err != nil {
+		return err
+	}
+	wh.Secret = secret
+	return nil
+}
+
+var _ WebhookDB = &webhookGorm{}
+
+type webhookGorm struct {
+	db *gorm.DB
+}
+
+func (wg *webhookGorm) ByID(id uint) (*Webhook, error) {
+	var webhook Webhook
+	err := first(wg.db.Where("id = ?", id), &webhook)
+
+	return &webhook, err
+}
+
+func (wg *webhookGorm) ByUserID(userID uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := wg.db.Where("user_id = ?", userID).Find(&webhooks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (wg *webhookGorm) Subscribed(userID uint, event WebhookEvent) ([]Webhook, error) {
+	webhooks, err := wg.ByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	var subscribed []Webhook
+	for _, wh := range webhooks {
+		if wh.Subscribed(event) {
+			subscribed = append(subscribed, wh)
+		}
+	}
+
+	return subscribed, nil
+}
+
+func (wg *webhookGorm) Create(webhook *Webhook) error {
+	return wg.db.Create(webhook).Error
+}
+
+func (wg *webhookGorm) Delete(id uint) error {
+	webhook := Webhook{Model: gorm.Model{ID: id}}
+	return wg.db.Delete(&webhook).Error
+}
+
+func (wg *webhookGorm) Deliveries(webhookID uint, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := wg.db.Where("webhook_id = ?", webhookID).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (wg *webhookGorm) PendingDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := wg.db.Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (wg *webhookGorm) CreateDelivery(delivery *WebhookDelivery) error {
+	return wg.db.Create(delivery).Error
+}
+
+func (wg *webhookGorm) UpdateDelivery(delivery *WebhookDelivery) error {
+	return wg.db.Save(delivery).Error
+}
+
+type webhookValFunc func(*Webhook) error
+
+func runWebhookValFuncs(webhook *Webhook, fns ...webhookValFunc) error {
+	for _, fn := range fns {
+		if err := fn(webhook); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -31,7 +31,7 @@ const (
 	ErrPasswordRequired    modelError = "models: password is required"
 	ErrTitleRequired       modelError = "models: title is required"
 	ErrDescriptionRequired modelError = "models: description is required"
-	ErrApplyAtRequired     modelError = "models: description is required"
+	ErrApplyAtRequired     modelError = "models: applyAt is required"
 	ErrPwResetInvalid      modelError = "models: token provided is not valid"
 
 	// ErrRememberTooShort is returned when a remember token is
@@ -42,8 +42,9 @@ const (
 	// without a user remember token hash.
 	ErrRememberRequired   privateError = "models: remember is required"
 	ErrUserIDRequired     privateError = "models: user ID is required"
-	ErrLocationIDRequired privateError = "models: Location ID is required"
-	ErrCategoryIDRequired privateError = "models: Category ID is required"
+	ErrLocationIDRequired modelError   = "models: Location ID is required"
+	ErrCategoryIDRequired modelError   = "models: Category ID is required"
+	ErrCompanyIDRequired  modelError   = "models: company ID is required"
 	// ErrIDInvalid is returned when an invalid ID is provided
 	// to a method like Delete.
 	ErrIDInvalid privateError = "models: ID provided was invalid"
@@ -53,6 +54,66 @@ const (
 	ErrCompanyBenefitRequired modelError = "models: cannot update non existent CompanyBenefit"
 	ErrBenefitNameRequired    modelError = "models: benefitName is required"
 	ErrCompanyProfileRequired modelError = "models: cannot add benefit to non existent profile"
+
+	// ErrSkillNameRequired is returned when a skill or alias is
+	// created without a name.
+	ErrSkillNameRequired  modelError = "models: skill name is required"
+	ErrSkillAliasRequired modelError = "models: skill alias is required"
+	// ErrSkillNameTaken is returned when a skill or alias would
+	// resolve to a skill that already exists in the taxonomy.
+	ErrSkillNameTaken modelError = "models: skill already exists"
+	// ErrSkillUnknown is returned when a skill name provided
+	// does not match any skill of the taxonomy.
+	ErrSkillUnknown modelError = "models: skill is not part of the taxonomy"
+	// ErrProficiencyInvalid is returned when a skill requirement
+	// uses an unknown proficiency level.
+	ErrProficiencyInvalid modelError = "models: minProficiency must be one of beginner, intermediate, advanced or expert"
+
+	// ErrQueryInvalid is returned when a saved search query is
+	// not a valid query string.
+	ErrQueryInvalid     modelError = "models: query is not valid"
+	ErrFrequencyInvalid modelError = "models: frequency must be one of instant, daily or none"
+	// ErrUnsubscribeTokenInvalid is returned when an unsubscribe
+	// link was not signed by us.
+	ErrUnsubscribeTokenInvalid modelError = "models: unsubscribe link is not valid"
+
+	// ErrWebhookURLInvalid is returned when a webhook URL is not
+	// an absolute https URL of a public host.
+	ErrWebhookURLInvalid   modelError = "models: url must be an absolute https URL of a public host"
+	ErrWebhookEventInvalid modelError = "models: events contains an unknown event type"
+
+	// ErrAPIKeyInvalid is returned when an API key is unknown,
+	// revoked or does not match its hash.
+	ErrAPIKeyInvalid      modelError = "models: API key is not valid"
+	ErrAPIKeyScopeInvalid modelError = "models: scopes must be a list of jobs:read, jobs:write or applications:read"
+
+	// ErrJobFeedURLInvalid is returned when a job feed URL is
+	// not an absolute https URL of a public host.
+	ErrJobFeedURLInvalid modelError = "models: feed url must be an absolute https URL of a public host"
+
+	// ErrSalaryInvalid is returned when a salary is missing its
+	// currency or period, or when its range is reversed.
+	ErrSalaryInvalid       modelError = "models: salary needs an amount, a currency code and a period of HOUR, DAY, WEEK, MONTH or YEAR"
+	ErrValidThroughInvalid modelError = "models: validThrough must be after the publication date"
+
+	// ErrMemberRoleInvalid is returned when a company member or
+	// invitation uses an unknown role.
+	ErrMemberRoleInvalid modelError = "models: role must be one of owner, admin, recruiter or viewer"
+	// ErrMemberRoleForbidden is returned when the role of a user
+	// within a company does not allow the change.
+	ErrMemberRoleForbidden modelError = "models: your role in the company does not allow this"
+	ErrMemberExists        modelError = "models: user is already a member of the company"
+	ErrLastOwner           modelError = "models: a company must keep at least one owner"
+	// ErrInvitationInvalid is returned when an invitation token
+	// is unknown, revoked, expired or already accepted.
+	ErrInvitationInvalid       modelError = "models: invitation is not valid or has expired"
+	ErrInvitationEmailMismatch modelError = "models: invitation was sent to another email address"
+	// ErrIncludeInvalid is returned when asked to include an
+	// association that is not available.
+	ErrIncludeInvalid modelError = "models: include lists an association that is not available"
+	// ErrVersionConflict is returned when a job post or company
+	// profile changed since the version being updated was read.
+	ErrVersionConflict modelError = "models: resource was changed by another request, reload it and try again"
 )
 
 type modelError string
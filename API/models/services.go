@@ -57,6 +57,69 @@ func WithSkill() ServicesConfig {
 	}
 }
 
+func WithSavedSearch(hmacKey string) ServicesConfig {
+	return func(s *Services) error {
+		s.SavedSearch = NewSavedSearchService(s.db, hmacKey)
+		return nil
+	}
+}
+
+func WithBookmark() ServicesConfig {
+	return func(s *Services) error {
+		s.Bookmark = NewBookmarkService(s.db)
+		return nil
+	}
+}
+
+func WithApplication() ServicesConfig {
+	return func(s *Services) error {
+		s.Application = NewApplicationService(s.db)
+		return nil
+	}
+}
+
+func WithWebhook() ServicesConfig {
+	return func(s *Services) error {
+		s.Webhook = NewWebhookService(s.db)
+		return nil
+	}
+}
+
+func WithAPIKey(hmacKey string) ServicesConfig {
+	return func(s *Services) error {
+		s.APIKey = NewAPIKeyService(s.db, hmacKey)
+		return nil
+	}
+}
+
+func WithImportJob() ServicesConfig {
+	return func(s *Services) error {
+		s.ImportJob = NewImportJobService(s.db)
+		return nil
+	}
+}
+
+func WithJobFeed() ServicesConfig {
+	return func(s *Services) error {
+		s.JobFeed = NewJobFeedService(s.db)
+		return nil
+	}
+}
+
+func WithCompany(hmacKey string) ServicesConfig {
+	return func(s *Services) error {
+		s.Company = NewCompanyService(s.db, hmacKey)
+		return nil
+	}
+}
+
+func WithEvent() ServicesConfig {
+	return func(s *Services) error {
+		s.Event = NewEventService(s.db)
+		return nil
+	}
+}
+
 func WithOAuth() ServicesConfig {
 	return func(s *Services) error {
 
@@ -84,13 +147,22 @@ func NewServices(cfgs ...ServicesConfig) (*Services, error) {
 }
 
 type Services struct {
-	JobPost  JobPostService
-	Category CategoryService
-	Location LocationService
-	User     UserService
-	Skill    SkillsService
-	OAuth    OAuthService
-	db       *gorm.DB
+	JobPost     JobPostService
+	Category    CategoryService
+	Location    LocationService
+	User        UserService
+	Skill       SkillsService
+	OAuth       OAuthService
+	SavedSearch SavedSearchService
+	Bookmark    BookmarkService
+	Application ApplicationService
+	Webhook     WebhookService
+	Event       EventService
+	APIKey      APIKeyService
+	ImportJob   ImportJobService
+	JobFeed     JobFeedService
+	Company     CompanyService
+	db          *gorm.DB
 }
 
 // Close closes the database connection
@@ -101,24 +173,79 @@ func (s *Services) Close() error {
 // AutoMigrate will attempt to automatically migrate
 // all tables
 func (s *Services) AutoMigrate() error {
+	// Job posts were announced as soon as they were created
+	// until they had an announcement date
+	announced := s.db.Dialect().HasColumn("job_posts", "announced_at")
 	err := s.db.AutoMigrate(
 		&User{},
 		&Role{},
 		&Location{},
 		&Category{},
 		&JobPost{},
+		&JobPostSkill{},
+		&JobPostRevision{},
 		&Skill{},
+		&SkillAlias{},
 		&CompanyProfile{},
 		&CompanyBenefit{},
 		&pwReset{},
-		&OAuth{}).Error
+		&OAuth{},
+		&SavedSearch{},
+		&SentAlert{},
+		&Bookmark{},
+		&Application{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&DomainEvent{},
+		&APIKey{},
+		&ImportJob{},
+		&ImportRowError{},
+		&JobFeed{},
+		&Company{},
+		&CompanyMember{},
+		&CompanyInvitation{}).Error
 	if err != nil {
 		return err
 	}
-	return runPopulatingFuncs(s.seedRoles, s.seedLocations, s.seedCategories, s.seedSkills)
+	fns := []populatingFunc{s.indexFeedEntries, s.seedRoles, s.seedLocations, s.seedCategories, s.seedSkills, s.backfillSlugs, s.backfillCompanies, s.backfillPublishedAt}
+	if !announced {
+		fns = append(fns, s.backfillAnnouncements)
+	}
+	return runPopulatingFuncs(fns...)
+}
+
+// indexFeedEntries makes the entries of job feeds unique, the
+// index is partial as every job post created by hand has the
+// same empty reference.
+func (s *Services) indexFeedEntries() error {
+	return s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_job_post_feed_entry
+		ON job_posts (job_feed_id, external_ref) WHERE job_feed_id <> 0`).Error
 }
+
+// backfillPublishedAt publishes the job posts created before
+// they had a publication date, so they stay listed.
+func (s *Services) backfillPublishedAt() error {
+	return s.db.Exec("UPDATE job_posts SET published_at = created_at WHERE published_at IS NULL").Error
+}
+
+func (s *Services) backfillAnnouncements() error {
+	return s.db.Exec("UPDATE job_posts SET announced_at = created_at WHERE announced_at IS NULL AND published_at IS NOT NULL").Error
+}
+func (s *Services) backfillSlugs() error {
+	return backfillSlugs(s.db)
+}
+
+func (s *Services) backfillCompanies() error {
+	return backfillCompanies(s.db)
+}
+
 func (s *Services) seedRoles() error {
-	return s.db.Model(&Role{}).Create(&Role{RoleName: "User"}).Create(&Role{RoleName: "Candidate"}).Error
+	for _, name := range []string{"User", "Candidate", RoleAdmin} {
+		if err := s.db.FirstOrCreate(&Role{}, Role{RoleName: name}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Services) seedLocations() error {
@@ -156,25 +283,99 @@ func (s *Services) seedCategories() error {
 	}
 	return db.Error
 }
+func (s *Services) GetSkillsSeed() []Skill {
+	return []Skill{
+		newSeedSkill("JavaScript", "Language", "js", "ecmascript"),
+		newSeedSkill("Golang", "Language", "go", "go lang"),
+		newSeedSkill("TypeScript", "Language", "ts"),
+		newSeedSkill("Python", "Language", "py"),
+		newSeedSkill("Java", "Language"),
+		newSeedSkill("React", "Framework", "react.js", "reactjs"),
+		newSeedSkill("Vue.js", "Framework", "vue"),
+		newSeedSkill("Node.js", "Runtime", "node"),
+		newSeedSkill("PostgreSQL", "Database", "postgres", "psql"),
+		newSeedSkill("Docker", "DevOps"),
+		newSeedSkill("Kubernetes", "DevOps", "k8s"),
+	}
+}
+
+// getRelatedSkillsSeed returns pairs of related skill names
+func (s *Services) getRelatedSkillsSeed() [][2]string {
+	return [][2]string{
+		{"JavaScript", "TypeScript"},
+		{"JavaScript", "React"},
+		{"JavaScript", "Vue.js"},
+		{"JavaScript", "Node.js"},
+		{"Docker", "Kubernetes"},
+	}
+}
+
+func newSeedSkill(name, category string, aliases ...string) Skill {
+	skill := Skill{
+		SkillName:      name,
+		NormalizedName: NormalizeSkillName(name),
+		Category:       category,
+	}
+	for _, alias := range aliases {
+		skill.Aliases = append(skill.Aliases, SkillAlias{
+			Alias:           alias,
+			NormalizedAlias: NormalizeSkillName(alias),
+		})
+	}
+	return skill
+}
+
 func (s *Services) seedSkills() error {
-	return s.db.Model(&Skill{}).
-		Create(&Skill{SkillName: "JavaScript"}).
-		Create(&Skill{SkillName: "Golang"}).Error
+	db := s.db.Model(&Skill{})
+	skillsSeed := s.GetSkillsSeed()
+	byName := make(map[string]*Skill, len(skillsSeed))
+	for i := range skillsSeed {
+		db = db.Create(&skillsSeed[i])
+		byName[skillsSeed[i].SkillName] = &skillsSeed[i]
+	}
+	if db.Error != nil {
+		return db.Error
+	}
+	sg := skillsGorm{s.db}
+	for _, pair := range s.getRelatedSkillsSeed() {
+		if err := sg.AddRelatedSkill(byName[pair[0]], *byName[pair[1]]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DestructiveReset drops the all tables and rebuilds them
 func (s *Services) DestructiveReset() error {
-	err := s.db.Exec("DROP TABLE IF EXISTS job_post_skills;").DropTableIfExists(
+	err := s.db.Exec("DROP TABLE IF EXISTS job_post_skills;").
+		Exec("DROP TABLE IF EXISTS skill_related_skills;").
+		Exec("DROP TABLE IF EXISTS user_skills;").DropTableIfExists(
 		&User{},
 		&Role{},
 		&JobPost{},
+		&JobPostRevision{},
 		&Category{},
 		&Location{},
 		&Skill{},
+		&SkillAlias{},
 		&CompanyProfile{},
 		&CompanyBenefit{},
 		&pwReset{},
-		&OAuth{}).Error
+		&OAuth{},
+		&SavedSearch{},
+		&SentAlert{},
+		&Bookmark{},
+		&Application{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&DomainEvent{},
+		&APIKey{},
+		&ImportJob{},
+		&ImportRowError{},
+		&JobFeed{},
+		&Company{},
+		&CompanyMember{},
+		&CompanyInvitation{}).Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// maxSlugLength is the length slugs are cut at, before the
+// suffix keeping them unique.
+const maxSlugLength = 60
+
+// Slugify turns s into lowercase words of ASCII letters and
+// digits separated by hyphens.
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		default:
+			hyphen = true
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// jobPostSlug is the title of the job post followed by its ID,
+// which keeps it unique without looking up other job posts.
+func jobPostSlug(jp *JobPost) string {
+	if title := Slugify(jp.Title); title != "" {
+		return fmt.Sprintf("%s-%d", title, jp.ID)
+	}
+	return fmt.Sprintf("job-%d", jp.ID)
+}
+
+// companySlug is the name of the company, followed by a number
+// when another company already uses it.
+func companySlug(db *gorm.DB, profile *CompanyProfile) (string, error) {
+	base := Slugify(profile.CompanyName)
+	if base == "" {
+		base = "company"
+	}
+	slug := base
+	for n := 2; ; n++ {
+		var count int
+		err := db.Model(&CompanyProfile{}).
+			Where("slug = ? AND id <> ?", slug, profile.ID).
+			Count(&count).Error
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// setJobPostSlug assigns the job post its slug, once it has an
+// ID. A slug never changes once assigned.
+func setJobPostSlug(db *gorm.DB, jp *JobPost) error {
+	if jp.Slug != "" {
+		return nil
+	}
+	jp.Slug = jobPostSlug(jp)
+	return db.Model(jp).UpdateColumn("slug", jp.Slug).Error
+}
+
+// setCompanySlug assigns the company profile its slug once it
+// is named. A slug never changes once assigned.
+func setCompanySlug(db *gorm.DB, profile *CompanyProfile) error {
+	if profile.Slug != "" || strings.TrimSpace(profile.CompanyName) == "" {
+		return nil
+	}
+	slug, err := companySlug(db, profile)
+	if err != nil {
+		return err
+	}
+	profile.Slug = slug
+	return db.Model(profile).UpdateColumn("slug", profile.Slug).Error
+}
+
+// backfillSlugs assigns a slug to the job posts and company
+// profiles created before slugs existed.
+func backfillSlugs(db *gorm.DB) error {
+	var jobPosts []JobPost
+	if err := db.Where("slug = '' OR slug IS NULL").Find(&jobPosts).Error; err != nil {
+		return err
+	}
+	for i := range jobPosts {
+		if err := setJobPostSlug(db, &jobPosts[i]); err != nil {
+			return err
+		}
+	}
+	var profiles []CompanyProfile
+	if err := db.Where("slug = '' OR slug IS NULL").Order("id").Find(&profiles).Error; err != nil {
+		return err
+	}
+	for i := range profiles {
+		if err := setCompanySlug(db, &profiles[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
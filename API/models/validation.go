@@ -0,0 +1,122 @@
+package models
+
+import "strings"
+
+// Codes of the field errors
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeTooShort = "too_short"
+	CodeTaken    = "taken"
+)
+
+// FieldError describes what is wrong with a single field of a
+// request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type fieldError struct {
+	field string
+	code  string
+}
+
+// fieldErrors are the validation errors about a single field,
+// the job post validators keep going after them to report every
+// field at once, the user ones stop at the first.
+var fieldErrors = map[modelError]fieldError{
+	ErrTitleRequired:       {"title", CodeRequired},
+	ErrDescriptionRequired: {"description", CodeRequired},
+	ErrApplyAtRequired:     {"applyAt", CodeRequired},
+	ErrLocationIDRequired:  {"locationId", CodeRequired},
+	ErrCategoryIDRequired:  {"categoryId", CodeRequired},
+	ErrSalaryInvalid:       {"salary", CodeInvalid},
+	ErrValidThroughInvalid: {"validThrough", CodeInvalid},
+	ErrEmailRequired:       {"email", CodeRequired},
+	ErrEmailInvalid:        {"email", CodeInvalid},
+	ErrEmailTaken:          {"email", CodeTaken},
+	ErrPasswordRequired:    {"password", CodeRequired},
+	ErrPasswordTooShort:    {"password", CodeTooShort},
+}
+
+// ValidationError holds the field errors found validating a
+// model, in the order they were checked. Only the first error
+// of each field is kept. Every error is one of fieldErrors.
+type ValidationError struct {
+	Errors []error
+}
+
+func (ve *ValidationError) Error() string {
+	messages := make([]string, len(ve.Errors))
+	for i, err := range ve.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Contains reports whether err is one of the field errors
+func (ve *ValidationError) Contains(err error) bool {
+	for _, e := range ve.Errors {
+		if e == err {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflict reports whether every field error is about a value
+// already taken, rather than an invalid one.
+func (ve *ValidationError) Conflict() bool {
+	for _, err := range ve.Errors {
+		if fieldErrors[err.(modelError)].code != CodeTaken {
+			return false
+		}
+	}
+	return len(ve.Errors) > 0
+}
+
+// Fields describes the field errors, with their public message
+func (ve *ValidationError) Fields() []FieldError {
+	fields := make([]FieldError, 0, len(ve.Errors))
+	for _, err := range ve.Errors {
+		me := err.(modelError)
+		fe := fieldErrors[me]
+		fields = append(fields, FieldError{
+			Field:   fe.field,
+			Code:    fe.code,
+			Message: me.Public(),
+		})
+	}
+	return fields
+}
+
+// add adds err if it is a field error, reporting whether it
+// was one.
+func (ve *ValidationError) add(err error) bool {
+	me, ok := err.(modelError)
+	if !ok {
+		return false
+	}
+	fe, ok := fieldErrors[me]
+	if !ok {
+		return false
+	}
+	for _, e := range ve.Errors {
+		if fieldErrors[e.(modelError)].field == fe.field {
+			return true
+		}
+	}
+	ve.Errors = append(ve.Errors, err)
+	return true
+}
+
+// err returns the ValidationError, or nil when it holds no
+// field error.
+func (ve *ValidationError) err() error {
+	if len(ve.Errors) == 0 {
+		return nil
+	}
+	return ve
+}
@@ -0,0 +1,200 @@
+package pages
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+	"github.com/samueldaviddelacruz/go-job-board/API/syndication"
+)
+
+// maxDescription is the length of the descriptions shown in
+// search results and link previews.
+const maxDescription = 160
+
+var (
+	tagsRegex  = regexp.MustCompile(`<[^>]*>`)
+	spaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// JobPostPage is the public page of a job post, Company is nil
+// when the company has no profile.
+type JobPostPage struct {
+	JobPost models.JobPost
+	Company *models.CompanyProfile
+}
+
+// CompanyPage is the public page of a company, listing its open
+// job posts.
+type CompanyPage struct {
+	Company  models.CompanyProfile
+	JobPosts []models.JobPost
+}
+
+// meta are the tags describing a page to crawlers and link
+// previews.
+type meta struct {
+	SiteName    string
+	Title       string
+	Description string
+	Canonical   string
+	Type        string
+	Image       string
+}
+
+type view struct {
+	Home   string
+	Meta   meta
+	JSONLD template.JS
+	Data   interface{}
+}
+
+// NewRenderer parses the page templates.
+// This function will panic if the templates are not
+// parsed correctly, and should only be used during
+// initial setup
+func NewRenderer(urls seo.URLs) *Renderer {
+	funcs := template.FuncMap{
+		"jobPostURL": urls.JobPost,
+		"companyURL": func(profile *models.CompanyProfile) string {
+			return urls.Company(*profile)
+		},
+		"isoDate": func(t *time.Time) string {
+			return t.UTC().Format("2006-01-02")
+		},
+		"longDate": func(t *time.Time) string {
+			return t.UTC().Format("January 2, 2006")
+		},
+		"salary":   Salary,
+		"applyURL": applyURL,
+	}
+	parse := func(content string) *template.Template {
+		t := template.Must(template.New("layout").Funcs(funcs).Parse(layoutTmpl))
+		return template.Must(t.Parse(content))
+	}
+	return &Renderer{
+		urls:     urls,
+		jobPost:  parse(jobPostTmpl),
+		company:  parse(companyTmpl),
+		notFound: parse(notFoundTmpl),
+	}
+}
+
+// Renderer renders the server side pages of job posts and
+// companies, so that crawlers and link previews can read them.
+type Renderer struct {
+	urls     seo.URLs
+	jobPost  *template.Template
+	company  *template.Template
+	notFound *template.Template
+}
+
+// JobPost renders the page of the job post, including its
+// JobPosting structured data.
+func (r *Renderer) JobPost(w io.Writer, page JobPostPage) error {
+	jsonLD, err := seo.NewJobPosting(page.JobPost, page.Company, r.urls).Render()
+	if err != nil {
+		return err
+	}
+	title := page.JobPost.Title
+	m := r.meta(title, page.JobPost.Description, r.urls.JobPost(page.JobPost), "article")
+	if page.Company != nil {
+		if page.Company.CompanyName != "" {
+			m.Title = fmt.Sprintf("%s at %s", title, page.Company.CompanyName)
+		}
+		m.Image = page.Company.CompanyLogoUrl
+	}
+	return r.render(w, r.jobPost, view{
+		Meta:   m,
+		JSONLD: template.JS(jsonLD),
+		Data:   page,
+	})
+}
+
+// Company renders the page of the company
+func (r *Renderer) Company(w io.Writer, page CompanyPage) error {
+	m := r.meta(page.Company.CompanyName, page.Company.Description, r.urls.Company(page.Company), "profile")
+	m.Image = page.Company.CompanyLogoUrl
+	return r.render(w, r.company, view{
+		Meta: m,
+		Data: page,
+	})
+}
+
+// NotFound renders the page shown for unknown or closed job
+// posts and companies.
+func (r *Renderer) NotFound(w io.Writer) error {
+	return r.render(w, r.notFound, view{
+		Meta: r.meta("Page not found", "", r.urls.BaseURL+"/", "website"),
+	})
+}
+
+func (r *Renderer) meta(title, description, canonical, ogType string) meta {
+	return meta{
+		SiteName:    syndication.SiteName,
+		Title:       title,
+		Description: Excerpt(description),
+		Canonical:   canonical,
+		Type:        ogType,
+	}
+}
+
+// render executes the template in a buffer first, so that
+// nothing is written when it fails.
+func (r *Renderer) render(w io.Writer, t *template.Template, v view) error {
+	v.Home = r.urls.BaseURL + "/"
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", v); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// Excerpt is the beginning of the text of description, without
+// markup, short enough for search results.
+func Excerpt(description string) string {
+	text := tagsRegex.ReplaceAllString(description, " ")
+	text = strings.TrimSpace(spaceRegex.ReplaceAllString(text, " "))
+	if utf8.RuneCountInString(text) <= maxDescription {
+		return text
+	}
+	runes := []rune(text)[:maxDescription-1]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
+
+// Salary describes the salary of the job post, such as
+// "USD 90000–120000 per year".
+func Salary(jp models.JobPost) string {
+	if !jp.HasSalary() {
+		return ""
+	}
+	amount := fmt.Sprint(jp.SalaryMin)
+	switch {
+	case jp.SalaryMin == 0:
+		amount = fmt.Sprintf("up to %d", jp.SalaryMax)
+	case jp.SalaryMax > jp.SalaryMin:
+		amount = fmt.Sprintf("%d–%d", jp.SalaryMin, jp.SalaryMax)
+	}
+	return fmt.Sprintf("%s %s per %s", jp.SalaryCurrency, amount, strings.ToLower(string(jp.SalaryPeriod)))
+}
+
+// applyURL links to the address to apply at, which is either an
+// URL or an email address.
+func applyURL(applyAt string) string {
+	if strings.Contains(applyAt, "@") && !strings.Contains(applyAt, "://") {
+		return "mailto:" + applyAt
+	}
+	return applyAt
+}
@@ -0,0 +1,94 @@
+package pages
+
+const layoutTmpl = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Meta.Title}} | {{.Meta.SiteName}}</title>
+<meta name="description" content="{{.Meta.Description}}">
+<link rel="canonical" href="{{.Meta.Canonical}}">
+<meta property="og:site_name" content="{{.Meta.SiteName}}">
+<meta property="og:type" content="{{.Meta.Type}}">
+<meta property="og:title" content="{{.Meta.Title}}">
+<meta property="og:description" content="{{.Meta.Description}}">
+<meta property="og:url" content="{{.Meta.Canonical}}">
+{{- if .Meta.Image}}
+<meta property="og:image" content="{{.Meta.Image}}">
+{{- end}}
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Meta.Title}}">
+<meta name="twitter:description" content="{{.Meta.Description}}">
+{{- if .Meta.Image}}
+<meta name="twitter:image" content="{{.Meta.Image}}">
+{{- end}}
+{{- if .JSONLD}}
+<script type="application/ld+json">{{.JSONLD}}</script>
+{{- end}}
+<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; }
+.description { white-space: pre-line; }
+.meta { color: #555; }
+</style>
+</head>
+<body>
+<header><a href="{{.Home}}">{{.Meta.SiteName}}</a></header>
+<main>
+{{template "content" .}}
+</main>
+</body>
+</html>
+{{end}}`
+
+const jobPostTmpl = `{{define "content"}}{{with .Data}}
+<article>
+<h1>{{.JobPost.Title}}</h1>
+<p class="meta">
+{{- with .Company}}{{if .Slug}}<a href="{{companyURL .}}">{{.CompanyName}}</a>{{else}}{{.CompanyName}}{{end}}{{if .Verified}} <span class="verified" title="Verified {{.VerifiedDomain}}">✓</span>{{end}}{{end}}
+{{- with .JobPost.Location}} · {{.LocationName}}{{end}}
+{{- with .JobPost.Category}} · {{.CategoryName}}{{end}}
+{{- with .JobPost.PublishedAt}} · Posted <time datetime="{{isoDate .}}">{{longDate .}}</time>{{end}}
+</p>
+{{- with salary .JobPost}}
+<p class="salary">{{.}}</p>
+{{- end}}
+{{- with .JobPost.Skills}}
+<ul class="skills">{{range .}}<li>{{.SkillName}}</li>{{end}}</ul>
+{{- end}}
+<div class="description">{{.JobPost.Description}}</div>
+<p><a href="{{applyURL .JobPost.ApplyAt}}">Apply for this job</a></p>
+</article>
+{{end}}{{end}}`
+
+const companyTmpl = `{{define "content"}}{{with .Data}}
+<article>
+{{- if .Company.CompanyLogoUrl}}
+<img src="{{.Company.CompanyLogoUrl}}" alt="{{.Company.CompanyName}} logo" width="96">
+{{- end}}
+<h1>{{.Company.CompanyName}}{{if .Company.Verified}} <span class="verified" title="Verified {{.Company.VerifiedDomain}}">✓</span>{{end}}</h1>
+<p class="meta">
+{{- with .Company.Website}}<a href="{{.}}" rel="nofollow">{{.}}</a>{{end}}
+{{- with .Company.FoundedYear}} · Founded in {{.}}{{end}}
+</p>
+<div class="description">{{.Company.Description}}</div>
+{{- with .Company.CompanyBenefits}}
+<h2>Benefits</h2>
+<ul>{{range .}}<li>{{.BenefitName}}</li>{{end}}</ul>
+{{- end}}
+{{- with .Company.Skills}}
+<h2>Tech stack</h2>
+<ul class="skills">{{range .}}<li>{{.SkillName}}</li>{{end}}</ul>
+{{- end}}
+<h2>Open positions</h2>
+{{- if .JobPosts}}
+<ul>{{range .JobPosts}}<li><a href="{{jobPostURL .}}">{{.Title}}</a></li>{{end}}</ul>
+{{- else}}
+<p>No open positions right now.</p>
+{{- end}}
+</article>
+{{end}}{{end}}`
+
+const notFoundTmpl = `{{define "content"}}
+<h1>Page not found</h1>
+<p>This page does not exist or is no longer available.</p>
+{{end}}`
@@ -0,0 +1,58 @@
+// Package publicnet makes the HTTP requests to the URLs provided
+// by users, which must only ever reach public hosts over https.
+package publicnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// maxRedirects is the number of redirects followed, as for the
+// default http.Client.
+const maxRedirects = 10
+
+// NewClient creates an http.Client with a timeout that only
+// connects to public addresses, whatever the names of the URLs
+// resolve to, and only follows redirects to https URLs.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: Control,
+	}
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     &http.Transport{DialContext: dialer.DialContext},
+		CheckRedirect: CheckRedirect,
+	}
+}
+
+// Control refuses the connections to addresses that are not
+// public, it is called once the name is resolved.
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !models.PublicIP(ip) {
+		return fmt.Errorf("publicnet: %s is not a public address", host)
+	}
+	return nil
+}
+
+// CheckRedirect only lets the redirects to https URLs through,
+// the address they resolve to is checked by Control.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("publicnet: stopped after 10 redirects")
+	}
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("publicnet: redirect to %s is not https", req.URL)
+	}
+	return nil
+}
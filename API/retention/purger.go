@@ -0,0 +1,77 @@
+package retention
+
+import (
+	"log"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const purgeInterval = 24 * time.Hour
+
+// NewPurger creates a Purger keeping deleted job posts and
+// users for the retention period, or forever when it is zero.
+// Run must be called for it to start purging them.
+func NewPurger(js models.JobPostService, us models.UserService, retention time.Duration) *Purger {
+	return &Purger{
+		js:        js,
+		us:        us,
+		retention: retention,
+	}
+}
+
+// Purger permanently deletes the job posts and users that stayed
+// deleted for longer than the retention period, until then they
+// can be restored. It also erases the accounts whose deletion
+// grace period is over.
+type Purger struct {
+	js        models.JobPostService
+	us        models.UserService
+	retention time.Duration
+}
+
+// Run purges the expired job posts and users every day until
+// stop is closed
+func (p *Purger) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := p.PurgeExpired(now); err != nil {
+				log.Printf("retention: could not purge deleted records: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// PurgeExpired erases the accounts due for deletion, then
+// purges the job posts and users deleted longer than the
+// retention period before now.
+func (p *Purger) PurgeExpired(now time.Time) error {
+	erased, err := p.us.EraseScheduled(now)
+	if erased > 0 {
+		log.Printf("retention: erased %d accounts", erased)
+	}
+	if err != nil {
+		return err
+	}
+	if p.retention <= 0 {
+		return nil
+	}
+	before := now.Add(-p.retention)
+	jobPosts, err := p.js.PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+	users, err := p.us.PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+	if jobPosts > 0 || users > 0 {
+		log.Printf("retention: purged %d job posts and %d users deleted before %s", jobPosts, users, before.Format(time.RFC3339))
+	}
+	return nil
+}
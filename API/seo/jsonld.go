@@ -0,0 +1,180 @@
+package seo
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	// JSONLDContentType is the media type of structured data
+	JSONLDContentType = "application/ld+json; charset=utf-8"
+
+	schemaContext = "https://schema.org"
+	// remoteLocation is the location name of the job posts
+	// that can be worked from anywhere.
+	remoteLocation = "remote"
+)
+
+// JobPosting is the schema.org JobPosting of a job post, see
+// https://developers.google.com/search/docs/data-types/job-posting
+type JobPosting struct {
+	Context            string        `json:"@context"`
+	Type               string        `json:"@type"`
+	Title              string        `json:"title"`
+	Description        string        `json:"description"`
+	DatePosted         string        `json:"datePosted"`
+	ValidThrough       string        `json:"validThrough,omitempty"`
+	URL                string        `json:"url"`
+	Identifier         propertyValue `json:"identifier"`
+	HiringOrganization organization  `json:"hiringOrganization"`
+	JobLocation        *place        `json:"jobLocation,omitempty"`
+	JobLocationType    string        `json:"jobLocationType,omitempty"`
+	// ApplicantLocationRequirements is required by Google for
+	// remote job posts, they are open to any country.
+	ApplicantLocationRequirements *country        `json:"applicantLocationRequirements,omitempty"`
+	Industry                      string          `json:"industry,omitempty"`
+	Skills                        string          `json:"skills,omitempty"`
+	BaseSalary                    *monetaryAmount `json:"baseSalary,omitempty"`
+}
+
+type propertyValue struct {
+	Type  string `json:"@type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type organization struct {
+	Type   string `json:"@type"`
+	Name   string `json:"name"`
+	SameAs string `json:"sameAs,omitempty"`
+	Logo   string `json:"logo,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+type place struct {
+	Type    string        `json:"@type"`
+	Address postalAddress `json:"address"`
+}
+
+type postalAddress struct {
+	Type           string `json:"@type"`
+	AddressCountry string `json:"addressCountry"`
+}
+
+type country struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type monetaryAmount struct {
+	Type     string            `json:"@type"`
+	Currency string            `json:"currency"`
+	Value    quantitativeValue `json:"value"`
+}
+
+type quantitativeValue struct {
+	Type     string `json:"@type"`
+	Value    uint   `json:"value,omitempty"`
+	MinValue uint   `json:"minValue,omitempty"`
+	MaxValue uint   `json:"maxValue,omitempty"`
+	UnitText string `json:"unitText"`
+}
+
+// NewJobPosting describes the job post, hired for by the company
+// of the profile. The location, category and skills of the job
+// post are only included when they are loaded.
+func NewJobPosting(jp models.JobPost, company *models.CompanyProfile, urls URLs) *JobPosting {
+	org := hiringOrganization(company, urls)
+	posting := &JobPosting{
+		Context:     schemaContext,
+		Type:        "JobPosting",
+		Title:       jp.Title,
+		Description: jp.Description,
+		DatePosted:  datePosted(jp).Format(time.RFC3339),
+		URL:         urls.JobPost(jp),
+		Identifier: propertyValue{
+			Type:  "PropertyValue",
+			Name:  org.Name,
+			Value: strconv.FormatUint(uint64(jp.ID), 10),
+		},
+		HiringOrganization: org,
+	}
+	if jp.ValidThrough != nil {
+		posting.ValidThrough = jp.ValidThrough.UTC().Format(time.RFC3339)
+	}
+	if jp.Location != nil && jp.Location.LocationName != "" {
+		if strings.ToLower(jp.Location.LocationName) == remoteLocation {
+			posting.JobLocationType = "TELECOMMUTE"
+			posting.ApplicantLocationRequirements = &country{Type: "Country", Name: "Anywhere"}
+		} else {
+			posting.JobLocation = &place{
+				Type: "Place",
+				Address: postalAddress{
+					Type:           "PostalAddress",
+					AddressCountry: jp.Location.LocationName,
+				},
+			}
+		}
+	}
+	if jp.Category != nil {
+		posting.Industry = jp.Category.CategoryName
+	}
+	var skills []string
+	for _, skill := range jp.Skills {
+		skills = append(skills, skill.SkillName)
+	}
+	posting.Skills = strings.Join(skills, ", ")
+	if jp.HasSalary() {
+		posting.BaseSalary = baseSalary(jp)
+	}
+	return posting
+}
+
+// Render encodes the JobPosting as JSON-LD
+func (p *JobPosting) Render() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+func hiringOrganization(company *models.CompanyProfile, urls URLs) organization {
+	org := organization{Type: "Organization"}
+	if company == nil {
+		return org
+	}
+	org.Name = company.CompanyName
+	org.SameAs = company.Website
+	org.Logo = company.CompanyLogoUrl
+	org.URL = urls.Company(*company)
+	return org
+}
+
+func baseSalary(jp models.JobPost) *monetaryAmount {
+	value := quantitativeValue{
+		Type:     "QuantitativeValue",
+		UnitText: string(jp.SalaryPeriod),
+	}
+	if jp.SalaryMax == 0 || jp.SalaryMin == jp.SalaryMax {
+		value.Value = jp.SalaryMin
+		if value.Value == 0 {
+			value.Value = jp.SalaryMax
+		}
+	} else {
+		value.MinValue = jp.SalaryMin
+		value.MaxValue = jp.SalaryMax
+	}
+	return &monetaryAmount{
+		Type:     "MonetaryAmount",
+		Currency: jp.SalaryCurrency,
+		Value:    value,
+	}
+}
+
+func datePosted(jp models.JobPost) time.Time {
+	if jp.PublishedAt != nil {
+		return jp.PublishedAt.UTC()
+	}
+	return jp.CreatedAt.UTC()
+}
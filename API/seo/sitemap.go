@@ -0,0 +1,227 @@
+package seo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	// SitemapContentType is the media type of sitemaps
+	SitemapContentType = "application/xml; charset=utf-8"
+	// PageSize is the number of URLs listed in a sitemap, well
+	// under the limit of 50,000 of the sitemaps protocol.
+	PageSize = 10000
+	// maxAge is how long the sitemaps are cached when nothing
+	// changes, so that expired job posts are eventually dropped.
+	maxAge = time.Hour
+
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+// ErrSitemapNotFound is returned for the name of a sitemap
+// page that does not exist.
+var ErrSitemapNotFound = errors.New("seo: sitemap not found")
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// Sitemap is a rendered sitemap document
+type Sitemap struct {
+	Body    []byte
+	Updated time.Time
+}
+
+// NewSitemaps creates Sitemaps listing the pages of the listed
+// job posts and of the companies.
+func NewSitemaps(js models.JobPostService, us models.UserService, urls URLs) *Sitemaps {
+	return &Sitemaps{
+		js:   js,
+		us:   us,
+		urls: urls,
+	}
+}
+
+// Sitemaps renders the sitemap index and its pages. They are
+// built on first use and cached until HandleEvent is notified of
+// a change or maxAge elapses.
+type Sitemaps struct {
+	js   models.JobPostService
+	us   models.UserService
+	urls URLs
+
+	mu      sync.Mutex
+	builtAt time.Time
+	index   *Sitemap
+	pages   map[string]*Sitemap
+}
+
+// DomainEvents returns the domain events HandleEvent must be
+// subscribed to.
+func DomainEvents() []models.EventType {
+	return []models.EventType{
+		models.EventJobPostCreated,
+		models.EventJobPostUpdated,
+		models.EventJobPostPublished,
+		models.EventJobPostClosed,
+		models.EventJobPostDeleted,
+		models.EventJobPostRestored,
+		models.EventUserRegistered,
+		models.EventUserUpdated,
+		models.EventUserDeleted,
+		models.EventUserRestored,
+	}
+}
+
+// HandleEvent discards the cached sitemaps, they are rebuilt on
+// the next request.
+func (s *Sitemaps) HandleEvent(event models.DomainEvent) error {
+	s.Invalidate()
+	return nil
+}
+
+// Invalidate discards the cached sitemaps
+func (s *Sitemaps) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = nil
+	s.pages = nil
+}
+
+// Index returns the sitemap index, listing every sitemap page
+func (s *Sitemaps) Index(now time.Time) (*Sitemap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.build(now); err != nil {
+		return nil, err
+	}
+	return s.index, nil
+}
+
+// Page returns the sitemap page with the name, such as "jobs-1"
+func (s *Sitemaps) Page(name string, now time.Time) (*Sitemap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.build(now); err != nil {
+		return nil, err
+	}
+	page, ok := s.pages[name]
+	if !ok {
+		return nil, ErrSitemapNotFound
+	}
+	return page, nil
+}
+
+// build renders every sitemap unless the cached ones are still
+// fresh. s.mu must be held.
+func (s *Sitemaps) build(now time.Time) error {
+	if s.index != nil && now.Sub(s.builtAt) < maxAge {
+		return nil
+	}
+	jobPosts, err := s.js.Listed(now)
+	if err != nil {
+		return err
+	}
+	profiles, err := s.us.CompanyProfiles()
+	if err != nil {
+		return err
+	}
+
+	var jobURLs []sitemapURL
+	var jobsUpdated []time.Time
+	for _, jp := range jobPosts {
+		jobURLs = append(jobURLs, sitemapURL{Loc: s.urls.JobPost(jp), LastMod: lastMod(jp.UpdatedAt)})
+		jobsUpdated = append(jobsUpdated, jp.UpdatedAt)
+	}
+	var companyURLs []sitemapURL
+	var companiesUpdated []time.Time
+	for _, profile := range profiles {
+		if profile.Slug == "" {
+			continue
+		}
+		companyURLs = append(companyURLs, sitemapURL{Loc: s.urls.Company(profile), LastMod: lastMod(profile.UpdatedAt)})
+		companiesUpdated = append(companiesUpdated, profile.UpdatedAt)
+	}
+
+	pages := map[string]*Sitemap{}
+	index := sitemapIndex{Xmlns: sitemapNS}
+	var indexUpdated time.Time
+	for _, section := range []struct {
+		name    string
+		urls    []sitemapURL
+		updated []time.Time
+	}{
+		{"jobs", jobURLs, jobsUpdated},
+		{"companies", companyURLs, companiesUpdated},
+	} {
+		for start, n := 0, 1; start < len(section.urls); start, n = start+PageSize, n+1 {
+			end := start + PageSize
+			if end > len(section.urls) {
+				end = len(section.urls)
+			}
+			body, err := render(urlSet{Xmlns: sitemapNS, URLs: section.urls[start:end]})
+			if err != nil {
+				return err
+			}
+			updated := latest(section.updated[start:end])
+			name := fmt.Sprintf("%s-%d", section.name, n)
+			pages[name] = &Sitemap{Body: body, Updated: updated}
+			index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: s.urls.Sitemap(name), LastMod: lastMod(updated)})
+			if updated.After(indexUpdated) {
+				indexUpdated = updated
+			}
+		}
+	}
+	body, err := render(index)
+	if err != nil {
+		return err
+	}
+
+	s.index = &Sitemap{Body: body, Updated: indexUpdated}
+	s.pages = pages
+	s.builtAt = now
+	return nil
+}
+
+func render(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func latest(times []time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest.UTC()
+}
+
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
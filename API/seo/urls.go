@@ -0,0 +1,39 @@
+package seo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// URLs builds the public URLs of the pages indexed by search
+// engines.
+type URLs struct {
+	// BaseURL is the public URL of the site, without trailing
+	// slash.
+	BaseURL string
+}
+
+func NewURLs(baseURL string) URLs {
+	return URLs{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// JobPost is the public page of the job post
+func (u URLs) JobPost(jp models.JobPost) string {
+	return fmt.Sprintf("%s/j/%s", u.BaseURL, jp.Slug)
+}
+
+// Company is the public page of the company, only companies
+// with a slug have one.
+func (u URLs) Company(profile models.CompanyProfile) string {
+	if profile.Slug == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/c/%s", u.BaseURL, profile.Slug)
+}
+
+// Sitemap is the URL a sitemap is served at
+func (u URLs) Sitemap(name string) string {
+	return fmt.Sprintf("%s/sitemaps/%s.xml", u.BaseURL, name)
+}
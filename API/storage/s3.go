@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheControl is sent along with the stored files. Keys must
+// change whenever the content does, for instance by including
+// a hash of the content.
+const CacheControl = "public, max-age=31536000, immutable"
+
+// S3Config locates a bucket of Amazon S3 or of any compatible
+// service, such as MinIO or DigitalOcean Spaces.
+type S3Config struct {
+	// Endpoint is the URL of the service, such as
+	// https://s3.eu-west-1.amazonaws.com
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is the URL the files of the bucket are served
+	// at, such as the URL of a CDN. It defaults to the URL of
+	// the bucket.
+	PublicURL string
+}
+
+// NewS3 stores files in the bucket, which must allow public
+// reads for the files to be served.
+func NewS3(cfg S3Config) *S3 {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	return &S3{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// S3 stores files in a bucket of an S3 compatible service,
+// using path style requests signed with AWS Signature V4.
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func (s *S3) Put(key, contentType string, content io.Reader) error {
+	body, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Cache-Control", CacheControl)
+	return s.do(http.MethodPut, key, headers, body)
+}
+
+func (s *S3) Delete(key string) error {
+	return s.do(http.MethodDelete, key, http.Header{}, nil)
+}
+
+func (s *S3) URL(key string) string {
+	return s.cfg.PublicURL + "/" + key
+}
+
+func (s *S3) do(method, key string, headers http.Header, body []byte) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	u, err := url.Parse(s.cfg.Endpoint + escapePath("/"+s.cfg.Bucket+"/"+key))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name := range headers {
+		req.Header.Set(name, headers.Get(name))
+	}
+	s.sign(req, body)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("storage: %s %s returned status %d: %s", method, key, res.StatusCode, msg)
+	}
+	return nil
+}
+
+// sign adds the AWS Signature V4 of the request, see
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		signed[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	var names []string
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath escapes every segment of the path the way S3
+// expects it in canonical requests.
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
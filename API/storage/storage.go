@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrKeyInvalid is returned for keys escaping the storage, such
+// as "../secret".
+var ErrKeyInvalid = errors.New("storage: invalid key")
+
+// Storage stores public files, such as company logos, under
+// slash separated keys.
+type Storage interface {
+	// Put stores the content under the key, replacing any file
+	// already stored there.
+	Put(key, contentType string, content io.Reader) error
+	Delete(key string) error
+	// URL is the public URL the file stored under the key is
+	// served at.
+	URL(key string) string
+}
+
+// NewLocal stores files in dir, they are served at baseURL by
+// the handler of the application.
+func NewLocal(dir, baseURL string) *Local {
+	return &Local{
+		Dir:     dir,
+		BaseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Local stores files on the local disk
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+func (l *Local) Put(key, contentType string, content io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// The file is written aside and renamed, so that it is never
+	// served half written.
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.BaseURL + "/" + key
+}
+
+// Handler serves the stored files with long lived cache headers,
+// it must be mounted at the path of BaseURL with the prefix
+// stripped.
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, err := l.path(strings.TrimPrefix(r.URL.Path, "/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", CacheControl)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeFile(w, r, path)
+	})
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return ErrKeyInvalid
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." || strings.HasPrefix(part, ".") {
+			return ErrKeyInvalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package syndication
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+)
+
+const (
+	SiteName = "Go Job Board"
+	// MaxItems is the number of job posts listed in a feed
+	MaxItems = 50
+	// tagDate is the date of the tag URIs identifying the job
+	// posts, it must never change.
+	tagDate = "2019"
+)
+
+// Feed is a list of job posts rendered as RSS, Atom or JSON Feed
+type Feed struct {
+	Title string
+	// BaseURL is the public URL of the site, without trailing
+	// slash.
+	BaseURL string
+	// SelfURL is the URL the feed is served at
+	SelfURL  string
+	JobPosts []models.JobPost
+}
+
+// NewFeed returns the feed of the most recently published job
+// posts, ignoring the duplicates returned by JobPostDB.FindAll.
+func NewFeed(title, baseURL, selfURL string, jobPosts []models.JobPost) *Feed {
+	var unique []models.JobPost
+	seen := map[uint]bool{}
+	for _, jp := range jobPosts {
+		if !seen[jp.ID] {
+			seen[jp.ID] = true
+			unique = append(unique, jp)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return published(unique[i]).After(published(unique[j]))
+	})
+	if len(unique) > MaxItems {
+		unique = unique[:MaxItems]
+	}
+	return &Feed{
+		Title:    title,
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		SelfURL:  selfURL,
+		JobPosts: unique,
+	}
+}
+
+// Updated is the last time one of the job posts changed
+func (f *Feed) Updated() time.Time {
+	var updated time.Time
+	for _, jp := range f.JobPosts {
+		if jp.UpdatedAt.After(updated) {
+			updated = jp.UpdatedAt
+		}
+	}
+	return updated.UTC()
+}
+
+// ETag changes whenever a job post is added to, removed from or
+// updated in the feed.
+func (f *Feed) ETag(format string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", format, f.SelfURL)
+	for _, jp := range f.JobPosts {
+		fmt.Fprintf(h, "%d:%d\n", jp.ID, jp.UpdatedAt.UnixNano())
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// JobPostURL is the public page of the job post
+func (f *Feed) JobPostURL(jp models.JobPost) string {
+	return seo.NewURLs(f.BaseURL).JobPost(jp)
+}
+
+// GUID identifies the job post forever, even if its URL changes
+func (f *Feed) GUID(jp models.JobPost) string {
+	host := f.BaseURL
+	if u, err := url.Parse(f.BaseURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:job-post:%d", host, tagDate, jp.ID)
+}
+
+// tags are the category, location and skill names of the job post
+func tags(jp models.JobPost) []string {
+	var tags []string
+	if jp.Category != nil && jp.Category.CategoryName != "" {
+		tags = append(tags, jp.Category.CategoryName)
+	}
+	if jp.Location != nil && jp.Location.LocationName != "" {
+		tags = append(tags, jp.Location.LocationName)
+	}
+	for _, skill := range jp.Skills {
+		tags = append(tags, skill.SkillName)
+	}
+	return tags
+}
+
+func published(jp models.JobPost) time.Time {
+	if jp.PublishedAt != nil {
+		return jp.PublishedAt.UTC()
+	}
+	return jp.CreatedAt.UTC()
+}
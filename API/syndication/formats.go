@@ -0,0 +1,206 @@
+package syndication
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+
+	jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+)
+
+// ContentTypes are the media types of the feed formats
+var ContentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.BaseURL,
+			Description:   f.Title + " on " + SiteName,
+			LastBuildDate: f.Updated().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Href: f.SelfURL, Rel: "self", Type: ContentTypes[FormatRSS]},
+		},
+	}
+	for _, jp := range f.JobPosts {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       jp.Title,
+			Link:        f.JobPostURL(jp),
+			Description: jp.Description,
+			GUID:        rssGUID{Value: f.GUID(jp)},
+			PubDate:     published(jp).Format(time.RFC1123Z),
+			Categories:  tags(jp),
+		})
+	}
+	return marshalXML(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Link       atomLink       `xml:"link"`
+	Content    atomContent    `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom renders the feed as Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Updated: f.Updated().Format(time.RFC3339),
+		Author:  atomAuthor{Name: SiteName},
+		Links: []atomLink{
+			{Href: f.SelfURL, Rel: "self", Type: ContentTypes[FormatAtom]},
+			{Href: f.BaseURL, Rel: "alternate"},
+		},
+	}
+	for _, jp := range f.JobPosts {
+		entry := atomEntry{
+			ID:        f.GUID(jp),
+			Title:     jp.Title,
+			Updated:   jp.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: published(jp).Format(time.RFC3339),
+			Link:      atomLink{Href: f.JobPostURL(jp), Rel: "alternate"},
+			Content:   atomContent{Type: "html", Value: jp.Description},
+		}
+		for _, tag := range tags(jp) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalXML(doc)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// JSON renders the feed as JSON Feed 1.1
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       f.Title,
+		HomePageURL: f.BaseURL,
+		FeedURL:     f.SelfURL,
+		Items:       []jsonFeedItem{},
+	}
+	for _, jp := range f.JobPosts {
+		doc.Items = append(doc.Items, jsonFeedItem{
+			ID:            f.GUID(jp),
+			URL:           f.JobPostURL(jp),
+			Title:         jp.Title,
+			ContentHTML:   jp.Description,
+			DatePublished: published(jp).Format(time.RFC3339),
+			DateModified:  jp.UpdatedAt.UTC().Format(time.RFC3339),
+			Tags:          tags(jp),
+		})
+	}
+	return json.Marshal(doc)
+}
+
+// Render renders the feed in the provided format
+func (f *Feed) Render(format string) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return f.RSS()
+	case FormatAtom:
+		return f.Atom()
+	}
+	return f.JSON()
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
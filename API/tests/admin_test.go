@@ -0,0 +1,58 @@
+package model_services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/samueldaviddelacruz/go-job-board/API/middleware"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// roleUserService serves the users by email, with the admin
+// role given by roleID.
+type roleUserService struct {
+	models.UserService
+	roleID uint
+}
+
+func (s roleUserService) ByEmail(email string) (*models.User, error) {
+	return &models.User{Email: email, RoleID: 1}, nil
+}
+
+func (s roleUserService) IsAdmin(user *models.User) (bool, error) {
+	return user.RoleID == s.roleID, nil
+}
+
+func TestRequireAdmin(t *testing.T) {
+	const secret = "test-hmac-key"
+	token, err := jwt.Sign(models.CustomPayload{Email: "admin@acme.com"}, jwt.NewHS256([]byte(secret)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(us models.UserService, token string) int {
+		mw := middleware.RequireAdmin{RequireUser: middleware.RequireUser{
+			User: middleware.User{Secret: secret, UserService: us},
+		}}
+		req := httptest.NewRequest("DELETE", "/admin/users/2", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mw.ApplyFn(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(roleUserService{roleID: 3}, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a token, but got %d", code)
+	}
+	if code := serve(roleUserService{roleID: 3}, string(token)); code != http.StatusForbidden {
+		t.Errorf("expected status 403 for a user without the admin role, but got %d", code)
+	}
+	if code := serve(roleUserService{roleID: 1}, string(token)); code != http.StatusNoContent {
+		t.Errorf("expected the admin to be let through, but got %d", code)
+	}
+}
@@ -0,0 +1,77 @@
+package model_services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestAPIKeyHasScope(t *testing.T) {
+	apiKey := models.APIKey{Scopes: "jobs:read,applications:read"}
+	if !apiKey.HasScope(models.ScopeJobsRead) || !apiKey.HasScope(models.ScopeApplicationsRead) {
+		t.Errorf("expected %q to grant its scopes", apiKey.Scopes)
+	}
+	if apiKey.HasScope(models.ScopeJobsWrite) {
+		t.Errorf("expected %q not to grant %q", apiKey.Scopes, models.ScopeJobsWrite)
+	}
+}
+
+func TestAPIKeyService(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithAPIKey("api-key-test-secret"),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	apiKey := models.APIKey{UserID: 1, CompanyID: 1, Scopes: " jobs:read , jobs:write "}
+	if err := services.APIKey.Create(&apiKey); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(apiKey.Key, apiKey.Prefix+".") || apiKey.KeyHash == apiKey.Key {
+		t.Errorf("expected a hashed key starting with its prefix, but got %+v", apiKey)
+	}
+	if apiKey.Scopes != "jobs:read,jobs:write" || apiKey.Name == "" {
+		t.Errorf("expected normalized scopes and a default name, but got %+v", apiKey)
+	}
+
+	found, err := services.APIKey.Authenticate(apiKey.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != apiKey.ID || found.LastUsedAt == nil {
+		t.Errorf("expected key %d to be used, but got %+v", apiKey.ID, found)
+	}
+
+	t.Run("SadPath: wrong secret is rejected", func(t *testing.T) {
+		wantError := models.ErrAPIKeyInvalid
+		if _, err := services.APIKey.Authenticate(apiKey.Prefix + ".wrong"); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: unknown scope is not allowed", func(t *testing.T) {
+		wantError := models.ErrAPIKeyScopeInvalid
+		invalid := models.APIKey{UserID: 1, CompanyID: 1, Scopes: "jobs:delete"}
+		if err := services.APIKey.Create(&invalid); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: revoked key is rejected", func(t *testing.T) {
+		if err := services.APIKey.Revoke(apiKey.ID); err != nil {
+			t.Fatal(err)
+		}
+		wantError := models.ErrAPIKeyInvalid
+		if _, err := services.APIKey.Authenticate(apiKey.Key); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package model_services_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestJobPostUserStatus(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+		models.WithBookmark(),
+		models.WithApplication(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	jobPosts := []models.JobPost{mockJobPost(), mockJobPost()}
+	for i := range jobPosts {
+		if err := services.JobPost.Create(&jobPosts[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const userID = 1
+	bookmark := models.Bookmark{UserID: userID, JobPostID: jobPosts[0].ID}
+	if err := services.Bookmark.Create(&bookmark); err != nil {
+		t.Fatal(err)
+	}
+	application := models.Application{UserID: userID, JobPostID: jobPosts[1].ID}
+	if err := services.Application.Create(&application); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := services.JobPost.SetUserStatus(userID, jobPosts); err != nil {
+		t.Fatal(err)
+	}
+	if !*jobPosts[0].Bookmarked || *jobPosts[0].Applied {
+		t.Errorf("expected first job post to be bookmarked only, got bookmarked = %v, applied = %v", *jobPosts[0].Bookmarked, *jobPosts[0].Applied)
+	}
+	if *jobPosts[1].Bookmarked || !*jobPosts[1].Applied {
+		t.Errorf("expected second job post to be applied only, got bookmarked = %v, applied = %v", *jobPosts[1].Bookmarked, *jobPosts[1].Applied)
+	}
+
+	t.Run("RemoveBookmark", func(t *testing.T) {
+		if err := services.Bookmark.Delete(userID, jobPosts[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		got, err := services.Bookmark.ByUserID(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("expected bookmarks list to be empty, but got = %v elements", len(got))
+		}
+	})
+}
+
+// listedJobPostService serves a single job post by ID
+type listedJobPostService struct {
+	models.JobPostService
+	jobPost models.JobPost
+}
+
+func (s *listedJobPostService) ByID(id uint) (*models.JobPost, error) {
+	jobPost := s.jobPost
+	return &jobPost, nil
+}
+
+// countingBookmarkService counts the bookmarks created
+type countingBookmarkService struct {
+	models.BookmarkService
+	created int
+}
+
+func (s *countingBookmarkService) Create(bookmark *models.Bookmark) error {
+	s.created++
+	return nil
+}
+
+// countingApplicationService counts the applications created
+type countingApplicationService struct {
+	models.ApplicationService
+	created int
+}
+
+func (s *countingApplicationService) Create(application *models.Application) error {
+	s.created++
+	return nil
+}
+
+func TestCandidateListedJobPosts(t *testing.T) {
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	cases := map[string]struct {
+		publishedAt, closedAt *time.Time
+		want                  int
+	}{
+		"listed":    {&past, nil, http.StatusOK},
+		"draft":     {nil, nil, http.StatusNotFound},
+		"scheduled": {&future, nil, http.StatusNotFound},
+		"closed":    {&past, &past, http.StatusNotFound},
+	}
+	candidate := &models.User{}
+	candidate.ID = 2
+	for name, c := range cases {
+		stub := &listedJobPostService{jobPost: models.JobPost{PublishedAt: c.publishedAt, ClosedAt: c.closedAt}}
+		stub.jobPost.ID = 1
+		bookmarks, applications := &countingBookmarkService{}, &countingApplicationService{}
+		jobs := controllers.NewJobs(stub, nil, bookmarks, applications, nil)
+		for _, fn := range []controllers.HandlerFunc{jobs.Bookmark, jobs.MarkApplied} {
+			if rec := serveOwned(fn, "PUT", "", map[string]string{"id": "1"}, candidate); rec.Code != c.want {
+				t.Errorf("%s: expected status %d, but got %d", name, c.want, rec.Code)
+			}
+		}
+		if c.want == http.StatusNotFound && bookmarks.created+applications.created != 0 {
+			t.Errorf("%s: expected nothing to be created", name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package model_services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestSearchCompanyProfiles(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithUser("pepperhere", "randomtesthmacvalue"),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	for i, profile := range []models.CompanyProfile{
+		{CompanyName: "Acme", Description: "Rockets and anvils"},
+		{CompanyName: "Globex", Description: "We build rockets too"},
+		{CompanyName: "Initech", Description: "TPS reports"},
+		// Unnamed companies are not listed
+		{Description: "Rockets in stealth mode"},
+	} {
+		user := models.User{Email: fmt.Sprintf("company%d@example.com", i), Password: "megaman007"}
+		if err := services.User.Create(&user); err != nil {
+			t.Fatal(err)
+		}
+		user.CompanyProfile = &profile
+		if err := services.User.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profiles, total, err := services.User.SearchCompanyProfiles("rocket", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(profiles) != 1 || profiles[0].CompanyName != "Acme" {
+		t.Errorf("expected the first of 2 companies to be Acme, but got %d companies %+v", total, profiles)
+	}
+	profiles, _, err = services.User.SearchCompanyProfiles("rocket", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].CompanyName != "Globex" {
+		t.Errorf("expected the second page to be Globex, but got %+v", profiles)
+	}
+
+	t.Run("SadPath: no match returns an empty page", func(t *testing.T) {
+		profiles, total, err := services.User.SearchCompanyProfiles("umbrella", 0, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 0 || len(profiles) != 0 {
+			t.Errorf("expected no companies, but got %d companies %+v", total, profiles)
+		}
+	})
+}
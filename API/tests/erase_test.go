@@ -0,0 +1,154 @@
+package model_services_test
+
+import (
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestUserErase(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithUser("pepperhere", "randomtesthmacvalue"),
+		models.WithJobPost(),
+		models.WithOAuth(),
+		models.WithSavedSearch("randomtesthmacvalue"),
+		models.WithBookmark(),
+		models.WithWebhook(),
+		models.WithAPIKey("randomtesthmacvalue"),
+		models.WithJobFeed(),
+		models.WithCompany("randomtesthmacvalue"),
+		models.WithApplication(),
+		models.WithImportJob(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	owner := models.User{Email: "owner@acme.com", Password: "megaman007"}
+	recruiter := models.User{Email: "recruiter@acme.com", Password: "megaman007"}
+	for _, user := range []*models.User{&owner, &recruiter} {
+		if err := services.User.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Created before the company, the job post has none
+	personal := mockJobPost()
+	if err := services.JobPost.Create(&personal); err != nil {
+		t.Fatal(err)
+	}
+	owner.RememberHash = "remember-hash"
+	owner.CompanyProfile = &models.CompanyProfile{CompanyName: "Acme"}
+	if err := services.User.Update(&owner); err != nil {
+		t.Fatal(err)
+	}
+	companyID := owner.CompanyProfile.CompanyID
+	invitation := models.CompanyInvitation{CompanyID: companyID, Email: recruiter.Email, Role: models.MemberRecruiter}
+	if err := services.Company.CreateInvitation(&invitation); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := services.Company.AcceptInvitation(invitation.Token, &recruiter); err != nil {
+		t.Fatal(err)
+	}
+
+	jobPost := mockJobPost()
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	webhook := models.Webhook{UserID: owner.ID, URL: "https://hooks.example.com/jobs", Events: "job_post.created"}
+	importJob := models.ImportJob{
+		UserID: owner.ID,
+		Format: "csv",
+		Status: models.ImportCompleted,
+		Errors: []models.ImportRowError{{Row: 2, Message: "unknown category"}},
+	}
+	for _, err := range []error{
+		services.OAuth.Create(&models.OAuth{UserID: owner.ID, Service: "dropbox"}),
+		services.SavedSearch.Create(&models.SavedSearch{UserID: owner.ID, Query: "q=golang"}),
+		services.Bookmark.Create(&models.Bookmark{UserID: owner.ID, JobPostID: jobPost.ID}),
+		services.Webhook.Create(&webhook),
+		services.APIKey.Create(&models.APIKey{UserID: owner.ID, CompanyID: companyID, Scopes: "jobs:read"}),
+		services.JobFeed.Create(&models.JobFeed{UserID: owner.ID, URL: "https://acme.example/feed.xml", CategoryID: 3, LocationID: 4}),
+		services.Application.Create(&models.Application{UserID: owner.ID, JobPostID: jobPost.ID}),
+		services.ImportJob.Create(&importJob),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := services.Webhook.CreateDelivery(&models.WebhookDelivery{WebhookID: webhook.ID, Event: models.WebhookPing, Payload: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+	resetToken, err := services.User.InitiateReset(owner.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export, err := services.User.Export(owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Webhooks) != 1 || export.Webhooks[0].Secret != "" {
+		t.Errorf("expected the webhook without its secret, but got %+v", export.Webhooks)
+	}
+	if len(export.SavedSearches) != 1 || len(export.Bookmarks) != 1 || len(export.APIKeys) != 1 ||
+		len(export.JobFeeds) != 1 || len(export.CompanyMemberships) != 1 {
+		t.Errorf("unexpected export %+v", export)
+	}
+
+	if err := services.User.Erase(owner.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := services.User.ByRemember("remember-hash"); err != models.ErrNotFound {
+		t.Errorf("users: expected the remember hash to be cleared, but got %v", err)
+	}
+	if _, err := services.OAuth.Find(owner.ID, "dropbox"); err != models.ErrNotFound {
+		t.Errorf("o_auths: expected the connection to be deleted, but got %v", err)
+	}
+	if _, err := services.User.CompleteReset(resetToken, "megaman008"); err == nil {
+		t.Errorf("pw_resets: expected the password reset to be deleted")
+	}
+	if searches, _ := services.SavedSearch.ByUserID(owner.ID); len(searches) != 0 {
+		t.Errorf("saved_searches: expected none, but got %v", searches)
+	}
+	if bookmarks, _ := services.Bookmark.ByUserID(owner.ID); len(bookmarks) != 0 {
+		t.Errorf("bookmarks: expected none, but got %v", bookmarks)
+	}
+	if hooks, _ := services.Webhook.ByUserID(owner.ID); len(hooks) != 0 {
+		t.Errorf("webhooks: expected none, but got %v", hooks)
+	}
+	if deliveries, _ := services.Webhook.Deliveries(webhook.ID, 10); len(deliveries) != 0 {
+		t.Errorf("webhook_deliveries: expected none, but got %v", deliveries)
+	}
+	if apiKeys, _ := services.APIKey.ByUserID(owner.ID); len(apiKeys) != 0 {
+		t.Errorf("api_keys: expected none, but got %v", apiKeys)
+	}
+	if feeds, _ := services.JobFeed.ByUserID(owner.ID); len(feeds) != 0 {
+		t.Errorf("job_feeds: expected none, but got %v", feeds)
+	}
+	if applications, _ := services.Application.ByUserID(owner.ID); len(applications) != 0 {
+		t.Errorf("applications: expected none, but got %v", applications)
+	}
+	if _, err := services.ImportJob.ByID(importJob.ID); err != models.ErrNotFound {
+		t.Errorf("import_jobs: expected the import to be deleted, but got %v", err)
+	}
+	if _, err := services.JobPost.DeletedByID(personal.ID); err != nil {
+		t.Errorf("job_posts: expected the job post without company to be deleted, but got %v", err)
+	}
+	if _, err := services.JobPost.ByID(jobPost.ID); err != nil {
+		t.Errorf("job_posts: expected the company job post to be kept, but got %v", err)
+	}
+	if _, err := services.Company.Member(companyID, owner.ID); err != models.ErrNotFound {
+		t.Errorf("company_members: expected the membership to be deleted, but got %v", err)
+	}
+	member, err := services.Company.Member(companyID, recruiter.ID)
+	if err != nil || member.Role != models.MemberOwner {
+		t.Errorf("company_members: expected the ownership to be transferred, but got %+v %v", member, err)
+	}
+}
@@ -0,0 +1,94 @@
+package model_services_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestHandle(t *testing.T) {
+	serve := func(err error, correlationID string) (*httptest.ResponseRecorder, controllers.Problem) {
+		handler := controllers.Handle(func(w http.ResponseWriter, r *http.Request) error {
+			return err
+		})
+		req := httptest.NewRequest("GET", "/jobs/1", nil)
+		if correlationID != "" {
+			req.Header.Set(controllers.CorrelationIDHeader, correlationID)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		problem := controllers.Problem{}
+		if err != nil {
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("expected a problem, but got %q", rec.Body.String())
+			}
+		}
+		return rec, problem
+	}
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{models.ErrNotFound, http.StatusNotFound},
+		{models.ErrIncludeInvalid, http.StatusBadRequest},
+		{models.ErrEmailTaken, http.StatusConflict},
+		{models.ErrPasswordIncorrect, http.StatusUnauthorized},
+		{models.ErrMemberRoleForbidden, http.StatusForbidden},
+		{models.ErrVersionConflict, http.StatusPreconditionFailed},
+		{models.ErrWebhookURLInvalid, http.StatusUnprocessableEntity},
+		{models.NewJobPostService(nil).Validate(&models.JobPost{UserID: 1}), http.StatusUnprocessableEntity},
+	}
+	for _, c := range cases {
+		rec, problem := serve(c.err, "")
+		if rec.Code != c.status || problem.Status != c.status {
+			t.Errorf("expected %q to respond with %d, but got %d", c.err, c.status, rec.Code)
+		}
+		if rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("expected a problem+json response, but got %q", rec.Header().Get("Content-Type"))
+		}
+		if problem.CorrelationID != "" {
+			t.Errorf("expected %q not to have a correlation ID", c.err)
+		}
+	}
+
+	t.Run("successful handlers respond themselves", func(t *testing.T) {
+		rec, _ := serve(nil, "")
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+			t.Errorf("expected an untouched response, but got %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("SadPath: unknown errors are hidden behind a correlation ID", func(t *testing.T) {
+		rec, problem := serve(errors.New("pq: connection refused"), "")
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, but got %d", rec.Code)
+		}
+		if problem.Detail != "" || problem.CorrelationID == "" {
+			t.Errorf("expected only a correlation ID, but got %+v", problem)
+		}
+		if rec.Header().Get(controllers.CorrelationIDHeader) != problem.CorrelationID {
+			t.Errorf("expected the correlation ID header to be %q", problem.CorrelationID)
+		}
+		_, problem = serve(models.ErrIDInvalid, "")
+		if problem.Status != http.StatusInternalServerError || problem.Detail != "" {
+			t.Errorf("expected private errors to be hidden, but got %+v", problem)
+		}
+	})
+
+	t.Run("the correlation ID of the client is kept", func(t *testing.T) {
+		_, problem := serve(errors.New("boom"), "req-42")
+		if problem.CorrelationID != "req-42" {
+			t.Errorf("expected correlation ID req-42, but got %q", problem.CorrelationID)
+		}
+		_, problem = serve(errors.New("boom"), "<script>")
+		if problem.CorrelationID == "<script>" {
+			t.Errorf("expected invalid correlation IDs to be replaced")
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package model_services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/events"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// memoryEvents is an in-memory models.EventService
+type memoryEvents struct {
+	events []models.DomainEvent
+}
+
+func (me *memoryEvents) Pending(limit int) ([]models.DomainEvent, error) {
+	var pending []models.DomainEvent
+	for _, e := range me.events {
+		if e.DispatchedAt == nil && len(pending) < limit {
+			pending = append(pending, e)
+		}
+	}
+	return pending, nil
+}
+
+func (me *memoryEvents) MarkDispatched(event *models.DomainEvent) error {
+	for i := range me.events {
+		if me.events[i].ID == event.ID {
+			me.events[i].DispatchedAt = &event.CreatedAt
+			me.events[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (me *memoryEvents) MarkFailed(event *models.DomainEvent, cause error) error {
+	for i := range me.events {
+		if me.events[i].ID == event.ID {
+			me.events[i].LastError = cause.Error()
+			me.events[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func TestEventDispatcher(t *testing.T) {
+	store := &memoryEvents{events: []models.DomainEvent{
+		{ID: 1, Type: models.EventJobPostCreated},
+		{ID: 2, Type: models.EventUserRegistered},
+		{ID: 3, Type: models.EventJobPostCreated},
+	}}
+	dispatcher := events.NewDispatcher(store)
+
+	var created []uint
+	dispatcher.Subscribe(func(e models.DomainEvent) error {
+		created = append(created, e.ID)
+		return nil
+	}, models.EventJobPostCreated)
+	failing := true
+	dispatcher.Subscribe(func(e models.DomainEvent) error {
+		if failing && e.ID == 3 {
+			return errors.New("subscriber is down")
+		}
+		return nil
+	}, models.EventJobPostCreated, models.EventUserRegistered)
+
+	if err := dispatcher.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[0] != 1 || created[1] != 3 {
+		t.Errorf("expected events [1 3] to be handled in order, but got %v", created)
+	}
+	pending, _ := store.Pending(10)
+	if len(pending) != 1 || pending[0].ID != 3 || pending[0].LastError == "" {
+		t.Fatalf("expected only the failed event to be pending, but got %+v", pending)
+	}
+
+	// Every subscriber sees the failed event again once it is retried
+	failing = false
+	if err := dispatcher.DispatchPending(); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 3 || created[2] != 3 {
+		t.Errorf("expected event 3 to be delivered again, but got %v", created)
+	}
+	if pending, _ := store.Pending(10); len(pending) != 0 {
+		t.Errorf("expected no pending events, but got %+v", pending)
+	}
+
+	t.Run("SadPath: panicking subscriber fails the event", func(t *testing.T) {
+		store := &memoryEvents{events: []models.DomainEvent{{ID: 1, Type: models.EventUserRegistered}}}
+		dispatcher := events.NewDispatcher(store)
+		dispatcher.Subscribe(func(e models.DomainEvent) error {
+			panic("boom")
+		}, models.EventUserRegistered)
+
+		if err := dispatcher.DispatchPending(); err != nil {
+			t.Fatal(err)
+		}
+		if pending, _ := store.Pending(10); len(pending) != 1 || pending[0].Attempts != 1 {
+			t.Errorf("expected the event to stay pending, but got %+v", pending)
+		}
+	})
+}
+
+func TestEventOutbox(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+		models.WithSkill(),
+		models.WithEvent(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	jobPost := models.JobPost{
+		Title:       "Go developer",
+		Description: "Build APIs",
+		ApplyAt:     "jobs@example.com",
+		UserID:      1,
+		CategoryID:  1,
+		LocationID:  1,
+	}
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	if err := services.Skill.AddSkillToOwner(&jobPost, models.Skill{SkillName: "golang"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := services.Event.Pending(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := []models.EventType{
+		models.EventJobPostCreated,
+		models.EventJobPostPublished,
+		models.EventJobPostSkillAdded,
+	}
+	if len(pending) != len(wantTypes) {
+		t.Fatalf("expected %d events, but got %+v", len(wantTypes), pending)
+	}
+	for i, want := range wantTypes {
+		if pending[i].Type != want || pending[i].AggregateID != jobPost.ID {
+			t.Errorf("expected event %d to be %s of job post %d, but got %+v", i, want, jobPost.ID, pending[i])
+		}
+	}
+
+	var payload models.SkillEventPayload
+	if err := pending[2].Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.OwnerID != jobPost.ID || payload.SkillID == 0 {
+		t.Errorf("expected skill added to job post %d, but got %+v", jobPost.ID, payload)
+	}
+
+	t.Run("SadPath: failed change records no event", func(t *testing.T) {
+		if err := services.JobPost.Create(&models.JobPost{}); err == nil {
+			t.Fatal("expected invalid job post to be rejected")
+		}
+		if err := services.Skill.AddSkillToOwner(&jobPost, models.Skill{SkillName: "cobol"}); err != models.ErrSkillUnknown {
+			t.Errorf("should return %q error got %q error", models.ErrSkillUnknown, err)
+		}
+		if after, _ := services.Event.Pending(10); len(after) != len(wantTypes) {
+			t.Errorf("expected %d events, but got %d", len(wantTypes), len(after))
+		}
+	})
+}
+
+func TestScheduledJobPostAnnouncement(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+		models.WithEvent(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	publishedAt := time.Now().Add(time.Hour)
+	jobPost := mockJobPost()
+	jobPost.PublishedAt = &publishedAt
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	published := func() int {
+		pending, err := services.Event.Pending(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, event := range pending {
+			if event.Type == models.EventJobPostPublished {
+				count++
+			}
+		}
+		return count
+	}
+	if got := published(); got != 0 {
+		t.Errorf("expected the scheduled job post not to be announced, but got %d events", got)
+	}
+	if announced, err := services.JobPost.AnnounceDue(time.Now()); err != nil || announced != 0 {
+		t.Errorf("expected no job post to be due, but got %d %v", announced, err)
+	}
+	announced, err := services.JobPost.AnnounceDue(publishedAt.Add(time.Second))
+	if err != nil || announced != 1 {
+		t.Fatalf("expected the job post to be announced, but got %d %v", announced, err)
+	}
+	if announced, _ := services.JobPost.AnnounceDue(publishedAt.Add(time.Minute)); announced != 0 {
+		t.Errorf("expected the job post to be announced once, but got %d", announced)
+	}
+	if got := published(); got != 1 {
+		t.Errorf("expected a single JobPostPublished event, but got %d", got)
+	}
+}
@@ -0,0 +1,118 @@
+package model_services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func (s *stubUserService) Export(id uint) (*models.UserExport, error) {
+	user, err := s.ByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserExport{
+		ExportedAt:         time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC),
+		Account:            models.ExportedAccount{ID: user.ID, Email: user.Email, Skills: []models.Skill{}},
+		CompanyProfile:     user.CompanyProfile,
+		CompanyMemberships: []models.CompanyMember{{CompanyID: 2, UserID: user.ID, Role: models.MemberOwner}},
+		JobPosts:           []models.JobPost{{Title: "Go Developer"}},
+		JobFeeds:           []models.JobFeed{{URL: "https://acme.example/feed.xml"}},
+		Applications:       []models.Application{},
+		Bookmarks:          []models.Bookmark{{JobPostID: 3}},
+		SavedSearches:      []models.SavedSearch{{Query: "q=golang", Frequency: models.AlertDaily}},
+		Webhooks:           []models.Webhook{{URL: "https://hooks.example.com/jobs"}},
+		APIKeys:            []models.APIKey{{Prefix: "jb_1234", Scopes: "jobs:read"}},
+		OAuthConnections:   []models.OAuthConnection{{Service: "dropbox"}},
+	}, nil
+}
+
+func TestExport(t *testing.T) {
+	stub := &stubUserService{user: models.User{
+		Email:          "jane@acme.com",
+		CompanyProfile: &models.CompanyProfile{CompanyName: "Acme"},
+	}}
+	stub.user.ID = 1
+	me := controllers.NewMe(nil, nil, stub, nil)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req = req.WithContext(llctx.WithUser(req.Context(), &stub.user))
+		rec := httptest.NewRecorder()
+		controllers.Handle(me.Export)(rec, req)
+		return rec
+	}
+
+	t.Run("zip", func(t *testing.T) {
+		rec := serve("/me/export")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+			t.Fatalf("expected a zip archive, but got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="export-2020-03-31.zip"` {
+			t.Errorf("unexpected Content-Disposition %q", got)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := map[string][]byte{}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name], _ = ioutil.ReadAll(rc)
+			rc.Close()
+		}
+		for _, name := range []string{"account.json", "company-profile.json", "company-memberships.json",
+			"job-posts.json", "job-feeds.json", "applications.json", "bookmarks.json",
+			"saved-searches.json", "webhooks.json", "api-keys.json", "oauth-connections.json"} {
+			if _, ok := files[name]; !ok {
+				t.Errorf("expected %s in the archive", name)
+			}
+		}
+		account := models.ExportedAccount{}
+		json.Unmarshal(files["account.json"], &account)
+		if account.Email != "jane@acme.com" {
+			t.Errorf("unexpected account %+v", account)
+		}
+		profile := models.CompanyProfile{}
+		json.Unmarshal(files["company-profile.json"], &profile)
+		if profile.CompanyName != "Acme" {
+			t.Errorf("unexpected company profile %+v", profile)
+		}
+		searches := []models.SavedSearch{}
+		json.Unmarshal(files["saved-searches.json"], &searches)
+		if len(searches) != 1 || searches[0].Frequency != models.AlertDaily {
+			t.Errorf("expected the saved searches with their alerts, but got %+v", searches)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rec := serve("/me/export?format=json")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("expected a JSON document, but got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		export := models.UserExport{}
+		json.Unmarshal(rec.Body.Bytes(), &export)
+		if export.Account.Email != "jane@acme.com" || len(export.JobPosts) != 1 || len(export.OAuthConnections) != 1 ||
+			len(export.CompanyMemberships) != 1 || len(export.Webhooks) != 1 || len(export.APIKeys) != 1 {
+			t.Errorf("unexpected export %+v", export)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if rec := serve("/me/export?format=xml"); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, but got %d", rec.Code)
+		}
+	})
+}
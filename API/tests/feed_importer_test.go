@@ -0,0 +1,351 @@
+package model_services_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/importer"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/publicnet"
+)
+
+const testJobFeed = `<?xml version="1.0" encoding="utf-8"?>
+<source>
+  <publisher>ACME</publisher>
+  <job>
+    <referencenumber><![CDATA[GO-1]]></referencenumber>
+    <title><![CDATA[Go developer]]></title>
+    <date><![CDATA[Mon, 05 Oct 2026 10:00:00 GMT]]></date>
+    <url><![CDATA[https://acme.example/jobs/go-1]]></url>
+    <category><![CDATA[Web Development]]></category>
+    <city><![CDATA[Toronto]]></city>
+    <country><![CDATA[Canada]]></country>
+    <description><![CDATA[<p>Build APIs</p>]]></description>
+  </job>
+  <job>
+    <referencenumber>QA-2</referencenumber>
+    <title>QA engineer</title>
+    <email>jobs@acme.example</email>
+    <category>Testing</category>
+    <description>Break things</description>
+  </job>
+</source>`
+
+func TestParseFeed(t *testing.T) {
+	entries, err := importer.ParseFeed(strings.NewReader(testJobFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, but got %+v", entries)
+	}
+	first := entries[0]
+	if first.Ref != "GO-1" || first.Description != "<p>Build APIs</p>" || first.ApplyAt != "https://acme.example/jobs/go-1" {
+		t.Errorf("unexpected first entry %+v", first)
+	}
+	if first.PublishedAt == nil || first.PublishedAt.Day() != 5 {
+		t.Errorf("expected first entry published on the 5th, but got %v", first.PublishedAt)
+	}
+	if len(first.Locations) != 2 || first.Locations[1] != "Canada" {
+		t.Errorf("expected locations [Toronto Canada], but got %q", first.Locations)
+	}
+	if entries[1].ApplyAt != "jobs@acme.example" {
+		t.Errorf("expected email to be used to apply, but got %q", entries[1].ApplyAt)
+	}
+
+	t.Run("SadPath: malformed feed", func(t *testing.T) {
+		if _, err := importer.ParseFeed(strings.NewReader("<source><job>")); err == nil {
+			t.Error("expected malformed feed to be an error")
+		}
+	})
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			io.WriteString(w, testJobFeed)
+		case "/moved.xml":
+			http.Redirect(w, r, "http://acme.example/feed.xml", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	// The test server only listens on the loopback address
+	fetcher := &importer.HTTPFetcher{Client: server.Client()}
+	fetcher.Client.CheckRedirect = publicnet.CheckRedirect
+
+	body, err := fetcher.Fetch(server.URL + "/feed.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if entries, err := importer.ParseFeed(body); err != nil || len(entries) != 2 {
+		t.Errorf("expected 2 entries, but got %d entries and %v", len(entries), err)
+	}
+
+	t.Run("SadPath: error status is an error", func(t *testing.T) {
+		if _, err := fetcher.Fetch(server.URL + "/missing.xml"); err == nil {
+			t.Error("expected 404 to be an error")
+		}
+	})
+
+	t.Run("SadPath: http is refused", func(t *testing.T) {
+		if _, err := fetcher.Fetch(strings.Replace(server.URL, "https:", "http:", 1) + "/feed.xml"); err == nil {
+			t.Error("expected an http URL to be refused")
+		}
+	})
+
+	t.Run("SadPath: redirects to http are not followed", func(t *testing.T) {
+		if _, err := fetcher.Fetch(server.URL + "/moved.xml"); err == nil {
+			t.Error("expected the redirect to http to be refused")
+		}
+	})
+
+	t.Run("SadPath: private addresses are not reached", func(t *testing.T) {
+		if _, err := importer.NewHTTPFetcher().Fetch(server.URL + "/feed.xml"); err == nil {
+			t.Error("expected the loopback server not to be reached")
+		}
+	})
+}
+
+func TestJobFeedURL(t *testing.T) {
+	fs := models.NewJobFeedService(nil)
+	for _, url := range []string{
+		"http://acme.example/feed.xml",
+		"https://localhost/feed.xml",
+		"https://10.0.0.8/feed.xml",
+		"https://169.254.169.254/latest/meta-data",
+	} {
+		feed := models.JobFeed{UserID: 1, URL: url, CategoryID: 3, LocationID: 4}
+		if err := fs.Create(&feed); err != models.ErrJobFeedURLInvalid {
+			t.Errorf("%s: expected %q error, but got %v", url, models.ErrJobFeedURLInvalid, err)
+		}
+	}
+}
+
+func TestFeedSyncer(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+		models.WithCategory(),
+		models.WithLocation(),
+		models.WithJobFeed(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	// The feed is read from a local file rewritten between syncs
+	file, err := ioutil.TempFile("", "job-feed-*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	writeFeed := func(content string) {
+		if err := ioutil.WriteFile(file.Name(), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetcher := importer.FetcherFunc(func(url string) (io.ReadCloser, error) {
+		return os.Open(file.Name())
+	})
+	syncer := importer.NewFeedSyncer(services.JobFeed, services.JobPost, services.Category, services.Location, fetcher)
+
+	feed := models.JobFeed{UserID: 1, URL: "https://acme.example/feed.xml", CategoryID: 3, LocationID: 4}
+	if err := services.JobFeed.Create(&feed); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFeed(testJobFeed)
+	if err := syncer.Sync(&feed, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if feed.LastCreated != 2 || feed.LastSyncedAt == nil {
+		t.Errorf("expected 2 created job posts, but got %+v", feed)
+	}
+	jobPosts, err := services.JobPost.ByJobFeedID(feed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jp := range jobPosts {
+		// Testing is not a category, and QA-2 has no location
+		if jp.ExternalRef == "QA-2" && (jp.CategoryID != 3 || jp.LocationID != 4) {
+			t.Errorf("expected feed defaults to be used, but got %+v", jp)
+		}
+		revision, err := services.JobPost.Revision(jp.ID, 1)
+		if err != nil || revision.AuthorID != feed.UserID {
+			t.Errorf("expected the feed owner to author the revision, but got %+v %v", revision, err)
+		}
+	}
+
+	// QA-2 disappears and GO-1 changes
+	writeFeed(strings.Replace(testJobFeed[:strings.Index(testJobFeed, "<job>\n    <referencenumber>QA-2")]+"</source>",
+		"Go developer", "Senior Go developer", 1))
+	if err := syncer.Sync(&feed, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if feed.LastCreated != 0 || feed.LastUpdated != 1 || feed.LastClosed != 1 {
+		t.Errorf("expected 1 updated and 1 closed job post, but got %+v", feed)
+	}
+	jobPosts, _ = services.JobPost.ByJobFeedID(feed.ID)
+	if open := openJobPosts(jobPosts); len(open) != 1 || open[0].Title != "Senior Go developer" {
+		t.Errorf("expected only the updated job post to be open, but got %+v", open)
+	}
+
+	t.Run("SadPath: unreadable feed closes nothing", func(t *testing.T) {
+		writeFeed("<source><job>")
+		if err := syncer.Sync(&feed, time.Now()); err == nil || feed.LastError == "" {
+			t.Errorf("expected the error to be recorded, but got %+v", feed)
+		}
+		if jobPosts, _ := services.JobPost.ByJobFeedID(feed.ID); len(openJobPosts(jobPosts)) != 1 {
+			t.Errorf("expected the job post to stay open, but got %+v", jobPosts)
+		}
+	})
+
+	t.Run("entries coming back are reopened", func(t *testing.T) {
+		writeFeed(testJobFeed)
+		if err := syncer.Sync(&feed, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if feed.LastCreated != 0 || feed.LastUpdated != 2 {
+			t.Errorf("expected QA-2 to be reopened and GO-1 updated, but got %+v", feed)
+		}
+		jobPosts, _ := services.JobPost.ByJobFeedID(feed.ID)
+		if len(jobPosts) != 2 || len(openJobPosts(jobPosts)) != 2 {
+			t.Errorf("expected the 2 job posts to be open, but got %+v", jobPosts)
+		}
+	})
+
+	t.Run("SadPath: entries are unique", func(t *testing.T) {
+		duplicate := models.JobPost{
+			UserID:      1,
+			Title:       "QA engineer",
+			LocationID:  4,
+			CategoryID:  3,
+			Description: "Duplicate",
+			ApplyAt:     "https://acme.example/apply",
+			JobFeedID:   feed.ID,
+			ExternalRef: "QA-2",
+		}
+		if err := services.JobPost.Create(&duplicate); err == nil {
+			t.Errorf("expected a second job post for QA-2 to be rejected")
+		}
+	})
+}
+
+// openJobPosts returns the job posts that are not closed
+func openJobPosts(jobPosts []models.JobPost) []models.JobPost {
+	var open []models.JobPost
+	for _, jp := range jobPosts {
+		if jp.ClosedAt == nil {
+			open = append(open, jp)
+		}
+	}
+	return open
+}
+
+// feedJobPostService holds the job posts of a feed in memory
+type feedJobPostService struct {
+	models.JobPostService
+	jobPosts map[string]*models.JobPost
+	created  int
+}
+
+func (s *feedJobPostService) ByJobFeedID(feedID uint) ([]models.JobPost, error) {
+	var jobPosts []models.JobPost
+	for _, jp := range s.jobPosts {
+		jobPosts = append(jobPosts, *jp)
+	}
+	return jobPosts, nil
+}
+
+func (s *feedJobPostService) Create(jobPost *models.JobPost) error {
+	s.created++
+	jp := *jobPost
+	s.jobPosts[jobPost.ExternalRef] = &jp
+	return nil
+}
+
+func (s *feedJobPostService) Update(jobPost *models.JobPost) error {
+	jp := *jobPost
+	s.jobPosts[jobPost.ExternalRef] = &jp
+	return nil
+}
+
+func (s *feedJobPostService) Close(id uint) error {
+	now := time.Now()
+	for _, jp := range s.jobPosts {
+		if jp.ID == id {
+			jp.ClosedAt = &now
+		}
+	}
+	return nil
+}
+
+type feedJobFeedService struct{ models.JobFeedService }
+
+func (feedJobFeedService) Update(feed *models.JobFeed) error { return nil }
+
+type feedCategoryService struct{ models.CategoryService }
+
+func (feedCategoryService) FindAll() ([]models.Category, error) { return nil, nil }
+
+type feedLocationService struct{ models.LocationService }
+
+func (feedLocationService) FindAll() ([]models.Location, error) { return nil, nil }
+
+func TestFeedSyncerReopens(t *testing.T) {
+	closedAt, deletedAt := time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)
+	goPost := &models.JobPost{Title: "Go developer", JobFeedID: 1, ExternalRef: "GO-1", ClosedAt: &closedAt}
+	goPost.ID = 1
+	qaPost := &models.JobPost{Title: "QA engineer", JobFeedID: 1, ExternalRef: "QA-2"}
+	qaPost.ID = 2
+	qaPost.DeletedAt = &deletedAt
+	js := &feedJobPostService{jobPosts: map[string]*models.JobPost{"GO-1": goPost, "QA-2": qaPost}}
+	fetcher := importer.FetcherFunc(func(url string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(testJobFeed)), nil
+	})
+	syncer := importer.NewFeedSyncer(feedJobFeedService{}, js, feedCategoryService{}, feedLocationService{}, fetcher)
+
+	feed := models.JobFeed{UserID: 1, CategoryID: 3, LocationID: 4}
+	feed.ID = 1
+	if err := syncer.Sync(&feed, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if js.created != 0 || feed.LastCreated != 0 {
+		t.Errorf("expected no job post to be created, but got %d", js.created)
+	}
+	if feed.LastUpdated != 1 || js.jobPosts["GO-1"].ClosedAt != nil {
+		t.Errorf("expected GO-1 to be reopened, but got %+v", js.jobPosts["GO-1"])
+	}
+	if js.jobPosts["QA-2"].Description != "" {
+		t.Errorf("expected the deleted QA-2 to be left alone, but got %+v", js.jobPosts["QA-2"])
+	}
+
+	// Closed job posts are not closed again
+	closedAt = time.Now()
+	js.jobPosts["GO-1"].ClosedAt = &closedAt
+	fetcher = importer.FetcherFunc(func(url string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("<source></source>")), nil
+	})
+	syncer = importer.NewFeedSyncer(feedJobFeedService{}, js, feedCategoryService{}, feedLocationService{}, fetcher)
+	if err := syncer.Sync(&feed, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if feed.LastClosed != 0 {
+		t.Errorf("expected nothing to be closed, but got %d", feed.LastClosed)
+	}
+}
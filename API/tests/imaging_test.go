@@ -0,0 +1,187 @@
+package model_services_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/imaging"
+	"github.com/samueldaviddelacruz/go-job-board/API/storage"
+)
+
+func newTestImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: alpha})
+		}
+	}
+	return img
+}
+
+func TestProcessLogo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newTestImage(2000, 1000, 200)); err != nil {
+		t.Fatal(err)
+	}
+	variants, err := imaging.ProcessLogo(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]int{
+		imaging.VariantOriginal:  {1024, 512},
+		imaging.VariantThumbnail: {256, 256},
+		imaging.VariantHeader:    {800, 400},
+	}
+	if len(variants) != len(want) {
+		t.Fatalf("expected %d variants, but got %d", len(want), len(variants))
+	}
+	for _, variant := range variants {
+		size := want[variant.Name]
+		if variant.Width != size[0] || variant.Height != size[1] {
+			t.Errorf("expected %s to be %dx%d, but got %dx%d", variant.Name, size[0], size[1], variant.Width, variant.Height)
+		}
+		if variant.ContentType != "image/png" {
+			t.Errorf("expected transparent %s to stay a PNG, but got %s", variant.Name, variant.ContentType)
+		}
+		cfg, err := png.DecodeConfig(bytes.NewReader(variant.Content))
+		if err != nil || cfg.Width != size[0] {
+			t.Errorf("expected %s to decode as a %dpx wide PNG, but got %+v %v", variant.Name, size[0], cfg, err)
+		}
+	}
+
+	t.Run("JPEG metadata is stripped", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, newTestImage(64, 64, 255), nil); err != nil {
+			t.Fatal(err)
+		}
+		// Insert an EXIF segment right after the SOI marker
+		exif := append([]byte("Exif\x00\x00"), []byte("GPS secret location")...)
+		segment := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+		data := append(append([]byte{}, buf.Bytes()[:2]...), segment...)
+		data = append(data, buf.Bytes()[2:]...)
+
+		variants, err := imaging.ProcessLogo(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, variant := range variants {
+			if variant.ContentType != "image/jpeg" {
+				t.Errorf("expected opaque %s to be a JPEG, but got %s", variant.Name, variant.ContentType)
+			}
+			if bytes.Contains(variant.Content, []byte("secret")) {
+				t.Errorf("expected the metadata to be stripped from %s", variant.Name)
+			}
+		}
+	})
+
+	t.Run("SadPath: GIF is not allowed", func(t *testing.T) {
+		wantError := imaging.ErrFormatUnsupported
+		var buf bytes.Buffer
+		if err := gif.Encode(&buf, newTestImage(64, 64, 255), nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := imaging.ProcessLogo(buf.Bytes()); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: tiny image is not allowed", func(t *testing.T) {
+		wantError := imaging.ErrImageTooSmall
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, newTestImage(16, 64, 255)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := imaging.ProcessLogo(buf.Bytes()); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := storage.NewLocal(dir, "http://localhost:5000/media/")
+
+	if err := store.Put("logos/1/abc-thumbnail.png", "image/png", strings.NewReader("logo")); err != nil {
+		t.Fatal(err)
+	}
+	if url := store.URL("logos/1/abc-thumbnail.png"); url != "http://localhost:5000/media/logos/1/abc-thumbnail.png" {
+		t.Errorf("unexpected URL %q", url)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/media", store.Handler()))
+	defer server.Close()
+	res, err := http.Get(server.URL + "/media/logos/1/abc-thumbnail.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != "logo" {
+		t.Errorf("expected the logo to be served, but got %d %q", res.StatusCode, body)
+	}
+	if cc := res.Header.Get("Cache-Control"); cc != storage.CacheControl {
+		t.Errorf("expected Cache-Control %q, but got %q", storage.CacheControl, cc)
+	}
+
+	if err := store.Delete("logos/1/abc-thumbnail.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logos", "1", "abc-thumbnail.png")); !os.IsNotExist(err) {
+		t.Errorf("expected the logo to be deleted, but got %v", err)
+	}
+
+	t.Run("SadPath: keys can not escape the directory", func(t *testing.T) {
+		wantError := storage.ErrKeyInvalid
+		if err := store.Put("../escaped.png", "image/png", strings.NewReader("x")); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+func TestS3Storage(t *testing.T) {
+	var gotAuth, gotPath, gotCache string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		gotCache = r.Header.Get("Cache-Control")
+		gotBody, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	store := storage.NewS3(storage.S3Config{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "logos",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	if err := store.Put("logos/1/abc-header.jpg", "image/jpeg", strings.NewReader("jpeg")); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/logos/logos/1/abc-header.jpg" || string(gotBody) != "jpeg" || gotCache != storage.CacheControl {
+		t.Errorf("unexpected request to %s with body %q and Cache-Control %q", gotPath, gotBody, gotCache)
+	}
+	wantScope := "Credential=AKIDEXAMPLE/" + time.Now().UTC().Format("20060102") + "/eu-west-1/s3/aws4_request"
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 "+wantScope) || !strings.Contains(gotAuth, "Signature=") {
+		t.Errorf("unexpected Authorization %q", gotAuth)
+	}
+	if url := store.URL("logos/1/abc-header.jpg"); url != server.URL+"/logos/logos/1/abc-header.jpg" {
+		t.Errorf("unexpected URL %q", url)
+	}
+}
@@ -0,0 +1,137 @@
+package model_services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/importer"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const testImportCSV = `Title,Description,ApplyAt,Category,Location,Skills
+Go developer,Build APIs,jobs@example.com,Web Development,Remote,golang; postgres
+"Frontend, React",Build UIs,jobs@example.com,web development,USA,js
+Broken row,only two
+`
+
+const testImportJSONLines = `{"title":"Go developer","description":"Build APIs","applyAt":"jobs@example.com","category":"Web Development","location":"Remote","skills":["go"]}
+
+{"title":"Typo","salary":100}
+`
+
+func TestParseImport(t *testing.T) {
+	rows, rowErrors, err := importer.Parse(importer.FormatCSV, strings.NewReader(testImportCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || len(rowErrors) != 1 || rowErrors[0].Row != 3 {
+		t.Fatalf("expected 2 rows and an error on row 3, but got %+v and %+v", rows, rowErrors)
+	}
+	if rows[1].Title != "Frontend, React" || rows[1].Number != 2 {
+		t.Errorf("expected quoted title on row 2, but got %+v", rows[1])
+	}
+	if len(rows[0].Skills) != 2 || rows[0].Skills[1] != "postgres" {
+		t.Errorf("expected skills [golang postgres], but got %q", rows[0].Skills)
+	}
+
+	rows, rowErrors, err = importer.Parse(importer.FormatJSONLines, strings.NewReader(testImportJSONLines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Skills[0] != "go" {
+		t.Errorf("expected 1 row with skill go, but got %+v", rows)
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Row != 3 {
+		t.Errorf("expected unknown field error on line 3, but got %+v", rowErrors)
+	}
+
+	t.Run("SadPath: unknown CSV column", func(t *testing.T) {
+		if _, _, err := importer.Parse(importer.FormatCSV, strings.NewReader("title,salary\n")); err == nil {
+			t.Error("expected an unknown column error")
+		}
+	})
+
+	t.Run("SadPath: unknown format", func(t *testing.T) {
+		wantError := importer.ErrFormatUnknown
+		if _, _, err := importer.Parse("xlsx", strings.NewReader("")); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+func TestBulkImport(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+		models.WithSkill(),
+		models.WithCategory(),
+		models.WithLocation(),
+		models.WithImportJob(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	bulk := importer.NewBulk(services.ImportJob, services.JobPost, services.Skill, services.Category, services.Location)
+	rows, rowErrors, err := importer.ParseCSV(strings.NewReader(testImportCSV +
+		"No category,Build,jobs@example.com,Gardening,Remote,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(dryRun bool) *models.ImportJob {
+		job := models.ImportJob{UserID: 1, Format: importer.FormatCSV, DryRun: dryRun}
+		job.Errors = append(job.Errors, rowErrors...)
+		if err := services.ImportJob.Create(&job); err != nil {
+			t.Fatal(err)
+		}
+		bulk.Run(&job, rows)
+		found, err := services.ImportJob.ByID(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found
+	}
+
+	job := run(true)
+	if job.Status != models.ImportCompleted || job.Imported != 2 || len(job.Errors) != 2 {
+		t.Errorf("expected 2 valid rows and 2 errors, but got %+v", job)
+	}
+	if jobPosts, _ := services.JobPost.ByUserID(1); len(jobPosts) != 0 {
+		t.Errorf("expected dry run not to create job posts, but got %d", len(jobPosts))
+	}
+
+	job = run(false)
+	if job.Imported != 2 {
+		t.Errorf("expected 2 imported rows, but got %+v", job)
+	}
+	jobPosts, _ := services.JobPost.ByUserID(1)
+	if len(jobPosts) != 2 {
+		t.Fatalf("expected 2 job posts, but got %d", len(jobPosts))
+	}
+	for _, jobPost := range jobPosts {
+		found, err := services.JobPost.ByIDWith(jobPost.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Title == "Go developer" && len(found.Skills) != 2 {
+			t.Errorf("expected the job post to be created with its 2 skills, but got %+v", found.Skills)
+		}
+		revision, err := services.JobPost.Revision(jobPost.ID, 1)
+		if err != nil || revision.AuthorID != 1 {
+			t.Errorf("expected the importing user to author the revision, but got %+v %v", revision, err)
+		}
+	}
+
+	t.Run("SadPath: unknown category is reported", func(t *testing.T) {
+		last := job.Errors[len(job.Errors)-1]
+		if last.Row != 4 || last.Field != "category" {
+			t.Errorf("expected category error on row 4, but got %+v", last)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package model_services_test
+
+import (
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestMemberRoles(t *testing.T) {
+	cases := []struct {
+		role                                  models.MemberRole
+		manageJobPosts, manageMembers, grants bool
+	}{
+		{models.MemberOwner, true, true, true},
+		{models.MemberAdmin, true, true, false},
+		{models.MemberRecruiter, true, false, false},
+		{models.MemberViewer, false, false, false},
+	}
+	for _, c := range cases {
+		if got := c.role.CanManageJobPosts(); got != c.manageJobPosts {
+			t.Errorf("expected %s CanManageJobPosts to be %v, but got %v", c.role, c.manageJobPosts, got)
+		}
+		if got := c.role.CanManageMembers(); got != c.manageMembers {
+			t.Errorf("expected %s CanManageMembers to be %v, but got %v", c.role, c.manageMembers, got)
+		}
+		if got := c.role.CanGrant(models.MemberOwner); got != c.grants {
+			t.Errorf("expected %s CanGrant owner to be %v, but got %v", c.role, c.grants, got)
+		}
+		if !c.role.CanView() {
+			t.Errorf("expected %s to be able to view the company", c.role)
+		}
+	}
+	if !models.MemberAdmin.CanGrant(models.MemberRecruiter) {
+		t.Errorf("expected admins to be able to make recruiters")
+	}
+	if models.MemberRole("intern").CanView() {
+		t.Errorf("expected unknown roles not to be able to view the company")
+	}
+}
+
+func TestCompanyService(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithUser("pepperhere", "randomtesthmacvalue"),
+		models.WithJobPost(),
+		models.WithCompany("randomtesthmacvalue"),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	owner := models.User{Email: "owner@acme.com", Password: "megaman007"}
+	recruiter := models.User{Email: "recruiter@acme.com", Password: "megaman007"}
+	for _, user := range []*models.User{&owner, &recruiter} {
+		if err := services.User.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	owner.CompanyProfile = &models.CompanyProfile{CompanyName: "Acme"}
+	if err := services.User.Update(&owner); err != nil {
+		t.Fatal(err)
+	}
+	companyID := owner.CompanyProfile.CompanyID
+	if companyID == 0 {
+		t.Fatalf("expected a company to be created along the profile, but got %+v", owner.CompanyProfile)
+	}
+
+	invitation := models.CompanyInvitation{CompanyID: companyID, Email: " Recruiter@Acme.com ", Role: models.MemberRecruiter}
+	if err := services.Company.CreateInvitation(&invitation); err != nil {
+		t.Fatal(err)
+	}
+	if invitation.Token == "" || invitation.TokenHash == invitation.Token || invitation.Email != "recruiter@acme.com" {
+		t.Errorf("expected a hashed token and a normalized email, but got %+v", invitation)
+	}
+	member, err := services.Company.AcceptInvitation(invitation.Token, &recruiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.CompanyID != companyID || member.Role != models.MemberRecruiter {
+		t.Errorf("expected a recruiter of company %d, but got %+v", companyID, member)
+	}
+
+	// Job posts of recruiters go to their company
+	jobPost := models.JobPost{UserID: recruiter.ID, Title: "Gopher", LocationID: 1, CategoryID: 1, Description: "Go", ApplyAt: "jobs@acme.com"}
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	if jobPost.CompanyID != companyID {
+		t.Errorf("expected the job post to belong to company %d, but got %d", companyID, jobPost.CompanyID)
+	}
+	profile, err := services.User.CompanyProfileForJobPost(&jobPost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.CompanyName != "Acme" {
+		t.Errorf("expected the job post to be listed under Acme, but got %+v", profile)
+	}
+
+	t.Run("SadPath: invitation can only be accepted once", func(t *testing.T) {
+		wantError := models.ErrInvitationInvalid
+		if _, err := services.Company.AcceptInvitation(invitation.Token, &recruiter); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: invitation is bound to its email address", func(t *testing.T) {
+		wantError := models.ErrInvitationEmailMismatch
+		other := models.CompanyInvitation{CompanyID: companyID, Email: "someone@acme.com", Role: models.MemberViewer}
+		if err := services.Company.CreateInvitation(&other); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := services.Company.AcceptInvitation(other.Token, &owner); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: members can not be invited again", func(t *testing.T) {
+		wantError := models.ErrMemberExists
+		again := models.CompanyInvitation{CompanyID: companyID, Email: "recruiter@acme.com", Role: models.MemberAdmin}
+		if err := services.Company.CreateInvitation(&again); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: recruiters can not manage members", func(t *testing.T) {
+		wantError := models.ErrMemberRoleForbidden
+		if _, err := services.Company.Authorize(companyID, recruiter.ID, models.MemberRole.CanManageMembers); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: last owner can not leave", func(t *testing.T) {
+		wantError := models.ErrLastOwner
+		if err := services.Company.RemoveMember(companyID, owner.ID); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+		demoted := models.CompanyMember{CompanyID: companyID, UserID: owner.ID, Role: models.MemberAdmin}
+		if err := services.Company.SetMemberRole(&demoted); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
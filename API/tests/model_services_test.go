@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/samueldaviddelacruz/go-job-board/API/models"
 )
@@ -63,6 +64,7 @@ func TestUserService(t *testing.T) {
 	t.Run("Find", testUserService_Find(services.User))
 	t.Run("Update", testUserService_Update(services.User, services.Skill))
 	t.Run("Delete", testUserService_Delete(services.User))
+	t.Run("Erase", testUserService_Erase(services.User))
 
 }
 
@@ -72,6 +74,21 @@ func testUserService_Update(us models.UserService, ss models.SkillsService) func
 		t.Run("CompanyProfile", func(t *testing.T) {
 			user := findUserByID(us, 1, t)
 			companyProfile := testUpdateCompanyProfileFields(user, us, t)
+			t.Run("user changes keep the profile version", func(t *testing.T) {
+				user := findUserByID(us, 1, t)
+				version := user.CompanyProfile.Version
+				user.Email = "ps3_4@hotmail.com"
+				if err := us.Update(user); err != nil {
+					t.Fatal(err)
+				}
+				if got := findUserByID(us, 1, t).CompanyProfile.Version; got != version {
+					t.Errorf("expected profile version %d, but got %d", version, got)
+				}
+				user.Email = "ps3_3@hotmail.com"
+				if err := us.Update(user); err != nil {
+					t.Fatal(err)
+				}
+			})
 			testAddCompanyProfileSkill(t, ss, companyProfile)
 			testRemoveCompanyProfileSkill(t, ss, companyProfile)
 			testAddCompanyProfileBenefit(t, us, companyProfile)
@@ -241,6 +258,54 @@ func testUserService_Delete(us models.UserService) func(t *testing.T) {
 		if err := us.Delete(1); err != nil {
 			t.Error(err)
 		}
+
+		t.Run("Purge", func(t *testing.T) {
+			purged, err := us.PurgeDeleted(time.Now().Add(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purged != 1 {
+				t.Errorf("expected %d user to be purged, but got %d", 1, purged)
+			}
+			if err := us.Restore(1); err != models.ErrNotFound {
+				t.Errorf("expected the user to be gone, but got %v", err)
+			}
+		})
+	}
+}
+
+func testUserService_Erase(us models.UserService) func(t *testing.T) {
+	return func(t *testing.T) {
+		user := models.User{
+			Email:    "erased@hotmail.com",
+			Password: "megaman007",
+		}
+		if err := us.Create(&user); err != nil {
+			t.Fatal(err)
+		}
+		if err := us.ScheduleDeletion(&user); err != nil {
+			t.Fatal(err)
+		}
+		export, err := us.Export(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if export.Account.Email != user.Email || export.Account.DeletionScheduledAt == nil {
+			t.Errorf("unexpected export %+v", export.Account)
+		}
+		if erased, err := us.EraseScheduled(time.Now()); err != nil || erased != 0 {
+			t.Fatalf("expected the grace period to be kept, but got %d %v", erased, err)
+		}
+		erased, err := us.EraseScheduled(*export.Account.DeletionScheduledAt)
+		if err != nil || erased != 1 {
+			t.Fatalf("expected the account to be erased, but got %d %v", erased, err)
+		}
+		if _, err := us.ByEmail("erased@hotmail.com"); err != models.ErrNotFound {
+			t.Errorf("expected the email to be erased, but got %v", err)
+		}
+		if _, err := us.Authenticate("erased@hotmail.com", "megaman007"); err == nil {
+			t.Errorf("expected the erased account not to sign in")
+		}
 	}
 }
 
@@ -378,9 +443,60 @@ func testCategoriesService_Find(cs models.CategoryService, getCategoriesSeed fun
 
 func testJobsService_Delete(jobPostService models.JobPostService) func(t *testing.T) {
 	return func(t *testing.T) {
+		jobPost := findJobByID(jobPostService, 1, t)
 		if err := jobPostService.Delete(1); err != nil {
-			t.Error(err)
+			t.Fatal(err)
+		}
+		if _, err := jobPostService.ByID(1); err != models.ErrNotFound {
+			t.Errorf("expected deleted job posts not to be found, but got %v", err)
 		}
+
+		t.Run("Trash", func(t *testing.T) {
+			deleted, err := jobPostService.Deleted(jobPost.UserID, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deleted) != 1 || deleted[0].ID != 1 {
+				t.Errorf("expected the job post in the trash, but got %v", deleted)
+			}
+			if deleted, _ := jobPostService.Deleted(jobPost.UserID+1, nil); len(deleted) != 0 {
+				t.Errorf("expected the trash of other users to be empty, but got %v", deleted)
+			}
+		})
+
+		t.Run("Restore", func(t *testing.T) {
+			if err := jobPostService.Restore(1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := jobPostService.ByID(1); err != nil {
+				t.Errorf("expected the job post to be restored, but got %v", err)
+			}
+			if err := jobPostService.Restore(1); err != models.ErrNotFound {
+				t.Errorf("expected job posts that are not deleted not to be restored, but got %v", err)
+			}
+		})
+
+		t.Run("Purge", func(t *testing.T) {
+			if err := jobPostService.Purge(1); err != models.ErrNotFound {
+				t.Errorf("expected job posts that are not deleted not to be purged, but got %v", err)
+			}
+			if err := jobPostService.Delete(1); err != nil {
+				t.Fatal(err)
+			}
+			purged, err := jobPostService.PurgeDeleted(time.Now().Add(time.Minute))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if purged != 1 {
+				t.Errorf("expected %d job post to be purged, but got %d", 1, purged)
+			}
+			if _, err := jobPostService.DeletedByID(1); err != models.ErrNotFound {
+				t.Errorf("expected the job post to be gone, but got %v", err)
+			}
+			if revisions, _ := jobPostService.Revisions(1); len(revisions) != 0 {
+				t.Errorf("expected the revisions to be purged, but got %v", revisions)
+			}
+		})
 	}
 }
 
@@ -401,15 +517,140 @@ func testJobsService_Update(jobPostService models.JobPostService, skillsService
 			compareJobPostsFields(*got, want, t)
 		})
 
+		t.Run("SadPath: stale version", func(t *testing.T) {
+			stale := findJobByID(jobPostService, 1, t)
+			stale.Version--
+			stale.Title = "Golang Dev Wanted 3"
+			if err := jobPostService.Update(stale); !isError(err, models.ErrVersionConflict) {
+				t.Errorf("expected error to be %v, but got %v", models.ErrVersionConflict, err)
+			}
+			if want := findJobByID(jobPostService, 1, t); want.Title != got.Title || want.Version != got.Version {
+				t.Errorf("expected the job post not to change, but got %+v", want)
+			}
+		})
+
+		version := got.Version
 		testAddSkill(t, skillsService, got)
+		t.Run("skills change the version", func(t *testing.T) {
+			stored := findJobByID(jobPostService, got.ID, t)
+			if stored.Version != version+1 || got.Version != stored.Version {
+				t.Errorf("expected version %d, but got %d stored and %d returned", version+1, stored.Version, got.Version)
+			}
+		})
+		testSetSkillRequirement(t, jobPostService, got)
 
 		testRemoveSkill(t, skillsService, got)
 		if len(got.Skills) != 0 {
 			t.Errorf("expected skills list to be empty, but got = %v elements", len(got.Skills))
 		}
+
+		t.Run("Revisions", func(t *testing.T) {
+			revisions, err := jobPostService.Revisions(got.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Created, updated, skill added, requirement changed and
+			// skill removed
+			if len(revisions) != 5 {
+				t.Fatalf("expected %d revisions, but got %d", 5, len(revisions))
+			}
+			if changes := revisions[1].Changes; len(changes) == 0 || changes[0].Field != "title" {
+				t.Errorf("expected the update to change the title, but got %+v", changes)
+			}
+			if len(revisions[2].SkillsAdded) != 1 || len(revisions[4].SkillsRemoved) != 1 {
+				t.Errorf("expected the skill to be added then removed, but got %+v", revisions[2:])
+			}
+			if changes := revisions[3].Changes; len(changes) != 1 || changes[0].Field != "skillRequirements" {
+				t.Errorf("expected the requirement change to be recorded, but got %+v", changes)
+			}
+
+			current := findJobByID(jobPostService, got.ID, t)
+			if err := jobPostService.RestoreRevision(current, &revisions[3]); err != nil {
+				t.Fatal(err)
+			}
+			filters := models.JobPost{}
+			filters.ID = got.ID
+			found, err := jobPostService.FindAll(filters)
+			if err != nil || len(found) != 1 {
+				t.Fatalf("expected the restored job post, but got %v %v", found, err)
+			}
+			want := models.JobPostSkill{JobPostID: got.ID, SkillID: 1, MinProficiency: models.ProficiencyAdvanced, MinYears: 3}
+			if reqs := found[0].SkillRequirements; len(reqs) != 1 || reqs[0] != want {
+				t.Errorf("expected the requirement to be restored as it was, but got %+v", reqs)
+			}
+			if revisions, _ := jobPostService.Revisions(got.ID); len(revisions) != 6 {
+				t.Errorf("expected the restore to be a new revision, but got %d revisions", len(revisions))
+			}
+		})
+
+		t.Run("AddSkill", func(t *testing.T) {
+			req := models.JobPostSkill{JobPostID: got.ID, SkillID: 2, MinYears: 1}
+			if err := jobPostService.AddSkill(&req); err != nil {
+				t.Fatal(err)
+			}
+			filters := models.JobPost{}
+			filters.ID = got.ID
+			found, err := jobPostService.FindAll(filters)
+			if err != nil || len(found) != 1 {
+				t.Fatalf("expected the job post, but got %v %v", found, err)
+			}
+			added := false
+			for _, stored := range found[0].SkillRequirements {
+				added = added || stored == req
+			}
+			if !added {
+				t.Errorf("expected the skill to be added as optional, but got %+v", found[0].SkillRequirements)
+			}
+			if revisions, _ := jobPostService.Revisions(got.ID); len(revisions) != 7 {
+				t.Errorf("expected the skill and its requirement to be a single revision, but got %d revisions", len(revisions))
+			}
+
+			t.Run("SadPath: unknown skill is not added", func(t *testing.T) {
+				unknown := models.JobPostSkill{JobPostID: got.ID, SkillID: 100000}
+				if err := jobPostService.AddSkill(&unknown); err != models.ErrSkillUnknown {
+					t.Errorf("should return %q error got %q error", models.ErrSkillUnknown, err)
+				}
+			})
+		})
 	}
 }
 
+func testSetSkillRequirement(t *testing.T, jobPostService models.JobPostService, got *models.JobPost) {
+	t.Run("skill-requirement", func(t *testing.T) {
+		req := models.JobPostSkill{
+			JobPostID:      got.ID,
+			SkillID:        1,
+			Required:       false,
+			MinProficiency: models.ProficiencyAdvanced,
+			MinYears:       3,
+		}
+		if err := jobPostService.SetSkillRequirement(&req); err != nil {
+			t.Fatal(err)
+		}
+
+		filters := models.JobPost{}
+		filters.ID = got.ID
+		found, err := jobPostService.FindAll(filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || len(found[0].SkillRequirements) != 1 {
+			t.Fatalf("expected job post to have %d skill requirement, but got %v", 1, found)
+		}
+		if gotReq := found[0].SkillRequirements[0]; gotReq != req {
+			t.Errorf("skill requirement did not update correctly got = %v, want = %v", gotReq, req)
+		}
+
+		t.Run("SadPath: unknown proficiency is not allowed", func(t *testing.T) {
+			wantError := models.ErrProficiencyInvalid
+			req.MinProficiency = "guru"
+			if err := jobPostService.SetSkillRequirement(&req); err != wantError {
+				t.Errorf("should return %q error got %q error", wantError, err)
+			}
+		})
+	})
+}
+
 func testJobsService_Find(jobPostService models.JobPostService) func(t *testing.T) {
 	return func(t *testing.T) {
 		want := models.JobPost{
@@ -500,6 +741,28 @@ func testJobsService_Find(jobPostService models.JobPostService) func(t *testing.
 					}
 				}
 			})
+			t.Run("Scheduled", func(t *testing.T) {
+				publishedAt := time.Now().Add(time.Hour)
+				scheduled := models.JobPost{
+					Title:       "Golang Dev Scheduled",
+					Description: "Golang Dev Wanted",
+					ApplyAt:     "samysoft@gmail.com",
+					UserID:      1,
+					LocationID:  1,
+					CategoryID:  2,
+					PublishedAt: &publishedAt,
+				}
+				if err := jobPostService.Create(&scheduled); err != nil {
+					t.Fatal(err)
+				}
+				got, err := jobPostService.FindAll(models.JobPost{Title: scheduled.Title})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != 0 {
+					t.Errorf("expected scheduled job posts to be hidden, but got %d job posts", len(got))
+				}
+			})
 
 		})
 		t.Run("ByID", func(t *testing.T) {
@@ -623,7 +886,7 @@ func testJobsService_Create(jobPostService models.JobPostService) func(t *testin
 				jp := mockJobPost()
 				spt.jobPostModifier(&jp)
 				wantError := spt.expectedError
-				if err := jobPostService.Create(&jp); err != wantError {
+				if err := jobPostService.Create(&jp); !isError(err, wantError) {
 					t.Errorf("should return %q error got %q error", wantError, err)
 				}
 			})
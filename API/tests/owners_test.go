@@ -0,0 +1,363 @@
+package model_services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/middleware"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// ownedSkillsService records the skills added to their owner.
+type ownedSkillsService struct {
+	models.SkillsService
+	added []models.Skill
+}
+
+func (s *ownedSkillsService) AddSkillToOwner(owner interface{}, skill models.Skill) error {
+	s.added = append(s.added, skill)
+	return nil
+}
+
+// ownedSavedSearchService serves saved searches of user 1 and
+// fails the test when anything is changed.
+type ownedSavedSearchService struct {
+	models.SavedSearchService
+	t *testing.T
+}
+
+func (s ownedSavedSearchService) ByUserID(userID uint) ([]models.SavedSearch, error) {
+	if userID != 1 {
+		s.t.Errorf("expected the saved searches of user 1, but got the ones of user %d", userID)
+	}
+	return []models.SavedSearch{{UserID: 1}}, nil
+}
+
+func (s ownedSavedSearchService) ByID(id uint) (*models.SavedSearch, error) {
+	search := models.SavedSearch{UserID: 1}
+	search.ID = id
+	return &search, nil
+}
+
+func (s ownedSavedSearchService) Create(search *models.SavedSearch) error {
+	s.t.Errorf("expected no saved search to be created, but got %+v", search)
+	return nil
+}
+
+func (s ownedSavedSearchService) Delete(id uint) error {
+	s.t.Errorf("expected saved search %d not to be deleted", id)
+	return nil
+}
+
+// ownedWebhookService serves webhooks of user 1 and fails the
+// test when anything is changed.
+type ownedWebhookService struct {
+	models.WebhookService
+	t *testing.T
+}
+
+func (s ownedWebhookService) ByUserID(userID uint) ([]models.Webhook, error) {
+	if userID != 1 {
+		s.t.Errorf("expected the webhooks of user 1, but got the ones of user %d", userID)
+	}
+	return []models.Webhook{{UserID: 1}}, nil
+}
+
+func (s ownedWebhookService) ByID(id uint) (*models.Webhook, error) {
+	webhook := models.Webhook{UserID: 1}
+	webhook.ID = id
+	return &webhook, nil
+}
+
+func (s ownedWebhookService) Create(webhook *models.Webhook) error {
+	s.t.Errorf("expected no webhook to be created, but got %+v", webhook)
+	return nil
+}
+
+func (s ownedWebhookService) Delete(id uint) error {
+	s.t.Errorf("expected webhook %d not to be deleted", id)
+	return nil
+}
+
+func (s ownedWebhookService) Deliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	s.t.Errorf("expected the deliveries of webhook %d not to be read", webhookID)
+	return nil, nil
+}
+
+// ownedAPIKeyService serves API keys of user 1 and records the
+// keys created.
+type ownedAPIKeyService struct {
+	models.APIKeyService
+	t       *testing.T
+	created []models.APIKey
+}
+
+func (s *ownedAPIKeyService) ByUserID(userID uint) ([]models.APIKey, error) {
+	if userID != 1 {
+		s.t.Errorf("expected the API keys of user 1, but got the ones of user %d", userID)
+	}
+	return []models.APIKey{{UserID: 1}}, nil
+}
+
+func (s *ownedAPIKeyService) ByID(id uint) (*models.APIKey, error) {
+	apiKey := models.APIKey{UserID: 1}
+	apiKey.ID = id
+	return &apiKey, nil
+}
+
+func (s *ownedAPIKeyService) Create(apiKey *models.APIKey) error {
+	s.created = append(s.created, *apiKey)
+	return nil
+}
+
+func (s *ownedAPIKeyService) Revoke(id uint) error {
+	s.t.Errorf("expected API key %d not to be revoked", id)
+	return nil
+}
+
+// memberCompanyService makes user 1 a recruiter of company 4
+// and nothing else.
+type memberCompanyService struct {
+	models.CompanyService
+}
+
+func (s memberCompanyService) Member(companyID, userID uint) (*models.CompanyMember, error) {
+	if companyID != 4 || userID != 1 {
+		return nil, models.ErrNotFound
+	}
+	return &models.CompanyMember{CompanyID: companyID, UserID: userID, Role: models.MemberRecruiter}, nil
+}
+
+func (s memberCompanyService) Authorize(companyID, userID uint, allowed func(models.MemberRole) bool) (*models.CompanyMember, error) {
+	member, err := s.Member(companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed(member.Role) {
+		return nil, models.ErrMemberRoleForbidden
+	}
+	return member, nil
+}
+
+// ownedJobFeedService serves job feeds of user 1 and fails the
+// test when anything is changed.
+type ownedJobFeedService struct {
+	models.JobFeedService
+	t *testing.T
+}
+
+func (s ownedJobFeedService) ByUserID(userID uint) ([]models.JobFeed, error) {
+	if userID != 1 {
+		s.t.Errorf("expected the job feeds of user 1, but got the ones of user %d", userID)
+	}
+	return []models.JobFeed{{UserID: 1}}, nil
+}
+
+func (s ownedJobFeedService) ByID(id uint) (*models.JobFeed, error) {
+	feed := models.JobFeed{UserID: 1}
+	feed.ID = id
+	return &feed, nil
+}
+
+func (s ownedJobFeedService) Create(feed *models.JobFeed) error {
+	s.t.Errorf("expected no job feed to be created, but got %+v", feed)
+	return nil
+}
+
+func (s ownedJobFeedService) Delete(id uint) error {
+	s.t.Errorf("expected job feed %d not to be deleted", id)
+	return nil
+}
+
+// serveOwned serves the request as the caller, with the URL
+// variables provided.
+func serveOwned(fn controllers.HandlerFunc, method, body string, vars map[string]string, caller *models.User) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req = mux.SetURLVars(req, vars)
+	if caller != nil {
+		req = req.WithContext(llctx.WithUser(req.Context(), caller))
+	}
+	rec := httptest.NewRecorder()
+	controllers.Handle(fn)(rec, req)
+	return rec
+}
+
+func TestOwnedResources(t *testing.T) {
+	owner, other := &models.User{CompanyProfile: &models.CompanyProfile{}}, &models.User{}
+	owner.ID, other.ID = 1, 2
+
+	t.Run("skills", func(t *testing.T) {
+		skills := &ownedSkillsService{}
+		users := controllers.NewUsers(&stubUserService{user: *owner}, skills, nil)
+		vars := map[string]string{"id": "1"}
+		if rec := serveOwned(users.AddSkill, "PUT", `{"id":3}`, vars, owner); rec.Code != http.StatusOK {
+			t.Errorf("expected the owner to add a skill, but got %d", rec.Code)
+		}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"AddSkill":        users.AddSkill,
+			"RemoveSkill":     users.RemoveSkill,
+			"Recommendations": users.Recommendations,
+		} {
+			if rec := serveOwned(fn, "PUT", `{"id":3}`, vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+		}
+		if len(skills.added) != 1 {
+			t.Errorf("expected a single skill to be added, but got %+v", skills.added)
+		}
+	})
+
+	t.Run("saved searches", func(t *testing.T) {
+		searches := controllers.NewSavedSearches(ownedSavedSearchService{t: t})
+		vars := map[string]string{"id": "1", "searchId": "3"}
+		if rec := serveOwned(searches.List, "GET", "", vars, owner); rec.Code != http.StatusOK {
+			t.Errorf("expected the owner to list their saved searches, but got %d", rec.Code)
+		}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"List":   searches.List,
+			"Create": searches.Create,
+			"Delete": searches.Delete,
+		} {
+			if rec := serveOwned(fn, "POST", `{"query":"q=go"}`, vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+		}
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		hooks := controllers.NewWebhooks(ownedWebhookService{t: t}, nil)
+		vars := map[string]string{"id": "1", "hookId": "3"}
+		if rec := serveOwned(hooks.List, "GET", "", vars, owner); rec.Code != http.StatusOK {
+			t.Errorf("expected the owner to list their webhooks, but got %d", rec.Code)
+		}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"List":       hooks.List,
+			"Create":     hooks.Create,
+			"Delete":     hooks.Delete,
+			"Deliveries": hooks.Deliveries,
+			"SendTest":   hooks.SendTest,
+		} {
+			if rec := serveOwned(fn, "POST", `{"url":"https://hooks.example.com"}`, vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+		}
+	})
+
+	t.Run("API keys", func(t *testing.T) {
+		stub := &ownedAPIKeyService{t: t}
+		apiKeys := controllers.NewAPIKeys(stub, memberCompanyService{})
+		vars := map[string]string{"id": "1", "keyId": "3"}
+		body := `{"companyId":4,"name":"ATS","scopes":"jobs:write"}`
+		if rec := serveOwned(apiKeys.Create, "POST", body, vars, owner); rec.Code != http.StatusCreated {
+			t.Errorf("expected the owner to create an API key, but got %d", rec.Code)
+		}
+		foreign := `{"companyId":5,"name":"ATS","scopes":"jobs:write"}`
+		if rec := serveOwned(apiKeys.Create, "POST", foreign, vars, owner); rec.Code != http.StatusNotFound {
+			t.Errorf("expected a key for another company to get status 404, but got %d", rec.Code)
+		}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"List":   apiKeys.List,
+			"Create": apiKeys.Create,
+			"Revoke": apiKeys.Revoke,
+		} {
+			if rec := serveOwned(fn, "POST", body, vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+		}
+		if len(stub.created) != 1 || stub.created[0].UserID != 1 || stub.created[0].CompanyID != 4 {
+			t.Errorf("expected a single key to be created for the owner, but got %+v", stub.created)
+		}
+	})
+
+	t.Run("logos", func(t *testing.T) {
+		logos := controllers.NewLogos(&stubUserService{user: *owner}, memberCompanyService{}, nil)
+		vars := map[string]string{"id": "1"}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"Upload": logos.Upload,
+			"Delete": logos.Delete,
+		} {
+			if rec := serveOwned(fn, "PUT", "", vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+			if rec := serveOwned(fn, "PUT", "", vars, owner); rec.Code != http.StatusPreconditionRequired {
+				t.Errorf("%s: expected status 428 without If-Match, but got %d", name, rec.Code)
+			}
+		}
+	})
+
+	t.Run("job feeds", func(t *testing.T) {
+		feeds := controllers.NewJobFeeds(ownedJobFeedService{t: t}, nil)
+		vars := map[string]string{"id": "1", "feedId": "3"}
+		if rec := serveOwned(feeds.List, "GET", "", vars, owner); rec.Code != http.StatusOK {
+			t.Errorf("expected the owner to list their job feeds, but got %d", rec.Code)
+		}
+		for name, fn := range map[string]controllers.HandlerFunc{
+			"List":   feeds.List,
+			"Create": feeds.Create,
+			"Delete": feeds.Delete,
+			"Sync":   feeds.Sync,
+		} {
+			if rec := serveOwned(fn, "POST", `{"url":"https://acme.example/feed.xml"}`, vars, other); rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected other users to get status 404, but got %d", name, rec.Code)
+			}
+		}
+	})
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	aks := models.NewAPIKeyService(nil, "test-hmac-key")
+	for _, scopes := range []string{"", " , ", "jobs:admin", "jobs:read,users:write", "*"} {
+		apiKey := models.APIKey{UserID: 1, CompanyID: 4, Scopes: scopes}
+		if err := aks.Create(&apiKey); err != models.ErrAPIKeyScopeInvalid {
+			t.Errorf("%q: expected %q error, but got %v", scopes, models.ErrAPIKeyScopeInvalid, err)
+		}
+	}
+	apiKey := models.APIKey{UserID: 1, Scopes: "jobs:read"}
+	if err := aks.Create(&apiKey); err != models.ErrCompanyIDRequired {
+		t.Errorf("expected %q error, but got %v", models.ErrCompanyIDRequired, err)
+	}
+}
+
+// companyAPIKeyService authenticates the key "jbk_1.secret" of
+// user 1, issued for company CompanyID.
+type companyAPIKeyService struct {
+	models.APIKeyService
+	companyID uint
+}
+
+func (s companyAPIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if key != "jbk_1.secret" {
+		return nil, models.ErrAPIKeyInvalid
+	}
+	return &models.APIKey{UserID: 1, CompanyID: s.companyID, Scopes: "jobs:write"}, nil
+}
+
+func TestAPIKeyCompany(t *testing.T) {
+	owner := models.User{CompanyProfile: &models.CompanyProfile{}}
+	owner.ID = 1
+	serve := func(companyID uint, body string) int {
+		mw := middleware.Auth{
+			User:      middleware.User{Secret: "test-hmac-key", UserService: &stubUserService{user: owner}},
+			APIKeys:   companyAPIKeyService{companyID: companyID},
+			Companies: memberCompanyService{},
+		}
+		jobs := controllers.NewJobs(nil, nil, nil, nil, memberCompanyService{})
+		req := httptest.NewRequest("POST", "/jobs", strings.NewReader(body))
+		req.Header.Set(middleware.APIKeyHeader, "jbk_1.secret")
+		rec := httptest.NewRecorder()
+		mw.RequireFn(models.ScopeJobsWrite, controllers.Handle(jobs.Create))(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(5, `{"title":"Go developer"}`); code != http.StatusUnauthorized {
+		t.Errorf("expected a key of a company its owner left to get status 401, but got %d", code)
+	}
+	if code := serve(4, `{"companyId":5,"title":"Go developer"}`); code != http.StatusNotFound {
+		t.Errorf("expected a post for another company to get status 404, but got %d", code)
+	}
+}
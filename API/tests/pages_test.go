@@ -0,0 +1,160 @@
+package model_services_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/pages"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+)
+
+func TestSlugify(t *testing.T) {
+	for input, want := range map[string]string{
+		"Senior Go Developer":       "senior-go-developer",
+		"  C++ / Qt (Remote!) ":     "c-qt-remote",
+		"Café Ünïcode":              "caf-n-code",
+		strings.Repeat("word ", 20): "word-word-word-word-word-word-word-word-word-word-word-word",
+	} {
+		if got := models.Slugify(input); got != want {
+			t.Errorf("expected %q to be slugified as %q, but got %q", input, want, got)
+		}
+	}
+}
+
+func TestRenderPages(t *testing.T) {
+	published := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	jobPost := models.JobPost{
+		Title:          "Go <developer>",
+		Slug:           "go-developer-7",
+		Description:    "<p>Build APIs</p>\n\nand " + strings.Repeat("more ", 50),
+		ApplyAt:        "jobs@acme.example",
+		PublishedAt:    &published,
+		Location:       &models.Location{LocationName: "Canada"},
+		SalaryMin:      90000,
+		SalaryMax:      120000,
+		SalaryCurrency: "USD",
+		SalaryPeriod:   models.SalaryYear,
+	}
+	jobPost.ID = 7
+	company := models.CompanyProfile{CompanyName: "Acme", Slug: "acme", CompanyLogoUrl: "https://cdn.example/acme.png"}
+	renderer := pages.NewRenderer(seo.NewURLs("https://jobs.example"))
+
+	var buf bytes.Buffer
+	if err := renderer.JobPost(&buf, pages.JobPostPage{JobPost: jobPost, Company: &company}); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		`<link rel="canonical" href="https://jobs.example/j/go-developer-7">`,
+		`<meta property="og:title" content="Go &lt;developer&gt; at Acme">`,
+		`<meta property="og:image" content="https://cdn.example/acme.png">`,
+		`<meta name="twitter:card" content="summary">`,
+		`<script type="application/ld+json">`,
+		`<a href="https://jobs.example/c/acme">Acme</a>`,
+		`<a href="mailto:jobs@acme.example">`,
+		`USD 90000–120000 per year`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected job post page to contain %s", want)
+		}
+	}
+	if strings.Contains(html, "<developer>") {
+		t.Error("expected the title to be escaped")
+	}
+
+	buf.Reset()
+	err := renderer.Company(&buf, pages.CompanyPage{Company: company, JobPosts: []models.JobPost{jobPost}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	html = buf.String()
+	for _, want := range []string{
+		`<link rel="canonical" href="https://jobs.example/c/acme">`,
+		`<meta property="og:type" content="profile">`,
+		`<a href="https://jobs.example/j/go-developer-7">Go &lt;developer&gt;</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected company page to contain %s", want)
+		}
+	}
+
+	t.Run("SadPath: long descriptions are cut without markup", func(t *testing.T) {
+		excerpt := pages.Excerpt(jobPost.Description)
+		if !strings.HasPrefix(excerpt, "Build APIs and more") || !strings.HasSuffix(excerpt, "…") {
+			t.Errorf("unexpected excerpt %q", excerpt)
+		}
+		if n := len([]rune(excerpt)); n > 160 {
+			t.Errorf("expected an excerpt of at most 160 characters, but got %d", n)
+		}
+	})
+}
+
+func TestSlugs(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithUser("pepperhere", "randomtesthmacvalue"),
+		models.WithJobPost(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	jobPost := models.JobPost{
+		Title:       "Senior Go Developer",
+		Description: "Build APIs",
+		ApplyAt:     "jobs@example.com",
+		UserID:      1,
+		CategoryID:  1,
+		LocationID:  1,
+	}
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	wantSlug := fmt.Sprintf("senior-go-developer-%d", jobPost.ID)
+	if jobPost.Slug != wantSlug {
+		t.Errorf("expected slug %q, but got %q", wantSlug, jobPost.Slug)
+	}
+	jobPost.Title, jobPost.Slug = "Staff Go Developer", "hijacked"
+	if err := services.JobPost.Update(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	found, err := services.JobPost.BySlug(wantSlug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != jobPost.ID || found.Title != "Staff Go Developer" {
+		t.Errorf("expected the slug to survive the update, but got %+v", found)
+	}
+
+	var slugs []string
+	for i := 0; i < 2; i++ {
+		user := models.User{Email: fmt.Sprintf("acme%d@example.com", i), Password: "megaman007"}
+		if err := services.User.Create(&user); err != nil {
+			t.Fatal(err)
+		}
+		user.CompanyProfile = &models.CompanyProfile{CompanyName: "Acme Inc."}
+		if err := services.User.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+		slugs = append(slugs, user.CompanyProfile.Slug)
+	}
+	if slugs[0] != "acme-inc" || slugs[1] != "acme-inc-2" {
+		t.Errorf("expected unique company slugs, but got %v", slugs)
+	}
+
+	t.Run("SadPath: unknown slug is not found", func(t *testing.T) {
+		wantError := models.ErrNotFound
+		if _, err := services.User.CompanyProfileBySlug("unknown"); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package model_services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestRankJobPosts(t *testing.T) {
+	now := time.Now()
+	lastMonth := now.Add(-45 * 24 * time.Hour)
+	skills := mockSkills(1, 2, 3, 4, 5)
+
+	jobPosts := []models.JobPost{
+		{Title: "Old full match", Skills: skills[:2], PublishedAt: &lastMonth, LocationID: 2},
+		{Title: "Recent partial match", Skills: skills, PublishedAt: &now, LocationID: 1},
+		{Title: "No match", Skills: mockSkills(6), PublishedAt: &now},
+		{Title: "Unpublished", Skills: skills},
+	}
+	candidateSkills := skills[:2]
+
+	got := models.RankJobPosts(jobPosts, candidateSkills, 1, now)
+	if len(got) != 2 {
+		t.Fatalf("expected %d recommendations, but got %d recommendations", 2, len(got))
+	}
+	if got[0].JobPost.Title != "Old full match" {
+		t.Errorf("expected best match to be %q, but got %q", "Old full match", got[0].JobPost.Title)
+	}
+	if got[1].Explanation[0] != "matches 2 of 5 required skills" {
+		t.Errorf("unexpected explanation %q", got[1].Explanation[0])
+	}
+	if len(got[1].Explanation) != 3 {
+		t.Errorf("expected recency and location to be explained, but got %v", got[1].Explanation)
+	}
+	if got[0].Score <= got[1].Score {
+		t.Errorf("expected recommendations to be sorted by score, got %v then %v", got[0].Score, got[1].Score)
+	}
+
+	t.Run("NiceToHaveSkills", func(t *testing.T) {
+		jobPost := models.JobPost{
+			Skills:      skills[:2],
+			PublishedAt: &lastMonth,
+			SkillRequirements: []models.JobPostSkill{
+				{SkillID: 2, Required: false},
+			},
+		}
+		got := models.RankJobPosts([]models.JobPost{jobPost}, skills[1:2], 0, now)
+		if len(got) != 1 {
+			t.Fatalf("expected %d recommendations, but got %d recommendations", 1, len(got))
+		}
+		if want := 0.5 / 1.5; got[0].Score != want {
+			t.Errorf("expected score %v, but got %v", want, got[0].Score)
+		}
+		want := []string{"matches 0 of 1 required skills", "matches 1 of 1 nice-to-have skills"}
+		if len(got[0].Explanation) != len(want) || got[0].Explanation[0] != want[0] || got[0].Explanation[1] != want[1] {
+			t.Errorf("expected explanation %v, but got %v", want, got[0].Explanation)
+		}
+	})
+}
+
+func mockSkills(ids ...uint) []models.Skill {
+	var skills []models.Skill
+	for _, id := range ids {
+		skill := models.Skill{}
+		skill.ID = id
+		skills = append(skills, skill)
+	}
+	return skills
+}
@@ -0,0 +1,181 @@
+package model_services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// stubUserService serves a single user, the methods it does
+// not override panic.
+type stubUserService struct {
+	models.UserService
+	user    models.User
+	updated *models.User
+}
+
+func (s *stubUserService) ByID(id uint) (*models.User, error) {
+	if id != s.user.ID {
+		return nil, models.ErrNotFound
+	}
+	user := s.user
+	profile := *s.user.CompanyProfile
+	user.CompanyProfile = &profile
+	return &user, nil
+}
+
+func (s *stubUserService) Update(user *models.User) error {
+	s.updated = user
+	return nil
+}
+
+func TestUpdateRequests(t *testing.T) {
+	newStub := func() *stubUserService {
+		stub := &stubUserService{user: models.User{
+			Email:  "jane@acme.com",
+			RoleID: 1,
+			CompanyProfile: &models.CompanyProfile{
+				CompanyName: "Acme",
+				Website:     "https://acme.com",
+				FoundedYear: 1999,
+				Version:     1,
+			},
+		}}
+		stub.user.ID = 1
+		return stub
+	}
+	serve := func(stub *stubUserService, fn func(*controllers.Users) controllers.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+		handler := controllers.Handle(fn(controllers.NewUsers(stub, nil, nil)))
+		req := httptest.NewRequest(method, "/user/1", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		req.Header.Set("If-Match", `"v1"`)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	update := func(u *controllers.Users) controllers.HandlerFunc { return u.Update }
+	updateProfile := func(u *controllers.Users) controllers.HandlerFunc { return u.UpdateCompanyProfile }
+
+	t.Run("PATCH only changes the members provided", func(t *testing.T) {
+		stub := newStub()
+		rec := serve(stub, updateProfile, "PATCH", `{"description":"Rockets","foundedYear":null}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, but got %d %s", rec.Code, rec.Body.String())
+		}
+		profile := stub.updated.CompanyProfile
+		if profile.CompanyName != "Acme" || profile.Website != "https://acme.com" {
+			t.Errorf("expected the other members to be kept, but got %+v", profile)
+		}
+		if profile.Description != "Rockets" || profile.FoundedYear != 0 {
+			t.Errorf("expected the description to be set and the year removed, but got %+v", profile)
+		}
+	})
+
+	t.Run("PUT replaces every member", func(t *testing.T) {
+		stub := newStub()
+		rec := serve(stub, updateProfile, "PUT", `{"companyName":"Acme Corp"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, but got %d %s", rec.Code, rec.Body.String())
+		}
+		profile := stub.updated.CompanyProfile
+		if profile.CompanyName != "Acme Corp" || profile.Website != "" || profile.FoundedYear != 0 {
+			t.Errorf("expected the profile to be replaced, but got %+v", profile)
+		}
+	})
+
+	t.Run("responses only show the account", func(t *testing.T) {
+		stub := newStub()
+		rec := serve(stub, update, "PATCH", `{"email":"jane.doe@acme.com"}`)
+		response := map[string]interface{}{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		if response["email"] != "jane.doe@acme.com" || response["roleId"] != nil || response["companyProfile"] != nil {
+			t.Errorf("unexpected response %v", response)
+		}
+		if stub.updated.Password != "" {
+			t.Errorf("expected the password to be left unchanged")
+		}
+	})
+
+	t.Run("SadPath: fields outside of the request are rejected", func(t *testing.T) {
+		for _, body := range []string{`{"roleId":2}`, `{"ID":2,"email":"jane@acme.com"}`, `{"email":"jane@acme.com","CreatedAt":"2020-01-01T00:00:00Z"}`} {
+			for _, method := range []string{"PUT", "PATCH"} {
+				stub := newStub()
+				if rec := serve(stub, update, method, body); rec.Code != http.StatusBadRequest || stub.updated != nil {
+					t.Errorf("%s %s should respond with 400, but got %d", method, body, rec.Code)
+				}
+			}
+		}
+	})
+
+	t.Run("SadPath: merge patches must be objects", func(t *testing.T) {
+		stub := newStub()
+		if rec := serve(stub, update, "PATCH", `["email"]`); rec.Code != http.StatusBadRequest {
+			t.Errorf("should respond with 400, but got %d", rec.Code)
+		}
+	})
+}
+
+func TestPreconditions(t *testing.T) {
+	stub := &stubUserService{user: models.User{
+		CompanyProfile: &models.CompanyProfile{CompanyName: "Acme", Version: 3},
+	}}
+	stub.user.ID = 1
+	users := controllers.NewUsers(stub, nil, nil)
+	serve := func(fn controllers.HandlerFunc, method string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/user/1/company-profile", strings.NewReader(`{"description":"Rockets"}`))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		controllers.Handle(fn)(rec, req)
+		return rec
+	}
+
+	t.Run("reads are tagged with the version", func(t *testing.T) {
+		rec := serve(users.CompanyProfile, "GET", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"v3"` {
+			t.Errorf("expected status 200 with ETag \"v3\", but got %d %q", rec.Code, rec.Header().Get("ETag"))
+		}
+		rec = serve(users.CompanyProfile, "GET", map[string]string{"If-None-Match": `"v3"`})
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("expected status 304, but got %d", rec.Code)
+		}
+		rec = serve(users.CompanyProfile, "GET", map[string]string{"If-None-Match": `"v2"`})
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected stale copies to get status 200, but got %d", rec.Code)
+		}
+	})
+
+	t.Run("updates of the current version succeed", func(t *testing.T) {
+		for _, match := range []string{`"v3"`, `"v2", "v3"`, "*"} {
+			stub.updated = nil
+			rec := serve(users.UpdateCompanyProfile, "PATCH", map[string]string{"If-Match": match})
+			if rec.Code != http.StatusOK || stub.updated == nil {
+				t.Errorf("If-Match %s should respond with 200, but got %d", match, rec.Code)
+			}
+		}
+	})
+
+	t.Run("SadPath: updates without If-Match are rejected", func(t *testing.T) {
+		stub.updated = nil
+		rec := serve(users.UpdateCompanyProfile, "PUT", nil)
+		if rec.Code != http.StatusPreconditionRequired || stub.updated != nil {
+			t.Errorf("expected status 428, but got %d", rec.Code)
+		}
+	})
+
+	t.Run("SadPath: updates of another version are rejected", func(t *testing.T) {
+		stub.updated = nil
+		rec := serve(users.UpdateCompanyProfile, "PATCH", map[string]string{"If-Match": `"v2"`})
+		if rec.Code != http.StatusPreconditionFailed || stub.updated != nil {
+			t.Errorf("expected status 412, but got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package model_services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/retention"
+)
+
+// purgeRecorder records the cutoff of PurgeDeleted for both job
+// posts and users, and the calls of EraseScheduled.
+type purgeRecorder struct {
+	before []time.Time
+	erased []time.Time
+	err    error
+}
+
+func (p *purgeRecorder) PurgeDeleted(before time.Time) (int, error) {
+	p.before = append(p.before, before)
+	return 0, p.err
+}
+
+type purgeJobPostService struct {
+	models.JobPostService
+	rec *purgeRecorder
+}
+
+func (s purgeJobPostService) PurgeDeleted(before time.Time) (int, error) {
+	return s.rec.PurgeDeleted(before)
+}
+
+type purgeUserService struct {
+	models.UserService
+	rec *purgeRecorder
+}
+
+func (s purgeUserService) PurgeDeleted(before time.Time) (int, error) {
+	return s.rec.PurgeDeleted(before)
+}
+
+func (s purgeUserService) EraseScheduled(now time.Time) (int, error) {
+	s.rec.erased = append(s.rec.erased, now)
+	return 0, nil
+}
+
+func TestPurger(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	t.Run("records deleted before the retention period are purged", func(t *testing.T) {
+		jobPosts, users := &purgeRecorder{}, &purgeRecorder{}
+		purger := retention.NewPurger(purgeJobPostService{rec: jobPosts}, purgeUserService{rec: users}, 30*24*time.Hour)
+		if err := purger.PurgeExpired(now); err != nil {
+			t.Fatal(err)
+		}
+		want := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+		if len(jobPosts.before) != 1 || !jobPosts.before[0].Equal(want) {
+			t.Errorf("expected job posts deleted before %s to be purged, but got %v", want, jobPosts.before)
+		}
+		if len(users.before) != 1 || !users.before[0].Equal(want) {
+			t.Errorf("expected users deleted before %s to be purged, but got %v", want, users.before)
+		}
+		if len(users.erased) != 1 || !users.erased[0].Equal(now) {
+			t.Errorf("expected the accounts scheduled for deletion to be erased, but got %v", users.erased)
+		}
+	})
+
+	t.Run("scheduled accounts are erased without a retention period", func(t *testing.T) {
+		jobPosts, users := &purgeRecorder{}, &purgeRecorder{}
+		purger := retention.NewPurger(purgeJobPostService{rec: jobPosts}, purgeUserService{rec: users}, 0)
+		if err := purger.PurgeExpired(now); err != nil {
+			t.Fatal(err)
+		}
+		if len(users.erased) != 1 {
+			t.Errorf("expected the accounts scheduled for deletion to be erased")
+		}
+		if len(jobPosts.before) != 0 || len(users.before) != 0 {
+			t.Errorf("expected nothing to be purged")
+		}
+	})
+
+	t.Run("SadPath: errors stop the purge", func(t *testing.T) {
+		jobPosts, users := &purgeRecorder{err: errors.New("pq: connection refused")}, &purgeRecorder{}
+		purger := retention.NewPurger(purgeJobPostService{rec: jobPosts}, purgeUserService{rec: users}, time.Hour)
+		if err := purger.PurgeExpired(now); err == nil {
+			t.Errorf("expected the error to be returned")
+		}
+		if len(users.before) != 0 {
+			t.Errorf("expected users not to be purged after an error")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package model_services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestDiffJobPostSnapshots(t *testing.T) {
+	published := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+	prev := models.JobPostSnapshot{
+		Title:       "Go Developer",
+		LocationID:  1,
+		CategoryID:  1,
+		Description: "Build APIs",
+		ApplyAt:     "jobs@acme.com",
+		PublishedAt: &published,
+		SkillIDs:    []uint{1, 2},
+	}
+
+	t.Run("only the fields changed are listed", func(t *testing.T) {
+		next := prev
+		next.Title = "Senior Go Developer"
+		next.SalaryMin = 90000
+		samePublished := published.In(time.FixedZone("CET", 3600))
+		next.PublishedAt = &samePublished
+		next.SkillIDs = []uint{2, 3}
+
+		changes, added, removed := models.DiffJobPostSnapshots(&prev, next)
+		if len(changes) != 2 {
+			t.Fatalf("expected 2 changes, but got %+v", changes)
+		}
+		if c := changes[0]; c.Field != "title" || c.From != "Go Developer" || c.To != "Senior Go Developer" {
+			t.Errorf("unexpected change %+v", c)
+		}
+		if c := changes[1]; c.Field != "salaryMin" || c.From != uint(0) || c.To != uint(90000) {
+			t.Errorf("unexpected change %+v", c)
+		}
+		if len(added) != 1 || added[0] != 3 || len(removed) != 1 || removed[0] != 1 {
+			t.Errorf("expected skill 3 added and 1 removed, but got %v and %v", added, removed)
+		}
+	})
+
+	t.Run("the first revision lists every field set", func(t *testing.T) {
+		changes, added, removed := models.DiffJobPostSnapshots(nil, prev)
+		if len(changes) != 6 || len(added) != 2 || len(removed) != 0 {
+			t.Errorf("expected 6 changes and 2 skills added, but got %+v, %v and %v", changes, added, removed)
+		}
+	})
+
+	t.Run("requirement changes are listed", func(t *testing.T) {
+		withRequirements := prev
+		withRequirements.SkillRequirements = []models.JobPostSkill{
+			{SkillID: 1, Required: true},
+			{SkillID: 2, Required: true, MinYears: 2},
+		}
+		next := withRequirements
+		next.SkillRequirements = []models.JobPostSkill{
+			{SkillID: 1, Required: true},
+			{SkillID: 2, Required: false, MinProficiency: models.ProficiencyAdvanced, MinYears: 2},
+		}
+		changes, added, removed := models.DiffJobPostSnapshots(&withRequirements, next)
+		if len(changes) != 1 || len(added) != 0 || len(removed) != 0 {
+			t.Fatalf("expected a single change, but got %+v, %v and %v", changes, added, removed)
+		}
+		if c := changes[0]; c.Field != "skillRequirements" || c.To != next.SkillRequirements[1] {
+			t.Errorf("unexpected change %+v", c)
+		}
+
+		// Snapshots without requirements had the default ones
+		changes, _, _ = models.DiffJobPostSnapshots(&prev, next)
+		if len(changes) != 1 || changes[0].From != (models.JobPostSkill{SkillID: 2, Required: true}) {
+			t.Errorf("expected the default requirement to be changed, but got %+v", changes)
+		}
+	})
+
+	t.Run("restoring applies the fields", func(t *testing.T) {
+		jobPost := models.JobPost{UserID: 4, Title: "Draft"}
+		prev.Apply(&jobPost)
+		if jobPost.Title != prev.Title || jobPost.UserID != 4 || jobPost.PublishedAt != prev.PublishedAt {
+			t.Errorf("unexpected job post %+v", jobPost)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package model_services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestJobPostFilter(t *testing.T) {
+	values, _ := url.ParseQuery("q=golang&l=1&c=2&sk=1,2&rsk=3&page=4")
+	filters := models.ParseJobPostFilter(values)
+
+	if got, want := models.EncodeJobPostFilter(filters).Encode(), "c=2&l=1&q=golang&rsk=3&sk=1%2C2"; got != want {
+		t.Errorf("expected encoded filter %q, but got %q", want, got)
+	}
+
+	jobPost := models.JobPost{
+		Title:      "Senior Golang Dev",
+		LocationID: 1,
+		CategoryID: 2,
+		Skills:     mockSkills(2, 3),
+	}
+	if !models.MatchesJobPostFilter(filters, &jobPost) {
+		t.Errorf("expected job post %v to match filter %v", jobPost, filters)
+	}
+
+	jobPost.SkillRequirements = []models.JobPostSkill{{SkillID: 3, Required: false}}
+	if models.MatchesJobPostFilter(filters, &jobPost) {
+		t.Errorf("expected job post with nice-to-have skill not to match required skill filter")
+	}
+}
+
+func TestSavedSearchService(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithSavedSearch("randomtesthmacvalue"),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	sss := services.SavedSearch
+	search := models.SavedSearch{
+		UserID: 1,
+		Query:  "?q=golang&page=2",
+	}
+	if err := sss.Create(&search); err != nil {
+		t.Fatal(err)
+	}
+	if search.Query != "q=golang" || search.Frequency != models.AlertDaily || search.Name != "golang" {
+		t.Errorf("expected saved search defaults to be set, but got %v", search)
+	}
+
+	t.Run("SadPath: invalid frequency is not allowed", func(t *testing.T) {
+		wantError := models.ErrFrequencyInvalid
+		if err := sss.Create(&models.SavedSearch{UserID: 1, Frequency: "hourly"}); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SentAlerts", func(t *testing.T) {
+		jobPosts := make([]models.JobPost, 2)
+		jobPosts[0].ID, jobPosts[1].ID = 1, 2
+		if err := sss.MarkSent(&search, jobPosts[:1], time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		if err := sss.MarkSent(&search, jobPosts[:1], time.Now()); err != nil {
+			t.Errorf("expected a job post sent twice not to fail, but got %v", err)
+		}
+		unsent, err := sss.Unsent(&search, jobPosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(unsent) != 1 || unsent[0].ID != 2 {
+			t.Errorf("expected only the second job post to be unsent, but got %v", unsent)
+		}
+		if search.LastNotifiedAt == nil {
+			t.Errorf("expected the saved search to be notified")
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		if err := sss.Unsubscribe(search.ID, "forged-token"); err != models.ErrUnsubscribeTokenInvalid {
+			t.Errorf("should return %q error got %q error", models.ErrUnsubscribeTokenInvalid, err)
+		}
+		if err := sss.Unsubscribe(search.ID, sss.UnsubscribeToken(&search)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := sss.ByID(search.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Frequency != models.AlertNone {
+			t.Errorf("expected frequency to be %q, but got %q", models.AlertNone, got.Frequency)
+		}
+	})
+}
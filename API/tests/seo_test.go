@@ -0,0 +1,223 @@
+package model_services_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/seo"
+)
+
+// listedJobPosts implements the part of JobPostService used by
+// the sitemaps.
+type listedJobPosts struct {
+	models.JobPostService
+	jobPosts []models.JobPost
+	calls    int
+}
+
+func (l *listedJobPosts) Listed(now time.Time) ([]models.JobPost, error) {
+	l.calls++
+	return l.jobPosts, nil
+}
+
+type companyProfiles struct {
+	models.UserService
+	profiles []models.CompanyProfile
+}
+
+func (c *companyProfiles) CompanyProfiles() ([]models.CompanyProfile, error) {
+	return c.profiles, nil
+}
+
+func TestJobPostingJSONLD(t *testing.T) {
+	published := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	validThrough := published.Add(30 * 24 * time.Hour)
+	jobPost := models.JobPost{
+		Title:          "Go developer",
+		Description:    "Build APIs",
+		PublishedAt:    &published,
+		ValidThrough:   &validThrough,
+		Location:       &models.Location{LocationName: "Remote"},
+		Category:       &models.Category{CategoryName: "Web Development"},
+		SalaryMin:      90000,
+		SalaryMax:      120000,
+		SalaryCurrency: "USD",
+		SalaryPeriod:   models.SalaryYear,
+	}
+	jobPost.ID, jobPost.Slug = 7, "go-developer-7"
+	company := &models.CompanyProfile{UserID: 3, CompanyName: "Acme", Slug: "acme", Website: "https://acme.example"}
+
+	body, err := seo.NewJobPosting(jobPost, company, seo.NewURLs("https://jobs.example/")).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{
+		"@context":        "https://schema.org",
+		"@type":           "JobPosting",
+		"datePosted":      "2026-10-01T12:00:00Z",
+		"validThrough":    "2026-10-31T12:00:00Z",
+		"url":             "https://jobs.example/j/go-developer-7",
+		"jobLocationType": "TELECOMMUTE",
+		"industry":        "Web Development",
+	} {
+		if got[key] != want {
+			t.Errorf("expected %s to be %q, but got %v", key, want, got[key])
+		}
+	}
+	org := got["hiringOrganization"].(map[string]interface{})
+	if org["name"] != "Acme" || org["sameAs"] != "https://acme.example" || org["url"] != "https://jobs.example/c/acme" {
+		t.Errorf("unexpected hiringOrganization %v", org)
+	}
+	value := got["baseSalary"].(map[string]interface{})["value"].(map[string]interface{})
+	if value["minValue"] != 90000.0 || value["maxValue"] != 120000.0 || value["unitText"] != "YEAR" {
+		t.Errorf("unexpected baseSalary value %v", value)
+	}
+
+	t.Run("SadPath: unknown salary and location are left out", func(t *testing.T) {
+		jobPost := models.JobPost{Title: "QA", PublishedAt: &published, Location: &models.Location{LocationName: "Canada"}}
+		posting := seo.NewJobPosting(jobPost, nil, seo.NewURLs("https://jobs.example"))
+		if posting.BaseSalary != nil || posting.JobLocationType != "" {
+			t.Errorf("expected no salary and an on-site job, but got %+v", posting)
+		}
+		if posting.JobLocation == nil || posting.JobLocation.Address.AddressCountry != "Canada" {
+			t.Errorf("expected job location in Canada, but got %+v", posting.JobLocation)
+		}
+	})
+}
+
+func TestSitemaps(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	js := &listedJobPosts{}
+	for i := 1; i <= seo.PageSize+1; i++ {
+		jp := models.JobPost{}
+		jp.ID, jp.UpdatedAt = uint(i), now.Add(-time.Duration(i)*time.Minute)
+		jp.Slug = fmt.Sprintf("job-%d", i)
+		js.jobPosts = append(js.jobPosts, jp)
+	}
+	profile := models.CompanyProfile{UserID: 3, Slug: "acme"}
+	profile.UpdatedAt = now
+	// Unnamed companies have no page
+	unnamed := models.CompanyProfile{UserID: 4}
+	us := &companyProfiles{profiles: []models.CompanyProfile{profile, unnamed}}
+	sitemaps := seo.NewSitemaps(js, us, seo.NewURLs("https://jobs.example"))
+
+	index, err := sitemaps.Index(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotIndex struct {
+		Locs []string `xml:"sitemap>loc"`
+	}
+	if err := xml.Unmarshal(index.Body, &gotIndex); err != nil {
+		t.Fatal(err)
+	}
+	wantLocs := []string{
+		"https://jobs.example/sitemaps/jobs-1.xml",
+		"https://jobs.example/sitemaps/jobs-2.xml",
+		"https://jobs.example/sitemaps/companies-1.xml",
+	}
+	if len(gotIndex.Locs) != len(wantLocs) {
+		t.Fatalf("expected sitemaps %v, but got %v", wantLocs, gotIndex.Locs)
+	}
+	for i, want := range wantLocs {
+		if gotIndex.Locs[i] != want {
+			t.Errorf("expected sitemap %d to be %q, but got %q", i, want, gotIndex.Locs[i])
+		}
+	}
+
+	page, err := sitemaps.Page("jobs-2", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotPage struct {
+		Locs []string `xml:"url>loc"`
+	}
+	if err := xml.Unmarshal(page.Body, &gotPage); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotPage.Locs) != 1 || gotPage.Locs[0] != "https://jobs.example/j/job-10001" {
+		t.Errorf("expected the last job post on the second page, but got %v", gotPage.Locs)
+	}
+
+	if js.calls != 1 {
+		t.Errorf("expected the sitemaps to be built once, but got %d builds", js.calls)
+	}
+	if err := sitemaps.HandleEvent(models.DomainEvent{Type: models.EventJobPostClosed}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sitemaps.Index(now); err != nil {
+		t.Fatal(err)
+	}
+	if js.calls != 2 {
+		t.Errorf("expected the sitemaps to be rebuilt after an event, but got %d builds", js.calls)
+	}
+
+	t.Run("SadPath: unknown page is not found", func(t *testing.T) {
+		wantError := seo.ErrSitemapNotFound
+		if _, err := sitemaps.Page("jobs-3", now); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+func TestJobPostSalary(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithJobPost(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	newJobPost := func() models.JobPost {
+		return models.JobPost{
+			Title:       "Go developer",
+			Description: "Build APIs",
+			ApplyAt:     "jobs@example.com",
+			UserID:      1,
+			CategoryID:  1,
+			LocationID:  1,
+		}
+	}
+	jobPost := newJobPost()
+	jobPost.SalaryMin, jobPost.SalaryCurrency, jobPost.SalaryPeriod = 50, "eur", models.SalaryHour
+	if err := services.JobPost.Create(&jobPost); err != nil {
+		t.Fatal(err)
+	}
+	if jobPost.SalaryCurrency != "EUR" {
+		t.Errorf("expected currency EUR, but got %q", jobPost.SalaryCurrency)
+	}
+
+	t.Run("SadPath: reversed salary range is not allowed", func(t *testing.T) {
+		wantError := models.ErrSalaryInvalid
+		jobPost := newJobPost()
+		jobPost.SalaryMin, jobPost.SalaryMax = 100, 50
+		jobPost.SalaryCurrency, jobPost.SalaryPeriod = "USD", models.SalaryYear
+		if err := services.JobPost.Create(&jobPost); !isError(err, wantError) {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: validThrough before publication is not allowed", func(t *testing.T) {
+		wantError := models.ErrValidThroughInvalid
+		jobPost := newJobPost()
+		past := time.Now().Add(-time.Hour)
+		jobPost.ValidThrough = &past
+		if err := services.JobPost.Create(&jobPost); !isError(err, wantError) {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
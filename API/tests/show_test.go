@@ -0,0 +1,136 @@
+package model_services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	llctx "github.com/samueldaviddelacruz/go-job-board/API/context"
+	"github.com/samueldaviddelacruz/go-job-board/API/controllers"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func (s *stubUserService) ByIDWith(id uint, include ...string) (*models.User, error) {
+	return s.ByID(id)
+}
+
+// stubJobPostService serves a single job post, recording the
+// associations asked for.
+type stubJobPostService struct {
+	models.JobPostService
+	jobPost models.JobPost
+	include []string
+}
+
+func (s *stubJobPostService) ByIDWith(id uint, include ...string) (*models.JobPost, error) {
+	if id != s.jobPost.ID {
+		return nil, models.ErrNotFound
+	}
+	s.include = include
+	jobPost := s.jobPost
+	return &jobPost, nil
+}
+
+func TestShow(t *testing.T) {
+	serve := func(fn controllers.HandlerFunc, target string, caller *models.User, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		if caller != nil {
+			req = req.WithContext(llctx.WithUser(req.Context(), caller))
+		}
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		controllers.Handle(fn)(rec, req)
+		return rec
+	}
+
+	t.Run("job posts", func(t *testing.T) {
+		published := time.Now().Add(-time.Hour)
+		company := &models.CompanyProfile{CompanyName: "Acme", Version: 3}
+		company.ID = 5
+		stub := &stubJobPostService{jobPost: models.JobPost{
+			UserID:      2,
+			Title:       "Go Developer",
+			PublishedAt: &published,
+			Version:     2,
+			Company:     company,
+		}}
+		stub.jobPost.ID = 1
+		jobs := controllers.NewJobs(stub, nil, nil, nil, nil)
+
+		rec := serve(jobs.Show, "/jobs/1?include=skillRequirements,%20company.benefits", nil, nil)
+		withIncludes := `"v2-c5.3+company.benefits+skillRequirements"`
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != withIncludes {
+			t.Fatalf("expected status 200 with ETag %s, but got %d %q", withIncludes, rec.Code, rec.Header().Get("ETag"))
+		}
+		if strings.Join(stub.include, ",") != "skillRequirements,company.benefits" {
+			t.Errorf("expected the associations to be included, but got %q", stub.include)
+		}
+		got := models.JobPost{}
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if got.Title != "Go Developer" || got.Company == nil || got.Company.CompanyName != "Acme" {
+			t.Errorf("unexpected job post %+v", got)
+		}
+
+		rec = serve(jobs.Show, "/jobs/1?include=company.benefits,skillRequirements", nil, map[string]string{"If-None-Match": withIncludes})
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304 whatever the order of include, but got %d", rec.Code)
+		}
+		rec = serve(jobs.Show, "/jobs/1", nil, map[string]string{"If-None-Match": withIncludes})
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"v2-c5.3"` {
+			t.Errorf("expected another representation to have another ETag, but got %d %q", rec.Code, rec.Header().Get("ETag"))
+		}
+		company.Version++
+		rec = serve(jobs.Show, "/jobs/1", nil, map[string]string{"If-None-Match": `"v2-c5.3"`})
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected a company change to change the ETag, but got %d", rec.Code)
+		}
+
+		stub.jobPost.PublishedAt = nil
+		if rec := serve(jobs.Show, "/jobs/1", nil, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("expected drafts to be hidden, but got %d", rec.Code)
+		}
+		author := &models.User{}
+		author.ID = 2
+		if rec := serve(jobs.Show, "/jobs/1", author, nil); rec.Code != http.StatusOK {
+			t.Errorf("expected drafts to be shown to their author, but got %d", rec.Code)
+		}
+	})
+
+	t.Run("users", func(t *testing.T) {
+		stub := &stubUserService{user: models.User{
+			Email:          "jane@acme.com",
+			RoleID:         1,
+			CompanyProfile: &models.CompanyProfile{CompanyName: "Acme"},
+		}}
+		stub.user.ID = 1
+		users := controllers.NewUsers(stub, nil, nil)
+
+		rec := serve(users.Show, "/users/1", &stub.user, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, but got %d %s", rec.Code, rec.Body.String())
+		}
+		response := map[string]interface{}{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		if response["email"] != "jane@acme.com" || response["roleId"] != nil || response["companyProfile"] == nil {
+			t.Errorf("unexpected response %v", response)
+		}
+		if skills, ok := response["skills"].([]interface{}); !ok || len(skills) != 0 {
+			t.Errorf("expected an empty list of skills, but got %v", response["skills"])
+		}
+
+		other := &models.User{}
+		other.ID = 2
+		for _, caller := range []*models.User{nil, other} {
+			if rec := serve(users.Show, "/users/1", caller, nil); rec.Code != http.StatusNotFound {
+				t.Errorf("expected other users to get status 404, but got %d", rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package model_services_test
+
+import (
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+func TestNormalizeSkillName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Golang", want: "golang"},
+		{name: " Go lang ", want: "golang"},
+		{name: "Node.js", want: "nodejs"},
+		{name: "C++", want: "c++"},
+		{name: "C#", want: "c#"},
+		{name: "...", want: ""},
+	}
+	for _, tt := range tests {
+		if got := models.NormalizeSkillName(tt.name); got != tt.want {
+			t.Errorf("NormalizeSkillName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSkillsService(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithSkill(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	t.Run("ByName", testSkillsService_ByName(services.Skill))
+	t.Run("Create", testSkillsService_Create(services.Skill))
+	t.Run("Autocomplete", testSkillsService_Autocomplete(services.Skill))
+}
+
+func testSkillsService_ByName(ss models.SkillsService) func(t *testing.T) {
+	return func(t *testing.T) {
+		for _, name := range []string{"Golang", "golang", "Go", "Go lang"} {
+			got, err := ss.ByName(name)
+			if err != nil {
+				t.Fatalf("skill %q could not be resolved error: %s", name, err.Error())
+			}
+			if got.SkillName != "Golang" {
+				t.Errorf("expected %q to resolve to %q, but got %q", name, "Golang", got.SkillName)
+			}
+		}
+		if _, err := ss.ByName("Cobol"); err != models.ErrNotFound {
+			t.Errorf("should return %q error got %q error", models.ErrNotFound, err)
+		}
+	}
+}
+
+func testSkillsService_Create(ss models.SkillsService) func(t *testing.T) {
+	return func(t *testing.T) {
+		skill := models.Skill{
+			SkillName: "Rust",
+			Category:  "Language",
+			Aliases:   []models.SkillAlias{{Alias: "rust lang"}},
+		}
+		if err := ss.Create(&skill); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("SadPath: alias of an existing skill is not allowed", func(t *testing.T) {
+			wantError := models.ErrSkillNameTaken
+			if err := ss.Create(&models.Skill{SkillName: "go-lang"}); err != wantError {
+				t.Errorf("should return %q error got %q error", wantError, err)
+			}
+		})
+	}
+}
+
+func testSkillsService_Autocomplete(ss models.SkillsService) func(t *testing.T) {
+	return func(t *testing.T) {
+		got, err := ss.Autocomplete("ru", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].SkillName != "Rust" {
+			t.Fatalf("expected autocomplete to return the created skill, but got %v", got)
+		}
+
+		got, err = ss.Autocomplete("k8", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].SkillName != "Kubernetes" {
+			t.Errorf("expected alias prefix to match %q, but got %v", "Kubernetes", got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package model_services_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/syndication"
+)
+
+func mockFeedJobPosts(now time.Time) []models.JobPost {
+	older := now.Add(-48 * time.Hour)
+	jobPosts := []models.JobPost{
+		{Title: "Older", Description: "<p>Old</p>", PublishedAt: &older, Category: &models.Category{CategoryName: "QA"}},
+		{Title: "Newer", Description: "New & shiny", PublishedAt: &now, Skills: mockSkills(1)},
+	}
+	jobPosts[0].ID, jobPosts[0].UpdatedAt = 1, older
+	jobPosts[1].ID, jobPosts[1].UpdatedAt = 2, now
+	// FindAll returns a post once per matching skill
+	return append(jobPosts, jobPosts[1])
+}
+
+func TestSyndicationFeed(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	feed := syndication.NewFeed("Jobs", "https://jobs.example/", "https://jobs.example/jobs.rss", mockFeedJobPosts(now))
+
+	if len(feed.JobPosts) != 2 || feed.JobPosts[0].Title != "Newer" {
+		t.Fatalf("expected 2 job posts, newest first, but got %+v", feed.JobPosts)
+	}
+	if !feed.Updated().Equal(now) {
+		t.Errorf("expected feed updated at %v, but got %v", now, feed.Updated())
+	}
+	if guid := feed.GUID(feed.JobPosts[0]); guid != "tag:jobs.example,2019:job-post:2" {
+		t.Errorf("unexpected GUID %q", guid)
+	}
+
+	body, err := feed.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rss struct {
+		Items []struct {
+			Title string `xml:"title"`
+			GUID  string `xml:"guid"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(body, &rss); err != nil {
+		t.Fatal(err)
+	}
+	if len(rss.Items) != 2 || rss.Items[0].GUID != feed.GUID(feed.JobPosts[0]) {
+		t.Errorf("unexpected RSS items %+v", rss.Items)
+	}
+
+	body, err = feed.Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var atom struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			Updated string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &atom); err != nil {
+		t.Fatal(err)
+	}
+	if atom.Updated != "2026-10-01T12:00:00Z" || len(atom.Entries) != 2 || atom.Entries[1].Updated != "2026-09-29T12:00:00Z" {
+		t.Errorf("unexpected Atom timestamps %+v", atom)
+	}
+
+	body, err = feed.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonFeed struct {
+		Version string `json:"version"`
+		Items   []struct {
+			ContentHTML string   `json:"content_html"`
+			Tags        []string `json:"tags"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &jsonFeed); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(jsonFeed.Version, "jsonfeed.org") || jsonFeed.Items[1].Tags[0] != "QA" {
+		t.Errorf("unexpected JSON feed %+v", jsonFeed)
+	}
+
+	t.Run("ETag changes when a job post is updated", func(t *testing.T) {
+		etag := feed.ETag(syndication.FormatRSS)
+		if etag == feed.ETag(syndication.FormatAtom) {
+			t.Error("expected formats to have different ETags")
+		}
+		feed.JobPosts[1].UpdatedAt = now.Add(time.Minute)
+		if etag == feed.ETag(syndication.FormatRSS) {
+			t.Error("expected ETag to change")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package model_services_test
+
+import (
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+// isError reports whether err is want, or a validation error
+// containing want.
+func isError(err, want error) bool {
+	if verr, ok := err.(*models.ValidationError); ok {
+		return verr.Contains(want)
+	}
+	return err == want
+}
+
+func TestValidationError(t *testing.T) {
+	jobPostService := models.NewJobPostService(nil)
+
+	t.Run("every field error is reported", func(t *testing.T) {
+		err := jobPostService.Validate(&models.JobPost{UserID: 1, SalaryCurrency: "usd"})
+		verr, ok := err.(*models.ValidationError)
+		if !ok {
+			t.Fatalf("expected a validation error, but got %v", err)
+		}
+		want := []models.FieldError{
+			{Field: "title", Code: models.CodeRequired, Message: models.ErrTitleRequired.Public()},
+			{Field: "locationId", Code: models.CodeRequired, Message: models.ErrLocationIDRequired.Public()},
+			{Field: "categoryId", Code: models.CodeRequired, Message: models.ErrCategoryIDRequired.Public()},
+			{Field: "description", Code: models.CodeRequired, Message: models.ErrDescriptionRequired.Public()},
+			{Field: "applyAt", Code: models.CodeRequired, Message: models.ErrApplyAtRequired.Public()},
+			{Field: "salary", Code: models.CodeInvalid, Message: models.ErrSalaryInvalid.Public()},
+		}
+		got := verr.Fields()
+		if len(got) != len(want) {
+			t.Fatalf("expected %d field errors, but got %+v", len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("expected field error %+v, but got %+v", want[i], got[i])
+			}
+		}
+		if verr.Conflict() {
+			t.Errorf("expected invalid fields not to be a conflict")
+		}
+	})
+
+	t.Run("SadPath: non field errors are returned first", func(t *testing.T) {
+		wantError := models.ErrUserIDRequired
+		if err := jobPostService.Validate(&models.JobPost{}); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+func TestUserValidation(t *testing.T) {
+	userService := models.NewUserService(nil, "pepper", "hmac-key")
+
+	t.Run("SadPath: validation stops at the first error", func(t *testing.T) {
+		user := models.User{Email: "not an email", Password: "short"}
+		err := userService.Create(&user)
+		verr, ok := err.(*models.ValidationError)
+		if !ok {
+			t.Fatalf("expected a validation error, but got %v", err)
+		}
+		if len(verr.Errors) != 1 || !verr.Contains(models.ErrPasswordTooShort) {
+			t.Errorf("expected only %q, but got %v", models.ErrPasswordTooShort, verr.Errors)
+		}
+		if user.PasswordHash != "" {
+			t.Errorf("expected the rejected password not to be hashed")
+		}
+	})
+}
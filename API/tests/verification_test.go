@@ -0,0 +1,139 @@
+package model_services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/verification"
+)
+
+type txtRecords map[string][]string
+
+func (tr txtRecords) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return tr[name], nil
+}
+
+func TestDomain(t *testing.T) {
+	cases := map[string]string{
+		"acme.com":                       "acme.com",
+		"https://www.Acme.com/careers":   "acme.com",
+		"http://jobs.acme.co.uk:8080/":   "jobs.acme.co.uk",
+		" https://acme.com. ":            "acme.com",
+		"https://xn--bcher-kva.example/": "xn--bcher-kva.example",
+	}
+	for website, want := range cases {
+		got, err := verification.Domain(website)
+		if err != nil || got != want {
+			t.Errorf("expected the domain of %q to be %q, but got %q %v", website, want, got, err)
+		}
+	}
+
+	t.Run("SadPath: websites without a domain name are not allowed", func(t *testing.T) {
+		wantError := verification.ErrWebsiteInvalid
+		for _, website := range []string{"", "localhost", "http://127.0.0.1/", "https://[::1]/", "acme"} {
+			if _, err := verification.Domain(website); err != wantError {
+				t.Errorf("%q should return %q error got %q error", website, wantError, err)
+			}
+		}
+	})
+}
+
+func TestVerifier(t *testing.T) {
+	profile := models.CompanyProfile{Website: "https://www.acme.com"}
+	profile.ID = 1
+	records := txtRecords{}
+	files := map[string]string{}
+	fetcher := verification.FetcherFunc(func(url string) (io.ReadCloser, error) {
+		content, ok := files[url]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	})
+	verifier := verification.NewVerifier("verification-test-secret", records, fetcher)
+
+	challenge, err := verifier.Challenge(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.Domain != "acme.com" || challenge.FileURL != "https://acme.com/.well-known/jobboard-verification.txt" {
+		t.Errorf("unexpected challenge %+v", challenge)
+	}
+	other := profile
+	other.ID = 2
+	if otherChallenge, _ := verifier.Challenge(other); otherChallenge.Token == challenge.Token {
+		t.Errorf("expected every profile to get its own token")
+	}
+
+	t.Run("DNS", func(t *testing.T) {
+		records["acme.com"] = []string{"v=spf1 -all", challenge.TXTRecord}
+		defer delete(records, "acme.com")
+		domain, err := verifier.CheckDNS(profile)
+		if err != nil || domain != "acme.com" {
+			t.Errorf("expected acme.com to be verified, but got %q %v", domain, err)
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		files[challenge.FileURL] = challenge.Token + "\n"
+		defer delete(files, challenge.FileURL)
+		domain, err := verifier.CheckFile(profile)
+		if err != nil || domain != "acme.com" {
+			t.Errorf("expected acme.com to be verified, but got %q %v", domain, err)
+		}
+	})
+
+	t.Run("Email", func(t *testing.T) {
+		now := time.Now()
+		code, err := verifier.EmailCode(profile, "Jane@HR.acme.com", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		domain, err := verifier.CheckEmailCode(profile, "jane@hr.acme.com", code, now.Add(time.Hour))
+		if err != nil || domain != "acme.com" {
+			t.Errorf("expected acme.com to be verified, but got %q %v", domain, err)
+		}
+
+		wantError := verification.ErrEmailCodeInvalid
+		if _, err := verifier.CheckEmailCode(profile, "jane@hr.acme.com", code, now.Add(25*time.Hour)); err != wantError {
+			t.Errorf("expired code should return %q error got %q error", wantError, err)
+		}
+		if _, err := verifier.CheckEmailCode(profile, "john@acme.com", code, now); err != wantError {
+			t.Errorf("code of another address should return %q error got %q error", wantError, err)
+		}
+		if _, err := verifier.CheckEmailCode(profile, "jane@hr.acme.com", "4102444800."+strings.SplitN(code, ".", 2)[1], now); err != wantError {
+			t.Errorf("extended code should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: missing record is rejected", func(t *testing.T) {
+		wantError := verification.ErrChallengeFailed
+		records["acme.com"] = []string{verification.TXTPrefix + "someone-else"}
+		defer delete(records, "acme.com")
+		if _, err := verifier.CheckDNS(profile); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+		if _, err := verifier.CheckFile(profile); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+
+	t.Run("SadPath: email outside of the domain is rejected", func(t *testing.T) {
+		wantError := verification.ErrEmailDomain
+		for _, email := range []string{"jane@acme.com.evil.io", "jane@notacme.com", "jane"} {
+			if _, err := verifier.EmailCode(profile, email, time.Now()); err != wantError {
+				t.Errorf("%q should return %q error got %q error", email, wantError, err)
+			}
+		}
+		webmail := models.CompanyProfile{Website: "https://gmail.com"}
+		if _, err := verifier.EmailCode(webmail, "jane@gmail.com", time.Now()); err != wantError {
+			t.Errorf("public provider should return %q error got %q error", wantError, err)
+		}
+	})
+}
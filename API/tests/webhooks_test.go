@@ -0,0 +1,210 @@
+package model_services_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+	"github.com/samueldaviddelacruz/go-job-board/API/webhooks"
+)
+
+const testWebhookSecret = "webhook-test-secret"
+
+// newWebhookReceiver starts a receiver verifying the signature
+// of every payload and answering with status.
+func newWebhookReceiver(t *testing.T, status int, received chan<- []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhooks.TimestampHeader), 10, 64)
+		if err != nil {
+			t.Error(err)
+		}
+		signature := strings.TrimPrefix(r.Header.Get(webhooks.SignatureHeader), "sha256=")
+		if !webhooks.VerifySignature(testWebhookSecret, timestamp, body, signature) {
+			t.Errorf("invalid signature %q for payload %s", signature, body)
+		}
+		received <- body
+		w.WriteHeader(status)
+	}))
+}
+
+func TestWebhookSender(t *testing.T) {
+	received := make(chan []byte, 1)
+	receiver := newWebhookReceiver(t, http.StatusNoContent, received)
+	defer receiver.Close()
+
+	payload := []byte(`{"event":"ping"}`)
+	status, err := webhooks.NewSender(webhooks.WithHTTPClient(receiver.Client())).Send(webhooks.Request{
+		URL:     receiver.URL,
+		Secret:  testWebhookSecret,
+		Event:   "ping",
+		Payload: payload,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("expected status %d, but got %d", http.StatusNoContent, status)
+	}
+	if got := <-received; string(got) != string(payload) {
+		t.Errorf("expected payload %s, but got %s", payload, got)
+	}
+
+	t.Run("SadPath: error status is an error", func(t *testing.T) {
+		failing := newWebhookReceiver(t, http.StatusInternalServerError, make(chan []byte, 1))
+		defer failing.Close()
+		_, err := webhooks.NewSender(webhooks.WithHTTPClient(failing.Client())).Send(webhooks.Request{
+			URL:     failing.URL,
+			Secret:  testWebhookSecret,
+			Payload: payload,
+		})
+		if err == nil {
+			t.Errorf("expected an error for status %d", http.StatusInternalServerError)
+		}
+	})
+
+	t.Run("SadPath: private addresses are not reached", func(t *testing.T) {
+		local := newWebhookReceiver(t, http.StatusNoContent, make(chan []byte, 1))
+		defer local.Close()
+		_, err := webhooks.NewSender().Send(webhooks.Request{
+			URL:     local.URL,
+			Secret:  testWebhookSecret,
+			Payload: payload,
+		})
+		if err == nil {
+			t.Errorf("expected the loopback receiver not to be reached")
+		}
+	})
+}
+
+func TestWebhookURL(t *testing.T) {
+	ws := models.NewWebhookService(nil)
+	for _, url := range []string{
+		"http://hooks.example.com/jobs",
+		"https://localhost/jobs",
+		"https://127.0.0.1/jobs",
+		"https://[::1]:8443/jobs",
+		"https://169.254.169.254/latest/meta-data",
+		"https://10.0.0.8/jobs",
+		"https://172.20.1.1/jobs",
+		"https://192.168.1.10/jobs",
+		"https://[fd00::1]/jobs",
+		"https://0.0.0.0/jobs",
+	} {
+		webhook := models.Webhook{UserID: 1, URL: url}
+		if err := ws.Create(&webhook); err != models.ErrWebhookURLInvalid {
+			t.Errorf("%s: expected %q error, but got %v", url, models.ErrWebhookURLInvalid, err)
+		}
+	}
+	for _, ip := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		if !models.PublicIP(net.ParseIP(ip)) {
+			t.Errorf("expected %s to be public", ip)
+		}
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+
+	services, err := models.NewServices(
+		models.WithGorm(
+			Dialect(),
+			ConnectionInfo()),
+		models.WithLogMode(false),
+		models.WithWebhook(),
+	)
+	must(err)
+
+	defer services.Close()
+	must(services.DestructiveReset())
+
+	received := make(chan []byte, 1)
+	receiver := newWebhookReceiver(t, http.StatusOK, received)
+	defer receiver.Close()
+
+	webhook := models.Webhook{
+		UserID: 1,
+		URL:    "https://hooks.example.com/jobs",
+		Secret: testWebhookSecret,
+		Events: "job_post.created",
+	}
+	if err := services.Webhook.Create(&webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	// The receiver listens on loopback, which webhooks can not
+	// be registered with
+	webhook.URL = receiver.URL
+	sender := webhooks.NewSender(webhooks.WithHTTPClient(receiver.Client()))
+	delivery, err := webhooks.NewDispatcher(services.Webhook, services.Company, sender).SendTest(webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if delivery.DeliveredAt == nil || delivery.NextAttemptAt != nil || delivery.Attempts != 1 {
+		t.Errorf("expected delivery to succeed on first attempt, but got %+v", delivery)
+	}
+
+	t.Run("SadPath: unknown event is not allowed", func(t *testing.T) {
+		wantError := models.ErrWebhookEventInvalid
+		invalid := models.Webhook{UserID: 1, URL: "https://hooks.example.com/jobs", Events: "job_post.deleted"}
+		if err := services.Webhook.Create(&invalid); err != wantError {
+			t.Errorf("should return %q error got %q error", wantError, err)
+		}
+	})
+}
+
+// subscribedWebhookService records the users whose webhooks are
+// looked up, none of them having any.
+type subscribedWebhookService struct {
+	models.WebhookService
+	userIDs []uint
+}
+
+func (s *subscribedWebhookService) Subscribed(userID uint, event models.WebhookEvent) ([]models.Webhook, error) {
+	s.userIDs = append(s.userIDs, userID)
+	return nil, nil
+}
+
+// teamCompanyService has an owner, a recruiter and a viewer in
+// company 4.
+type teamCompanyService struct {
+	models.CompanyService
+}
+
+func (s teamCompanyService) Members(companyID uint) ([]models.CompanyMember, error) {
+	return []models.CompanyMember{
+		{CompanyID: companyID, UserID: 1, Role: models.MemberOwner},
+		{CompanyID: companyID, UserID: 2, Role: models.MemberRecruiter},
+		{CompanyID: companyID, UserID: 3, Role: models.MemberViewer},
+	}, nil
+}
+
+func TestWebhookRecipients(t *testing.T) {
+	handle := func(eventType models.EventType, payload string) []uint {
+		ws := &subscribedWebhookService{}
+		dispatcher := webhooks.NewDispatcher(ws, teamCompanyService{}, nil)
+		err := dispatcher.HandleEvent(models.DomainEvent{Type: eventType, Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ws.userIDs
+	}
+
+	if userIDs := handle(models.EventJobPostCreated, `{"userId":2,"companyId":4}`); len(userIDs) != 2 || userIDs[0] != 1 || userIDs[1] != 2 {
+		t.Errorf("expected the members managing job posts to be notified, but got %v", userIDs)
+	}
+	if userIDs := handle(models.EventApplicationReceived, `{"jobPost":{"userId":2,"companyId":4}}`); len(userIDs) != 2 {
+		t.Errorf("expected the members managing job posts to be notified of applications, but got %v", userIDs)
+	}
+	if userIDs := handle(models.EventJobPostCreated, `{"userId":5}`); len(userIDs) != 1 || userIDs[0] != 5 {
+		t.Errorf("expected the author of a job post without company to be notified, but got %v", userIDs)
+	}
+}
@@ -0,0 +1,289 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/hash"
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	// TXTPrefix starts the value of the DNS TXT record
+	TXTPrefix = "jobboard-verification="
+	// FilePath is where the verification file is served on the
+	// website of the company.
+	FilePath = "/.well-known/jobboard-verification.txt"
+	// Method names, as stored on the verified profiles
+	MethodDNS   = "dns"
+	MethodFile  = "file"
+	MethodEmail = "email"
+
+	lookupTimeout = 10 * time.Second
+	fetchTimeout  = 10 * time.Second
+	maxFileSize   = 4 << 10
+	// emailCodeTTL is how long an emailed code can be used for
+	emailCodeTTL = 24 * time.Hour
+)
+
+var (
+	ErrWebsiteInvalid = errors.New("verification: website must be the domain name of the company")
+	// ErrChallengeFailed is returned when the DNS record or the
+	// file does not contain the token of the company.
+	ErrChallengeFailed = errors.New("verification: token of the company was not found")
+	// ErrEmailDomain is returned for email addresses outside of
+	// the domain of the company, or hosted by a public provider.
+	ErrEmailDomain      = errors.New("verification: email address must be on the domain of the company")
+	ErrEmailCodeInvalid = errors.New("verification: code is not valid or has expired")
+)
+
+// freeEmailDomains are the email providers anyone can get an
+// address from, they do not prove the ownership of a domain.
+var freeEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"gmx.com":        true,
+	"mail.com":       true,
+	"yandex.com":     true,
+}
+
+// Resolver looks up DNS TXT records, net.DefaultResolver is a
+// Resolver.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Fetcher returns the content of a URL
+type Fetcher interface {
+	Fetch(url string) (io.ReadCloser, error)
+}
+
+// FetcherFunc is a function used as a Fetcher
+type FetcherFunc func(url string) (io.ReadCloser, error)
+
+func (fn FetcherFunc) Fetch(url string) (io.ReadCloser, error) {
+	return fn(url)
+}
+
+// HTTPFetcher fetches verification files over HTTP
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+// NewHTTPFetcher creates an HTTPFetcher with a timeout
+func NewHTTPFetcher() *HTTPFetcher {
+	return &HTTPFetcher{
+		Client: &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+func (hf *HTTPFetcher) Fetch(url string) (io.ReadCloser, error) {
+	res, err := hf.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("verification: file responded with status %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+// Challenge tells a company how to prove it owns its domain
+type Challenge struct {
+	Domain string `json:"domain"`
+	Token  string `json:"token"`
+	// TXTRecord is the value of the TXT record to add to the
+	// domain.
+	TXTRecord string `json:"txtRecord"`
+	// FileURL is where a file containing the token must be
+	// served.
+	FileURL string `json:"fileUrl"`
+}
+
+// NewVerifier creates a Verifier. The tokens are signed with
+// hmacKey, so they never need to be stored.
+func NewVerifier(hmacKey string, resolver Resolver, fetcher Fetcher) *Verifier {
+	return &Verifier{
+		hmac:     hash.NewHMAC(hmacKey),
+		resolver: resolver,
+		fetcher:  fetcher,
+	}
+}
+
+// Verifier checks that a company owns the domain of the website
+// of its profile.
+type Verifier struct {
+	hmac     hash.HMAC
+	resolver Resolver
+	fetcher  Fetcher
+}
+
+// Challenge returns the challenge of the profile, whose token
+// only changes with the domain of the website.
+func (v *Verifier) Challenge(profile models.CompanyProfile) (*Challenge, error) {
+	domain, err := Domain(profile.Website)
+	if err != nil {
+		return nil, err
+	}
+	token := v.token(profile.ID, domain)
+	return &Challenge{
+		Domain:    domain,
+		Token:     token,
+		TXTRecord: TXTPrefix + token,
+		FileURL:   "https://" + domain + FilePath,
+	}, nil
+}
+
+// CheckDNS looks for the TXT record of the challenge on the
+// domain of the profile, and returns the domain verified.
+func (v *Verifier) CheckDNS(profile models.CompanyProfile) (string, error) {
+	challenge, err := v.Challenge(profile)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	records, err := v.resolver.LookupTXT(ctx, challenge.Domain)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", ErrChallengeFailed
+		}
+		return "", err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == challenge.TXTRecord {
+			return challenge.Domain, nil
+		}
+	}
+	return "", ErrChallengeFailed
+}
+
+// CheckFile fetches the file of the challenge from the website
+// of the profile, and returns the domain verified.
+func (v *Verifier) CheckFile(profile models.CompanyProfile) (string, error) {
+	challenge, err := v.Challenge(profile)
+	if err != nil {
+		return "", err
+	}
+	body, err := v.fetcher.Fetch(challenge.FileURL)
+	if err != nil {
+		return "", ErrChallengeFailed
+	}
+	defer body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(body, maxFileSize))
+	if err != nil {
+		return "", ErrChallengeFailed
+	}
+	if strings.TrimSpace(string(content)) != challenge.Token {
+		return "", ErrChallengeFailed
+	}
+	return challenge.Domain, nil
+}
+
+// EmailCode returns the code to email to the work address to
+// verify the profile with. The code expires after a day.
+func (v *Verifier) EmailCode(profile models.CompanyProfile, email string, now time.Time) (string, error) {
+	domain, err := Domain(profile.Website)
+	if err != nil {
+		return "", err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if err := emailOnDomain(email, domain); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(now.Add(emailCodeTTL).Unix(), 10)
+	return expires + "." + v.hmac.Hash(emailMessage(profile.ID, domain, email, expires)), nil
+}
+
+// CheckEmailCode verifies a code returned by EmailCode for the
+// same address, and returns the domain verified.
+func (v *Verifier) CheckEmailCode(profile models.CompanyProfile, email, code string, now time.Time) (string, error) {
+	domain, err := Domain(profile.Website)
+	if err != nil {
+		return "", err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if err := emailOnDomain(email, domain); err != nil {
+		return "", err
+	}
+	i := strings.Index(code, ".")
+	if i <= 0 {
+		return "", ErrEmailCodeInvalid
+	}
+	expires := code[:i]
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.After(time.Unix(unix, 0)) {
+		return "", ErrEmailCodeInvalid
+	}
+	if !v.hmac.Equal(emailMessage(profile.ID, domain, email, expires), code[i+1:]) {
+		return "", ErrEmailCodeInvalid
+	}
+	return domain, nil
+}
+
+func (v *Verifier) token(profileID uint, domain string) string {
+	return v.hmac.Hash(fmt.Sprintf("domain:%d:%s", profileID, domain))
+}
+
+func emailMessage(profileID uint, domain, email, expires string) string {
+	return fmt.Sprintf("email:%d:%s:%s:%s", profileID, domain, email, expires)
+}
+
+// emailOnDomain requires the address to be on the domain or one
+// of its subdomains.
+func emailOnDomain(email, domain string) error {
+	i := strings.LastIndex(email, "@")
+	if i <= 0 || freeEmailDomains[domain] {
+		return ErrEmailDomain
+	}
+	host := email[i+1:]
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return ErrEmailDomain
+	}
+	return nil
+}
+
+// Domain returns the domain name of a website, such as
+// "acme.com" for "https://www.acme.com/careers".
+func Domain(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return "", ErrWebsiteInvalid
+	}
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+	u, err := url.Parse(website)
+	if err != nil {
+		return "", ErrWebsiteInvalid
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return "", ErrWebsiteInvalid
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.Trim(label, "abcdefghijklmnopqrstuvwxyz0123456789-") != "" {
+			return "", ErrWebsiteInvalid
+		}
+	}
+	return host, nil
+}
@@ -0,0 +1,264 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/models"
+)
+
+const (
+	// maxAttempts is the number of times a delivery is tried
+	// before giving up on it.
+	maxAttempts = 8
+	// baseBackoff is the wait before the first retry, which
+	// doubles on every failed attempt.
+	baseBackoff   = 30 * time.Second
+	retryInterval = 30 * time.Second
+	retryBatch    = 100
+)
+
+// Envelope is the JSON body POSTed to webhooks. ID is the ID of
+// the domain event, receivers can use it to drop the duplicates
+// of an event delivered more than once.
+type Envelope struct {
+	ID        uint                `json:"id,omitempty"`
+	Event     models.WebhookEvent `json:"event"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Data      interface{}         `json:"data"`
+}
+
+// NewDispatcher creates a Dispatcher, Run must be called for
+// failed deliveries to be retried.
+func NewDispatcher(ws models.WebhookService, cs models.CompanyService, sender *Sender) *Dispatcher {
+	return &Dispatcher{
+		ws:     ws,
+		cs:     cs,
+		sender: sender,
+	}
+}
+
+// Dispatcher records a delivery for every webhook subscribed to
+// an event and sends them, retrying failed deliveries with an
+// exponential backoff.
+type Dispatcher struct {
+	ws     models.WebhookService
+	cs     models.CompanyService
+	sender *Sender
+}
+
+// webhookEvents are the webhook events sent for each of the
+// domain events the Dispatcher subscribes to.
+var webhookEvents = map[models.EventType]models.WebhookEvent{
+	models.EventJobPostCreated:      models.WebhookJobPostCreated,
+	models.EventJobPostUpdated:      models.WebhookJobPostUpdated,
+	models.EventJobPostPublished:    models.WebhookJobPostPublished,
+	models.EventJobPostClosed:       models.WebhookJobPostClosed,
+	models.EventApplicationReceived: models.WebhookApplicationReceived,
+}
+
+// DomainEvents returns the domain events HandleEvent must be
+// subscribed to.
+func DomainEvents() []models.EventType {
+	var types []models.EventType
+	for t := range webhookEvents {
+		types = append(types, t)
+	}
+	return types
+}
+
+// HandleEvent notifies the webhooks of the company owning the
+// job post the domain event is about, which are the webhooks of
+// the members managing its job posts. Job posts without a
+// company notify the webhooks of their author.
+func (d *Dispatcher) HandleEvent(event models.DomainEvent) error {
+	whEvent, ok := webhookEvents[event.Type]
+	if !ok {
+		return nil
+	}
+	var jobPost models.JobPost
+	if event.Type == models.EventApplicationReceived {
+		var application models.Application
+		if err := event.Decode(&application); err != nil {
+			return err
+		}
+		if application.JobPost == nil {
+			return fmt.Errorf("webhooks: application %d has no job post", application.ID)
+		}
+		jobPost = *application.JobPost
+	} else if err := event.Decode(&jobPost); err != nil {
+		return err
+	}
+	userIDs, err := d.recipients(&jobPost)
+	if err != nil {
+		return err
+	}
+	return d.dispatch(userIDs, Envelope{
+		ID:        event.ID,
+		Event:     whEvent,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      json.RawMessage(event.Payload),
+	})
+}
+
+// Dispatch notifies the webhooks of the user subscribed to the
+// event. Deliveries are sent in the background.
+func (d *Dispatcher) Dispatch(userID uint, event models.WebhookEvent, data interface{}) error {
+	return d.dispatch([]uint{userID}, Envelope{
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+}
+
+// recipients returns the users whose webhooks are notified
+// about the job post.
+func (d *Dispatcher) recipients(jobPost *models.JobPost) ([]uint, error) {
+	if jobPost.CompanyID == 0 {
+		return []uint{jobPost.UserID}, nil
+	}
+	members, err := d.cs.Members(jobPost.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []uint
+	for _, member := range members {
+		if models.MemberRole.CanManageJobPosts(member.Role) {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (d *Dispatcher) dispatch(userIDs []uint, envelope Envelope) error {
+	for _, userID := range userIDs {
+		webhooks, err := d.ws.Subscribed(userID, envelope.Event)
+		if err != nil {
+			return err
+		}
+		for _, wh := range webhooks {
+			delivery, err := d.newDelivery(wh, envelope)
+			if err != nil {
+				return err
+			}
+			go d.attempt(wh, delivery, time.Now())
+		}
+	}
+	return nil
+}
+
+// SendTest sends a ping event to the webhook and waits for the
+// result of the delivery.
+func (d *Dispatcher) SendTest(webhook models.Webhook) (*models.WebhookDelivery, error) {
+	data := map[string]interface{}{
+		"webhookId": webhook.ID,
+		"message":   "This is a test event",
+	}
+	delivery, err := d.newDelivery(webhook, Envelope{
+		Event:     models.WebhookPing,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.attempt(webhook, delivery, time.Now())
+	return delivery, nil
+}
+
+// RetryPending attempts again every delivery that is due
+func (d *Dispatcher) RetryPending(now time.Time) error {
+	deliveries, err := d.ws.PendingDeliveries(now, retryBatch)
+	if err != nil {
+		return err
+	}
+	for i := range deliveries {
+		webhook, err := d.ws.ByID(deliveries[i].WebhookID)
+		if err == models.ErrNotFound {
+			// The webhook was removed, stop retrying
+			deliveries[i].NextAttemptAt = nil
+			if err := d.ws.UpdateDelivery(&deliveries[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		d.attempt(*webhook, &deliveries[i], now)
+	}
+	return nil
+}
+
+// Run retries the pending deliveries until stop is closed
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := d.RetryPending(now); err != nil {
+				log.Printf("webhooks: could not retry deliveries: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) newDelivery(webhook models.Webhook, envelope Envelope) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	// The delivery is attempted right away, it is only picked
+	// up by RetryPending if that first attempt never completes.
+	next := time.Now().Add(baseBackoff)
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         envelope.Event,
+		Payload:       string(payload),
+		NextAttemptAt: &next,
+	}
+	if err := d.ws.CreateDelivery(&delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// attempt sends the delivery and records the outcome, the
+// next attempt is scheduled on failure.
+func (d *Dispatcher) attempt(webhook models.Webhook, delivery *models.WebhookDelivery, now time.Time) {
+	status, err := d.sender.Send(Request{
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		Event:      string(delivery.Event),
+		DeliveryID: delivery.ID,
+		Payload:    []byte(delivery.Payload),
+	})
+	delivery.Attempts++
+	delivery.StatusCode = status
+	if err == nil {
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	} else {
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nextAttempt(delivery.Attempts, now)
+	}
+	if err := d.ws.UpdateDelivery(delivery); err != nil {
+		log.Printf("webhooks: could not record delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// nextAttempt returns when a delivery that failed attempts
+// times should be tried again, or nil to give up on it.
+func nextAttempt(attempts uint, now time.Time) *time.Time {
+	if attempts >= maxAttempts {
+		return nil
+	}
+	next := now.Add(baseBackoff << (attempts - 1))
+	return &next
+}
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/samueldaviddelacruz/go-job-board/API/hash"
+	"github.com/samueldaviddelacruz/go-job-board/API/publicnet"
+)
+
+const (
+	// SignatureHeader holds the HMAC-SHA256 signature of the
+	// timestamp and the body, see Sign.
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Sign returns the signature of a payload sent at timestamp,
+// which is the base64 URL encoded HMAC-SHA256 of
+// "<timestamp>.<payload>" keyed with the webhook secret.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	return hash.NewHMAC(secret).Hash(signedContent(timestamp, payload))
+}
+
+// VerifySignature reports whether signature was produced by
+// Sign with the same secret, timestamp and payload. Receivers
+// should also reject timestamps that are too old.
+func VerifySignature(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hash.NewHMAC(secret).Equal(signedContent(timestamp, payload), signature)
+}
+
+func signedContent(timestamp int64, payload []byte) string {
+	return strconv.FormatInt(timestamp, 10) + "." + string(payload)
+}
+
+// SenderConfig configures the Sender created by NewSender
+type SenderConfig func(*Sender)
+
+// WithHTTPClient makes the Sender use client, which is not
+// restricted to public addresses. It is meant for tests.
+func WithHTTPClient(client *http.Client) SenderConfig {
+	return func(s *Sender) {
+		s.client = client
+	}
+}
+
+// NewSender creates a Sender using an http.Client with a short
+// timeout, so slow receivers do not hold deliveries. The client
+// only connects to public addresses, whatever the names of the
+// webhook URLs resolve to.
+func NewSender(cfgs ...SenderConfig) *Sender {
+	s := &Sender{
+		client: publicnet.NewClient(defaultTimeout),
+		now:    time.Now,
+	}
+	for _, cfg := range cfgs {
+		cfg(s)
+	}
+	return s
+}
+
+// Sender signs payloads and POSTs them to webhook URLs
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// Request describes a payload to send to a webhook URL
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID uint
+	Payload    []byte
+}
+
+// Send POSTs the signed payload and returns the status code of
+// the response. Any status outside of 2xx is an error.
+func (s *Sender) Send(req Request) (int, error) {
+	httpReq, err := http.NewRequest(http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := s.now().Unix()
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Go-JobBoard-Webhooks/1.0")
+	httpReq.Header.Set(EventHeader, req.Event)
+	httpReq.Header.Set(DeliveryHeader, strconv.Itoa(int(req.DeliveryID)))
+	httpReq.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(SignatureHeader, "sha256="+Sign(req.Secret, timestamp, req.Payload))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhooks: receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}